@@ -0,0 +1,98 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/IBAX-io/go-ibax/packages/archive"
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFrom  int64
+	exportTo    int64
+	archivePath string
+)
+
+// exportBlocksCmd represents the exportBlocks command
+var exportBlocksCmd = &cobra.Command{
+	Use:    "exportBlocks",
+	Short:  "Export the blocks to the archive file",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		initSnapshotDB()
+		file, err := os.Create(archivePath)
+		if err != nil {
+			log.WithError(err).Fatal("creating archive file")
+		}
+		defer file.Close()
+
+		count, err := archive.Export(file, exportFrom, exportTo)
+		if err != nil {
+			log.WithError(err).Fatal("exporting blocks")
+		}
+		if err = file.Sync(); err != nil {
+			log.WithError(err).Fatal("writing archive file")
+		}
+		log.WithFields(log.Fields{"count": count, "path": archivePath}).Info("blocks are exported")
+	},
+}
+
+// importBlocksCmd represents the importBlocks command
+var importBlocksCmd = &cobra.Command{
+	Use:    "importBlocks",
+	Short:  "Check and play the blocks from the archive file",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := utils.LockOrDie(conf.Config.LockFilePath)
+		defer f.Unlock()
+
+		file, err := os.Open(archivePath)
+		if err != nil {
+			log.WithError(err).Fatal("opening archive file")
+		}
+		defer file.Close()
+
+		initSnapshotDB()
+		smart.InitVM()
+		infoBlock := &model.InfoBlock{}
+		if _, err = infoBlock.Get(); err != nil {
+			log.WithError(err).Fatal("getting info block")
+		}
+		if infoBlock.BlockID > 0 {
+			if err = syspar.SysUpdate(nil); err != nil {
+				log.WithError(err).Fatal("updating system parameters")
+			}
+			if data, ok := block.GetDataFromFirstBlock(); ok {
+				syspar.SetFirstBlockData(data)
+			}
+			if err = smart.LoadContracts(); err != nil {
+				log.WithError(err).Fatal("loading contracts")
+			}
+		}
+		count, err := archive.Import(file, log.WithFields(log.Fields{}))
+		if err != nil {
+			log.WithFields(log.Fields{"count": count}).WithError(err).Fatal("importing blocks")
+		}
+		log.WithFields(log.Fields{"count": count}).Info("blocks are imported")
+	},
+}
+
+func init() {
+	exportBlocksCmd.Flags().Int64Var(&exportFrom, "from", 1, "first block to export")
+	exportBlocksCmd.Flags().Int64Var(&exportTo, "to", 0, "last block to export, 0 is the last block of blockchain")
+	exportBlocksCmd.Flags().StringVar(&archivePath, "out", "blocks.arc", "path to the archive file")
+	importBlocksCmd.Flags().StringVar(&archivePath, "in", "blocks.arc", "path to the archive file")
+}
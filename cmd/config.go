@@ -68,6 +68,12 @@ func init() {
 	viper.BindPFlag("HTTP.Host", configCmd.Flags().Lookup("httpHost"))
 	viper.BindPFlag("HTTP.Port", configCmd.Flags().Lookup("httpPort"))
 
+	// Metrics
+	configCmd.Flags().StringVar(&conf.Config.Metrics.Host, "metricsHost", "127.0.0.1", "Prometheus metrics host")
+	configCmd.Flags().IntVar(&conf.Config.Metrics.Port, "metricsPort", 0, "Prometheus metrics port, 0 is off")
+	viper.BindPFlag("Metrics.Host", configCmd.Flags().Lookup("metricsHost"))
+	viper.BindPFlag("Metrics.Port", configCmd.Flags().Lookup("metricsPort"))
+
 	// DB
 	configCmd.Flags().StringVar(&conf.Config.DB.Host, "dbHost", "127.0.0.1", "DB host")
 	configCmd.Flags().IntVar(&conf.Config.DB.Port, "dbPort", 5432, "DB port")
@@ -159,8 +165,14 @@ func init() {
 	configCmd.Flags().BoolVar(&conf.Config.TLS, "tls", false, "Enable https")
 	configCmd.Flags().StringVar(&conf.Config.TLSCert, "tls-cert", "", "Filepath to the fullchain of certificates")
 	configCmd.Flags().StringVar(&conf.Config.TLSKey, "tls-key", "", "Filepath to the private key")
+	configCmd.Flags().BoolVar(&conf.Config.NodeTLS, "nodeTls", false, "Enable encrypted tcp transport between honor nodes")
 	configCmd.Flags().Int64Var(&conf.Config.MaxPageGenerationTime, "mpgt", 3000, "Max page generation time in ms")
 	configCmd.Flags().Int64Var(&conf.Config.HTTPServerMaxBodySize, "mbs", 1<<20, "Max server body size in byte")
+	configCmd.Flags().IntVar(&conf.Config.MaxTxPoolSize, "txPoolSize", 100000, "Max count of unused transactions, 0 is unlimited")
+	configCmd.Flags().Int64Var(&conf.Config.SnapshotInterval, "snapshotInterval", 0, "Count of blocks between state snapshots, 0 is off, it must be the same on all honor nodes")
+	configCmd.Flags().BoolVar(&conf.Config.FastSync, "fastSync", false, "Sync new node from the state snapshot of honor nodes")
+	configCmd.Flags().Int64Var(&conf.Config.PruneBlocks, "pruneBlocks", 0, "Count of the last blocks whose bodies are kept, 0 is off")
+	configCmd.Flags().BoolVar(&conf.Config.PruneToSnapshot, "pruneToSnapshot", false, "Keep the blocks after the last finalized snapshot")
 	configCmd.Flags().StringSliceVar(&conf.Config.NodesAddr, "nodesAddr", []string{}, "List of addresses for downloading blockchain")
 	configCmd.Flags().Int64Var(&conf.Config.NetworkID, "networkID", 1, "Network ID")
 	configCmd.Flags().StringVar(&conf.Config.OBSMode, "obsMode", consts.NoneOBS, "OBS running mode")
@@ -173,13 +185,33 @@ func init() {
 	viper.BindPFlag("TLS", configCmd.Flags().Lookup("tls"))
 	viper.BindPFlag("TLSCert", configCmd.Flags().Lookup("tls-cert"))
 	viper.BindPFlag("TLSKey", configCmd.Flags().Lookup("tls-key"))
+	viper.BindPFlag("NodeTLS", configCmd.Flags().Lookup("nodeTls"))
 	viper.BindPFlag("MaxPageGenerationTime", configCmd.Flags().Lookup("mpgt"))
 	viper.BindPFlag("HTTPServerMaxBodySize", configCmd.Flags().Lookup("mbs"))
+	viper.BindPFlag("MaxTxPoolSize", configCmd.Flags().Lookup("txPoolSize"))
+	viper.BindPFlag("SnapshotInterval", configCmd.Flags().Lookup("snapshotInterval"))
+	viper.BindPFlag("FastSync", configCmd.Flags().Lookup("fastSync"))
+	viper.BindPFlag("PruneBlocks", configCmd.Flags().Lookup("pruneBlocks"))
+	viper.BindPFlag("PruneToSnapshot", configCmd.Flags().Lookup("pruneToSnapshot"))
 	viper.BindPFlag("TempDir", configCmd.Flags().Lookup("tempDir"))
 	viper.BindPFlag("NodesAddr", configCmd.Flags().Lookup("nodesAddr"))
 	viper.BindPFlag("NetworkID", configCmd.Flags().Lookup("networkID"))
 	viper.BindPFlag("OBSMode", configCmd.Flags().Lookup("obsMode"))
 
+	// RateLimit
+	configCmd.Flags().Float64Var(&conf.Config.RateLimit.IP.Rate, "ipRateLimit", 0, "Requests per second from one address, 0 is unlimited")
+	configCmd.Flags().IntVar(&conf.Config.RateLimit.IP.Burst, "ipRateBurst", 0, "Max burst of requests from one address")
+	configCmd.Flags().Float64Var(&conf.Config.RateLimit.Key.Rate, "keyRateLimit", 0, "Requests per second of one key, 0 is unlimited")
+	configCmd.Flags().IntVar(&conf.Config.RateLimit.Key.Burst, "keyRateBurst", 0, "Max burst of requests of one key")
+	viper.BindPFlag("RateLimit.IP.Rate", configCmd.Flags().Lookup("ipRateLimit"))
+	viper.BindPFlag("RateLimit.IP.Burst", configCmd.Flags().Lookup("ipRateBurst"))
+	viper.BindPFlag("RateLimit.Key.Rate", configCmd.Flags().Lookup("keyRateLimit"))
+	viper.BindPFlag("RateLimit.Key.Burst", configCmd.Flags().Lookup("keyRateBurst"))
+	configCmd.Flags().Int64Var(&conf.Config.RateLimit.KeyQuota, "keyQuota", 0, "Max count of requests of one key per quota period, 0 is unlimited")
+	configCmd.Flags().IntVar(&conf.Config.RateLimit.QuotaPeriod, "quotaPeriod", 1440, "Quota period in minutes")
+	viper.BindPFlag("RateLimit.KeyQuota", configCmd.Flags().Lookup("keyQuota"))
+	viper.BindPFlag("RateLimit.QuotaPeriod", configCmd.Flags().Lookup("quotaPeriod"))
+
 	// GFiles
 	configCmd.Flags().BoolVar(&conf.Config.GFiles.GFiles, "gfs", false, "Enable GFiles")
 	configCmd.Flags().StringVar(&conf.Config.GFiles.Host, "gFilesHost", "127.0.0.1:5001", "GFiles host")
@@ -199,6 +231,20 @@ func init() {
 	viper.BindPFlag("PoolPub.TotalCount", configCmd.Flags().Lookup("totalcount"))
 	viper.BindPFlag("PoolPub.RollBack", configCmd.Flags().Lookup("rollback"))
 	viper.BindPFlag("PoolPub.Path", configCmd.Flags().Lookup("poolpath"))
+
+	// Light mode
+	configCmd.Flags().StringVar(&conf.Config.Light.Path, "lightPath", "light", "leveldb path of the light mode")
+	configCmd.Flags().StringVar(&conf.Config.Light.HonorNodes, "lightHonorNodes", "", "Trusted json list of honor nodes for the light mode")
+	viper.BindPFlag("Light.Path", configCmd.Flags().Lookup("lightPath"))
+	viper.BindPFlag("Light.HonorNodes", configCmd.Flags().Lookup("lightHonorNodes"))
+
+	// Peers
+	configCmd.Flags().StringVar(&conf.Config.Peers.Path, "peersPath", "peers", "leveldb path of the table of peers")
+	configCmd.Flags().StringVar(&conf.Config.Peers.Address, "peersAddress", "", "Public tcp address of the node which is announced to peers")
+	configCmd.Flags().IntVar(&conf.Config.Peers.Max, "maxPeers", 200, "Max count of peers in the table")
+	viper.BindPFlag("Peers.Path", configCmd.Flags().Lookup("peersPath"))
+	viper.BindPFlag("Peers.Address", configCmd.Flags().Lookup("peersAddress"))
+	viper.BindPFlag("Peers.Max", configCmd.Flags().Lookup("maxPeers"))
 	// CryptoSettings
 	configCmd.Flags().StringVar(&conf.Config.CryptoSettings.Hasher, "hasher", "SHA256", "Hash Algorithm")
 	configCmd.Flags().StringVar(&conf.Config.CryptoSettings.Cryptoer, "cryptoer", "ECDSA", "Key and Sign Algorithm")
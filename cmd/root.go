@@ -22,14 +22,18 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(
+		exportBlocksCmd,
 		generateFirstBlockCmd,
 		generateKeysCmd,
+		importBlocksCmd,
 		initDatabaseCmd,
 		rollbackCmd,
+		snapshotCmd,
 		startCmd,
 		configCmd,
 		stopNetworkCmd,
 		versionCmd,
+		verifyProofCmd,
 	)
 
 	// This flags are visible for all child commands
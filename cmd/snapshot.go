@@ -0,0 +1,123 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/snapshot"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotPath string
+	snapshotHash string
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Export or import the state of blockchain",
+}
+
+var snapshotExportCmd = &cobra.Command{
+	Use:    "export",
+	Short:  "Export the state at the last block to the file",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := utils.LockOrDie(conf.Config.LockFilePath)
+		defer f.Unlock()
+
+		initSnapshotDB()
+		dbTransaction, err := model.StartSnapshotTransaction()
+		if err != nil {
+			log.WithError(err).Fatal("starting transaction")
+		}
+		defer dbTransaction.Rollback()
+
+		file, err := os.Create(snapshotPath)
+		if err != nil {
+			log.WithError(err).Fatal("creating snapshot file")
+		}
+		defer file.Close()
+
+		h := sha256.New()
+		header, err := snapshot.Export(dbTransaction, io.MultiWriter(file, h))
+		if err != nil {
+			log.WithError(err).Fatal("exporting snapshot")
+		}
+		log.WithFields(log.Fields{"block_id": header.BlockID, "hash": hex.EncodeToString(h.Sum(nil)),
+			"path": snapshotPath}).Info("snapshot is exported")
+	},
+}
+
+var snapshotImportCmd = &cobra.Command{
+	Use:    "import",
+	Short:  "Replace the state with the snapshot from the file, the snapshot must be trusted",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := utils.LockOrDie(conf.Config.LockFilePath)
+		defer f.Unlock()
+
+		if len(snapshotHash) > 0 {
+			hash, err := snapshot.FileHash(snapshotPath)
+			if err != nil {
+				log.WithError(err).Fatal("getting snapshot hash")
+			}
+			if !bytes.Equal(hash, converter.HexToBin(snapshotHash)) {
+				log.WithFields(log.Fields{"hash": hex.EncodeToString(hash)}).Fatal("snapshot hash doesn't match")
+			}
+		}
+		file, err := os.Open(snapshotPath)
+		if err != nil {
+			log.WithError(err).Fatal("opening snapshot file")
+		}
+		defer file.Close()
+
+		initSnapshotDB()
+		dbTransaction, err := model.StartTransaction()
+		if err != nil {
+			log.WithError(err).Fatal("starting transaction")
+		}
+		header, err := snapshot.Import(dbTransaction, file, nil)
+		if err != nil {
+			dbTransaction.Rollback()
+			log.WithError(err).Fatal("importing snapshot")
+		}
+		if err = dbTransaction.Commit(); err != nil {
+			log.WithError(err).Fatal("committing snapshot")
+		}
+		log.WithFields(log.Fields{"block_id": header.BlockID}).Info("snapshot is imported")
+	},
+}
+
+func initSnapshotDB() {
+	if err := model.GormInit(
+		conf.Config.DB.Host,
+		conf.Config.DB.Port,
+		conf.Config.DB.User,
+		conf.Config.DB.Password,
+		conf.Config.DB.Name,
+	); err != nil {
+		log.WithError(err).Fatal("init db")
+	}
+}
+
+func init() {
+	snapshotCmd.PersistentFlags().StringVar(&snapshotPath, "path", "snapshot.snap", "path to the snapshot file")
+	snapshotImportCmd.Flags().StringVar(&snapshotHash, "hash", "", "expected hex hash of the snapshot file")
+	snapshotCmd.AddCommand(snapshotExportCmd, snapshotImportCmd)
+}
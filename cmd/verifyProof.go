@@ -0,0 +1,74 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+
+	"github.com/IBAX-io/go-ibax/packages/api"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proofPath     string
+	proofNodeKey  string
+	proofTxData   string
+	proofCryptoer string
+	proofHasher   string
+)
+
+// verifyProofCmd represents the verifyProof command. It doesn't need the config of the node,
+// the algorithms of the network are specified by flags
+var verifyProofCmd = &cobra.Command{
+	Use:   "verifyProof",
+	Short: "Verify the transaction inclusion proof offline",
+	Run: func(cmd *cobra.Command, args []string) {
+		crypto.InitCurve(proofCryptoer)
+		crypto.InitHash(proofHasher)
+		data, err := os.ReadFile(proofPath)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "path": proofPath}).Fatal("reading proof file")
+		}
+		var proof api.TxProofResult
+		if err = json.Unmarshal(data, &proof); err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("unmarshalling proof")
+		}
+		if proof.Proof == nil {
+			log.Fatal("proof is empty")
+		}
+		pubKey, err := crypto.HexToPub(proofNodeKey)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("decoding node public key")
+		}
+		var txData []byte
+		if len(proofTxData) > 0 {
+			if txData, err = hex.DecodeString(proofTxData); err != nil {
+				log.WithFields(log.Fields{"error": err}).Fatal("decoding transaction data")
+			}
+		}
+		header, prev := proof.Header.BlockData()
+		if err = utils.VerifyTxProof(txData, proof.Proof, header, prev, pubKey); err != nil {
+			log.WithFields(log.Fields{"error": err, "tx_hash": proof.TxHash, "block_id": header.BlockID}).Fatal("proof is not valid")
+		}
+		log.WithFields(log.Fields{"tx_hash": proof.TxHash, "block_id": header.BlockID}).Info("proof is valid")
+	},
+}
+
+func init() {
+	verifyProofCmd.Flags().StringVar(&proofPath, "proof", "", "filepath to the result of /api/v2/txproof")
+	verifyProofCmd.Flags().StringVar(&proofNodeKey, "nodeKey", "", "public key of the honor node in hex")
+	verifyProofCmd.Flags().StringVar(&proofTxData, "txData", "", "raw transaction data in hex (optional)")
+	verifyProofCmd.Flags().StringVar(&proofCryptoer, "cryptoer", "ECDSA", "Key and Sign Algorithm of the network")
+	verifyProofCmd.Flags().StringVar(&proofHasher, "hasher", "SHA256", "Hash Algorithm of the network")
+	verifyProofCmd.MarkFlagRequired("proof")
+	verifyProofCmd.MarkFlagRequired("nodeKey")
+}
@@ -12,6 +12,7 @@ require (
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/fsnotify/fsnotify v1.4.9 // indirect
 	github.com/go-redis/redis v6.15.9+incompatible
+	github.com/gorilla/websocket v1.4.2
 	github.com/ochinchina/supervisord/config v0.0.0-20210709021912-96855de42ff6
 	github.com/ochinchina/supervisord/events v0.0.0-20210709021912-96855de42ff6 // indirect
 	github.com/ochinchina/supervisord/faults v0.0.0-20210709021912-96855de42ff6 // indirect
@@ -21,6 +22,7 @@ require (
 	github.com/ochinchina/supervisord/util v0.0.0-20210709021912-96855de42ff6 // indirect
 	github.com/pelletier/go-toml v1.8.1 // indirect
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.14.0
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/satori/go.uuid v1.2.0
 	github.com/shopspring/decimal v1.2.0
@@ -39,6 +39,7 @@ type Client struct {
 	EcosystemName string
 	RoleID        int64
 	IsMobile      bool
+	Scope         *keyScope // restrictions of the api key, nil for jwt
 }
 
 func (c *Client) Prefix() string {
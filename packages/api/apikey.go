@@ -0,0 +1,389 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/types"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// apiKeyPrefix is the prefix of the Authorization header with the api key instead of jwt
+	apiKeyPrefix = "ApiKey "
+	// defaultAPIKeyExpire is the lifetime of the api key in seconds by default
+	defaultAPIKeyExpire = 90 * 24 * 3600
+	// maxAPIKeyExpire is the maximum lifetime of the api key in seconds
+	maxAPIKeyExpire = 365 * 24 * 3600
+	apiKeySecretSize    = 32
+	// anyScope allows all routes or contracts
+	anyScope = "*"
+)
+
+var errAPIKeyValue = errors.New("api key must be in the format id.secret")
+
+// keyScope restricts the client which is authorized by the api key. Routes are the groups
+// of routes like listWhere, Contracts are the contracts which the node runs for the key
+// by estimate and call, the transactions are signed by the account itself
+type keyScope struct {
+	Routes     []string
+	Contracts  []string
+	Ecosystems []int64
+}
+
+func (s *keyScope) allowRoute(group string) bool {
+	return converter.InSliceString(anyScope, s.Routes) || converter.InSliceString(group, s.Routes)
+}
+
+// allowContract checks the full name of the contract like @1Name, the name without
+// the ecosystem is allowed in all ecosystems of the key
+func (s *keyScope) allowContract(fullName string) bool {
+	if converter.InSliceString(anyScope, s.Contracts) || converter.InSliceString(fullName, s.Contracts) {
+		return true
+	}
+	_, name := converter.ParseName(fullName)
+	return len(name) > 0 && converter.InSliceString(name, s.Contracts)
+}
+
+// ecosystemContracts returns the full names of the allowed contracts of the ecosystem,
+// it returns nil if all contracts are allowed
+func (s *keyScope) ecosystemContracts(ecosystemID int64) []string {
+	if converter.InSliceString(anyScope, s.Contracts) {
+		return nil
+	}
+	prefix := "@" + converter.Int64ToStr(ecosystemID)
+	list := make([]string, 0, len(s.Contracts))
+	for _, contract := range s.Contracts {
+		if !strings.HasPrefix(contract, "@") {
+			list = append(list, prefix+contract)
+		} else if id, name := converter.ParseName(contract); id == ecosystemID && len(name) > 0 {
+			list = append(list, contract)
+		}
+	}
+	return list
+}
+
+func (s *keyScope) allowEcosystem(ecosystemID int64) bool {
+	for _, id := range s.Ecosystems {
+		if id == ecosystemID {
+			return true
+		}
+	}
+	return false
+}
+
+// checkScope checks the route and the ecosystem of the request of the api key
+func checkScope(r *http.Request, client *Client) error {
+	if client.Scope == nil {
+		return nil
+	}
+	group := requestGroup(r)
+	if !client.Scope.allowRoute(group) {
+		return errScope.Errorf("route " + group)
+	}
+	if !client.Scope.allowEcosystem(client.EcosystemID) {
+		return errScope.Errorf("ecosystem " + converter.Int64ToStr(client.EcosystemID))
+	}
+	return nil
+}
+
+// checkKeyRole checks that the account of the api key is still the member of the role of the key
+func checkKeyRole(client *Client) error {
+	if client.RoleID == 0 {
+		return nil
+	}
+	role, err := checkRoleFromParam(client.RoleID, client.EcosystemID, client.AccountID)
+	if err != nil {
+		return err
+	}
+	if role == 0 {
+		return errScope.Errorf("role " + converter.Int64ToStr(client.RoleID))
+	}
+	return nil
+}
+
+// checkContractScope checks that the api key is allowed to run the contract
+func checkContractScope(client *Client, fullName string) error {
+	if client.Scope == nil || client.Scope.allowContract(fullName) {
+		return nil
+	}
+	return errScope.Errorf("contract " + fullName)
+}
+
+func hashAPIKeySecret(secret []byte) []byte {
+	h := sha256.Sum256(secret)
+	return h[:]
+}
+
+// parseAPIKey splits the key into the id and the secret
+func parseAPIKey(value string) (int64, []byte, error) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return 0, nil, errAPIKeyValue
+	}
+	id := converter.StrToInt64(parts[0])
+	secret, err := hex.DecodeString(parts[1])
+	if id <= 0 || err != nil || len(secret) != apiKeySecretSize {
+		return 0, nil, errAPIKeyValue
+	}
+	return id, secret, nil
+}
+
+// findAPIKey returns the valid key from the Authorization header. It returns errAPIKey
+// if the key is malformed, unknown, revoked or expired. Every key has the expiration time
+func findAPIKey(header string, now time.Time) (*model.APIKey, error) {
+	id, secret, err := parseAPIKey(strings.TrimPrefix(header, apiKeyPrefix))
+	if err != nil {
+		return nil, errAPIKey
+	}
+	key := &model.APIKey{}
+	found, err := key.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !found || subtle.ConstantTimeCompare(key.Hash, hashAPIKeySecret(secret)) != 1 {
+		return nil, errAPIKey
+	}
+	if now.Unix() >= key.Expire {
+		return nil, errAPIKey
+	}
+	return key, nil
+}
+
+func getClientFromAPIKey(key *model.APIKey, ecosysNameService types.EcosystemNameGetter) (*Client, error) {
+	scope := &keyScope{}
+	err := json.Unmarshal([]byte(key.Routes), &scope.Routes)
+	if err == nil {
+		err = json.Unmarshal([]byte(key.Contracts), &scope.Contracts)
+	}
+	if err == nil {
+		err = json.Unmarshal([]byte(key.Ecosystems), &scope.Ecosystems)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err, "api_key": key.ID}).Error("unmarshalling api key scope")
+		return nil, err
+	}
+
+	name, err := ecosysNameService.GetEcosystemName(key.EcosystemID)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		KeyID:         key.KeyID,
+		AccountID:     key.AccountID,
+		EcosystemID:   key.EcosystemID,
+		EcosystemName: name,
+		RoleID:        key.RoleID,
+		Scope:         scope,
+	}, nil
+}
+
+type apiKeyForm struct {
+	Name       string `schema:"name"`
+	Routes     string `schema:"routes"`
+	Contracts  string `schema:"contracts"`
+	Ecosystems string `schema:"ecosystems"`
+	Expire     int64  `schema:"expire"`
+	Validator  types.EcosystemIDValidator
+
+	scope keyScope
+}
+
+func splitList(value string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (f *apiKeyForm) Validate(r *http.Request) error {
+	if f.Expire <= 0 {
+		f.Expire = defaultAPIKeyExpire
+	}
+	if f.Expire > maxAPIKeyExpire {
+		return errAPIKeyExpire.Errorf(maxAPIKeyExpire)
+	}
+	f.scope.Routes = splitList(f.Routes)
+	if len(f.scope.Routes) == 0 {
+		return errEmptyRoutes
+	}
+	f.scope.Contracts = splitList(f.Contracts)
+	client := getClient(r)
+	f.scope.Ecosystems = make([]int64, 0)
+	for _, item := range splitList(f.Ecosystems) {
+		id := converter.StrToInt64(item)
+		if id <= 0 {
+			return errEcosystem.Errorf(id)
+		}
+		if _, err := f.Validator.Validate(id, client.EcosystemID, getLogger(r)); err != nil {
+			if err == ErrEcosystemNotFound {
+				err = errEcosystem.Errorf(id)
+			}
+			return err
+		}
+		f.scope.Ecosystems = append(f.scope.Ecosystems, id)
+	}
+	if len(f.scope.Ecosystems) == 0 {
+		f.scope.Ecosystems = append(f.scope.Ecosystems, client.EcosystemID)
+	}
+	return nil
+}
+
+// APIKeyResult is the api key without the secret
+type APIKeyResult struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	EcosystemID int64           `json:"ecosystem_id"`
+	RoleID      int64           `json:"role_id"`
+	Routes      json.RawMessage `json:"routes"`
+	Contracts   json.RawMessage `json:"contracts"`
+	Ecosystems  json.RawMessage `json:"ecosystems"`
+	Expire      int64           `json:"expire"`
+	Time        int64           `json:"time"`
+}
+
+type createAPIKeyResult struct {
+	APIKeyResult
+	Key string `json:"key"`
+}
+
+func newAPIKeyResult(key *model.APIKey) APIKeyResult {
+	return APIKeyResult{
+		ID:          key.ID,
+		Name:        key.Name,
+		EcosystemID: key.EcosystemID,
+		RoleID:      key.RoleID,
+		Routes:      json.RawMessage(key.Routes),
+		Contracts:   json.RawMessage(key.Contracts),
+		Ecosystems:  json.RawMessage(key.Ecosystems),
+		Expire:      key.Expire,
+		Time:        key.Time,
+	}
+}
+
+// ownerRequire allows the request only to the owner of the account who has logged in,
+// the api keys cannot mint or revoke keys
+func ownerRequire(w http.ResponseWriter, r *http.Request) *Client {
+	client := getClient(r)
+	if client.Scope != nil {
+		getLogger(r).WithFields(log.Fields{"type": consts.AccessDenied, "key_id": client.KeyID}).Warning("managing api keys by api key")
+		errorResponse(w, errScope.Errorf("api keys"))
+		return nil
+	}
+	return client
+}
+
+// createAPIKeyHandler mints the api key of the account. The secret is returned only once.
+// The key works in the current ecosystem if it is allowed, otherwise in the first allowed one
+func (m Mode) createAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	client := ownerRequire(w, r)
+	if client == nil {
+		return
+	}
+	form := &apiKeyForm{Validator: m.EcosysIDValidator}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	logger := getLogger(r)
+
+	secret := make([]byte, apiKeySecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("generating api key")
+		errorResponse(w, err)
+		return
+	}
+	routes, _ := json.Marshal(form.scope.Routes)
+	contracts, _ := json.Marshal(form.scope.Contracts)
+	ecosystems, _ := json.Marshal(form.scope.Ecosystems)
+	ecosystemID, roleID := client.EcosystemID, client.RoleID
+	if !form.scope.allowEcosystem(ecosystemID) {
+		// the role belongs to the ecosystem of the session
+		ecosystemID, roleID = form.scope.Ecosystems[0], 0
+	}
+	now := time.Now()
+	key := &model.APIKey{
+		Name:        form.Name,
+		Hash:        hashAPIKeySecret(secret),
+		KeyID:       client.KeyID,
+		AccountID:   client.AccountID,
+		EcosystemID: ecosystemID,
+		RoleID:      roleID,
+		Routes:      string(routes),
+		Contracts:   string(contracts),
+		Ecosystems:  string(ecosystems),
+		Expire:      now.Unix() + form.Expire,
+		Time:        now.Unix(),
+	}
+	if err := key.Create(nil); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating api key")
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, &createAPIKeyResult{
+		APIKeyResult: newAPIKeyResult(key),
+		Key:          converter.Int64ToStr(key.ID) + "." + hex.EncodeToString(secret),
+	})
+}
+
+// getAPIKeysHandler returns the api keys of the account
+func getAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	client := ownerRequire(w, r)
+	if client == nil {
+		return
+	}
+	keys, err := model.GetAPIKeys(client.KeyID)
+	if err != nil {
+		getLogger(r).WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting api keys")
+		errorResponse(w, err)
+		return
+	}
+	result := make([]APIKeyResult, len(keys))
+	for i := range keys {
+		result[i] = newAPIKeyResult(&keys[i])
+	}
+	jsonResponse(w, result)
+}
+
+// revokeAPIKeyHandler deletes the api key of the account
+func revokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	client := ownerRequire(w, r)
+	if client == nil {
+		return
+	}
+	id := converter.StrToInt64(mux.Vars(r)["id"])
+	found, err := model.DeleteAPIKey(id, client.KeyID)
+	if err != nil {
+		getLogger(r).WithFields(log.Fields{"type": consts.DBError, "error": err, "api_key": id}).Error("deleting api key")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errNotFoundRecord)
+		return
+	}
+	jsonResponse(w, &struct {
+		Result bool `json:"result"`
+	}{true})
+}
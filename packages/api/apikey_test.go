@@ -0,0 +1,114 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyScope(t *testing.T) {
+	scope := &keyScope{
+		Routes:     []string{"listWhere", "row"},
+		Contracts:  []string{"@1TokenTransfer", "MainCondition"},
+		Ecosystems: []int64{1, 5},
+	}
+	assert.True(t, scope.allowRoute("listWhere"))
+	assert.False(t, scope.allowRoute("sendTx"))
+	assert.True(t, scope.allowContract("@1TokenTransfer"))
+	assert.False(t, scope.allowContract("@5TokenTransfer"))
+	assert.True(t, scope.allowContract("@5MainCondition"))
+	assert.False(t, scope.allowContract("@1NewUser"))
+	assert.True(t, scope.allowEcosystem(5))
+	assert.False(t, scope.allowEcosystem(2))
+	assert.Equal(t, []string{"@1TokenTransfer", "@1MainCondition"}, scope.ecosystemContracts(1))
+	assert.Equal(t, []string{"@5MainCondition"}, scope.ecosystemContracts(5))
+
+	scope = &keyScope{Routes: []string{anyScope}, Contracts: []string{anyScope}}
+	assert.True(t, scope.allowRoute("sendTx"))
+	assert.True(t, scope.allowContract("@1NewUser"))
+	assert.Nil(t, scope.ecosystemContracts(1))
+
+	client := &Client{Scope: &keyScope{}}
+	assert.Error(t, checkContractScope(client, "@1NewUser"))
+	assert.NoError(t, checkContractScope(&Client{}, "@1NewUser"))
+}
+
+func TestParseAPIKey(t *testing.T) {
+	secret := strings.Repeat("ab", apiKeySecretSize)
+	id, data, err := parseAPIKey("12." + secret)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), id)
+	assert.Equal(t, secret, hex.EncodeToString(data))
+	assert.Len(t, hashAPIKeySecret(data), 32)
+
+	for _, v := range []string{"", "12", "12.", "0." + secret, "a." + secret, "12.abcd", "12." + secret + "zz"} {
+		_, _, err = parseAPIKey(v)
+		assert.Error(t, err, v)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{"listWhere", "row"}, splitList(" listWhere, ,row,"))
+	assert.Equal(t, []string{}, splitList(""))
+}
+
+type testEcosystemNames struct{}
+
+func (testEcosystemNames) GetEcosystemName(id int64) (string, error) {
+	return "test", nil
+}
+
+func TestAPIKeyMiddlewares(t *testing.T) {
+	key := &model.APIKey{ID: 1, KeyID: 10, EcosystemID: 1, Routes: `["row"]`, Contracts: `[]`, Ecosystems: `[1]`}
+	withKey := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(r.Header.Get("X-Test-Key")) > 0 {
+				r = setAPIKey(r, key)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	router := mux.NewRouter()
+	router.Use(loggerMiddleware, tokenMiddleware, withKey, Mode{EcosysNameGetter: testEcosystemNames{}}.clientMiddleware)
+	for _, route := range []string{"/api/v2/row/{name}", "/api/v2/listWhere/{name}"} {
+		router.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+	status := func(path string, header http.Header) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header = header
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// the invalid key isn't ignored
+	assert.Equal(t, http.StatusUnauthorized, status("/api/v2/row/keys", http.Header{"Authorization": {apiKeyPrefix + "1.abcd"}}))
+	// the scope is checked for all routes of the key, not only for the routes which require authorization
+	assert.Equal(t, http.StatusOK, status("/api/v2/row/keys", http.Header{"X-Test-Key": {"1"}}))
+	assert.Equal(t, http.StatusForbidden, status("/api/v2/listWhere/keys", http.Header{"X-Test-Key": {"1"}}))
+	assert.Equal(t, http.StatusOK, status("/api/v2/listWhere/keys", http.Header{}))
+}
+
+func TestAPIKeyFormExpire(t *testing.T) {
+	r := httptest.NewRequest("POST", "/api/v2/apikey", nil)
+	form := &apiKeyForm{Routes: "row", Expire: maxAPIKeyExpire + 1}
+	assert.Error(t, form.Validate(r))
+	// the overflow of the expiration time is rejected too
+	form.Expire = 1<<63 - 1
+	assert.Error(t, form.Validate(r))
+}
@@ -213,6 +213,10 @@ func getBlocksDetailedInfoHandler(w http.ResponseWriter, r *http.Request) {
 		errorResponse(w, errNotFound)
 		return
 	}
+	if err = checkPrunedBlock(blocks[0].ID); err != nil {
+		errorResponse(w, err)
+		return
+	}
 
 	result := map[int64]BlockDetailedInfo{}
 	for _, blockModel := range blocks {
@@ -273,3 +277,16 @@ func getBlocksDetailedInfoHandler(w http.ResponseWriter, r *http.Request) {
 
 	jsonResponse(w, &result)
 }
+
+// checkPrunedBlock returns errPruned if the body of the block has been deleted by pruning
+func checkPrunedBlock(blockID int64) error {
+	prunedID, err := model.GetPrunedBlockID()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pruned block")
+		return err
+	}
+	if blockID <= prunedID {
+		return errPruned.Errorf(blockID)
+	}
+	return nil
+}
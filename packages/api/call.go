@@ -0,0 +1,127 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type callResult struct {
+	Result []interface{} `json:"result"`
+}
+
+// callParams decodes the list of parameters of the view function from json body
+func callParams(r *http.Request) ([]interface{}, error) {
+	params := make([]interface{}, 0)
+	if r.ContentLength == 0 {
+		return params, nil
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func callViewHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	client := getClient(r)
+	logger := getLogger(r)
+
+	// the view can't flush contracts, the blocks don't change vm until the view is finished
+	vm := smart.GetVM()
+	vm.RLock()
+	defer vm.RUnlock()
+
+	contract := getContract(r, params["contract"])
+	if contract == nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "contract_name": params["contract"]}).Debug("contract name")
+		errorResponse(w, errContract.Errorf(params["contract"]))
+		return
+	}
+	if err := checkContractScope(client, getContractInfo(contract).Name); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	block := contract.GetView(params["func"])
+	if block == nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "contract_name": params["contract"], "func_name": params["func"]}).Debug("view function")
+		errorResponse(w, errViewFunc.Errorf(params["func"]))
+		return
+	}
+	values, err := callParams(r)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling view params")
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	pars, err := smart.ViewParams(block, values)
+	if err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ib := &model.InfoBlock{}
+	if _, err = ib.Get(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		errorResponse(w, err)
+		return
+	}
+	// view functions cannot modify the database, the transaction is rolled back just in case
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting db transaction")
+		errorResponse(w, err)
+		return
+	}
+	defer dbTransaction.Rollback()
+
+	info := getContractInfo(contract)
+	now := time.Now().Unix()
+	sc := smart.SmartContract{
+		VM: vm,
+		TxSmart: tx.SmartContract{
+			Header: tx.Header{
+				ID:          int(info.Owner.TableID + consts.ShiftContractID),
+				Time:        now,
+				EcosystemID: client.EcosystemID,
+				KeyID:       client.KeyID,
+				NetworkID:   conf.Config.NetworkID,
+			},
+		},
+		TxContract: contract,
+		BlockData: &utils.BlockData{
+			BlockID:      ib.BlockID,
+			Time:         ib.Time,
+			EcosystemID:  ib.EcosystemID,
+			KeyID:        ib.KeyID,
+			NodePosition: converter.StrToInt64(ib.NodePosition),
+		},
+		PreBlockData:  &utils.BlockData{BlockID: ib.BlockID, Hash: ib.Hash},
+		DbTransaction: dbTransaction,
+	}
+	ret, err := sc.CallView(block, pars)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, &callResult{Result: ret})
+}
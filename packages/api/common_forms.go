@@ -79,6 +79,9 @@ func (f *ecosystemForm) Validate(r *http.Request) error {
 		return err
 	}
 
+	if client.Scope != nil && !client.Scope.allowEcosystem(ecosysID) {
+		return errScope.Errorf("ecosystem " + converter.Int64ToStr(ecosysID))
+	}
 	f.EcosystemID = ecosysID
 	f.EcosystemPrefix = converter.Int64ToStr(f.EcosystemID)
 
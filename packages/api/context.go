@@ -9,6 +9,8 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/IBAX-io/go-ibax/packages/model"
+
 	jwt "github.com/dgrijalva/jwt-go"
 	log "github.com/sirupsen/logrus"
 )
@@ -19,6 +21,7 @@ const (
 	contextKeyLogger contextKey = iota
 	contextKeyToken
 	contextKeyClient
+	contextKeyAPIKey
 )
 
 func setContext(r *http.Request, key, value interface{}) *http.Request {
@@ -51,6 +54,17 @@ func getToken(r *http.Request) *jwt.Token {
 	return nil
 }
 
+func setAPIKey(r *http.Request, key *model.APIKey) *http.Request {
+	return setContext(r, contextKeyAPIKey, key)
+}
+
+func getAPIKey(r *http.Request) *model.APIKey {
+	if v := getContext(r, contextKeyAPIKey); v != nil {
+		return v.(*model.APIKey)
+	}
+	return nil
+}
+
 func setClient(r *http.Request, client *Client) *http.Request {
 	return setContext(r, contextKeyClient, client)
 	return nil
@@ -0,0 +1,379 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	ndjsonFormat      = "ndjson"
+	ndjsonContentType = "application/x-ndjson"
+	// streamBatch is the count of rows which are read by one query of the stream
+	streamBatch = 1000
+	// streamMaxRows is the maximum count of rows in the stream, the rest of rows are
+	// requested with the cursor from the trailer
+	streamMaxRows       = 100 * streamBatch
+	streamCursorTrailer = "X-Cursor"
+	// streamErrorTrailer is the error of the interrupted stream, the rest of rows are requested
+	// with the cursor from the X-Cursor trailer
+	streamErrorTrailer = "X-Error"
+	// cursorNullColumn is the selected flag of NULL value of the order column
+	cursorNullColumn = "cursor_null"
+)
+
+var columnName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// cursorForm switches the list to the keyset pagination. The empty cursor returns the first
+// page, the following pages are requested with the cursor from the next field of the result
+type cursorForm struct {
+	Cursor string `schema:"cursor"`
+	Format string `schema:"format"`
+}
+
+type cursorResult struct {
+	List []map[string]string `json:"list"`
+	Next string              `json:"next,omitempty"`
+}
+
+func (f *cursorForm) isStream(r *http.Request) bool {
+	return f.Format == ndjsonFormat || strings.Contains(r.Header.Get("Accept"), ndjsonContentType)
+}
+
+func (f *cursorForm) isKeyset(r *http.Request) bool {
+	_, ok := r.Form["cursor"]
+	return ok || f.isStream(r)
+}
+
+// keyset returns the position of the cursor or the start of the list ordered by the order.
+// The keyset can use only the unique columns and the columns which are permitted to read
+func (f *cursorForm) keyset(order, columns string, unique ...string) (*keyset, error) {
+	var (
+		k   *keyset
+		err error
+	)
+	if len(f.Cursor) > 0 {
+		k, err = decodeCursor(f.Cursor)
+	} else {
+		k, err = newKeyset(order, unique...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if allowed := permittedColumns(columns, unique...); allowed != nil {
+		for _, col := range k.Columns {
+			if !allowed[col] {
+				return nil, errCursorOrder.Errorf(col)
+			}
+		}
+	}
+	return k, nil
+}
+
+// permittedColumns returns the set of plain columns of the prepared columns with the unique columns.
+// It returns nil if all columns are permitted
+func permittedColumns(columns string, unique ...string) map[string]bool {
+	allowed := make(map[string]bool)
+	for _, col := range strings.Split(columns, ",") {
+		col = strings.TrimSpace(col)
+		if col == "*" || len(col) == 0 {
+			return nil
+		}
+		if len(col) > 2 && strings.Count(col, `"`) == 2 && strings.HasPrefix(col, `"`) && strings.HasSuffix(col, `"`) {
+			allowed[col[1:len(col)-1]] = true
+		}
+	}
+	for _, col := range unique {
+		allowed[col] = true
+	}
+	return allowed
+}
+
+// keyset is the position of the last returned row. The rows are ordered by the columns,
+// the first column is the requested order and the rest make the order unique.
+// The order column can be NULL, Null is true if it is NULL in the row of the position
+type keyset struct {
+	Columns []string `json:"c"`
+	Desc    bool     `json:"d,omitempty"`
+	Values  []string `json:"v,omitempty"`
+	Binary  []bool   `json:"b,omitempty"`
+	Null    bool     `json:"n,omitempty"`
+}
+
+// newKeyset parses the order "column [asc|desc]", the row must be identified by the order
+// column with the unique columns
+func newKeyset(order string, unique ...string) (*keyset, error) {
+	k := &keyset{}
+	fields := strings.Fields(strings.ToLower(order))
+	switch len(fields) {
+	case 2:
+		if fields[1] != "asc" && fields[1] != "desc" {
+			return nil, errCursorOrder.Errorf(order)
+		}
+		k.Desc = fields[1] == "desc"
+		fallthrough
+	case 1:
+		if !columnName.MatchString(fields[0]) {
+			return nil, errCursorOrder.Errorf(order)
+		}
+		k.Columns = append(k.Columns, fields[0])
+	case 0:
+	default:
+		return nil, errCursorOrder.Errorf(order)
+	}
+	for _, col := range unique {
+		if len(k.Columns) == 0 || col != k.Columns[0] {
+			k.Columns = append(k.Columns, col)
+		}
+	}
+	if len(k.Columns) == 0 {
+		return nil, errCursorOrder.Errorf(order)
+	}
+	return k, nil
+}
+
+func decodeCursor(cursor string) (*keyset, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errCursor
+	}
+	k := &keyset{}
+	if err = json.Unmarshal(data, k); err != nil || len(k.Columns) == 0 ||
+		len(k.Values) != len(k.Columns) || len(k.Binary) != len(k.Columns) {
+		return nil, errCursor
+	}
+	for _, col := range k.Columns {
+		if !columnName.MatchString(col) {
+			return nil, errCursor
+		}
+	}
+	return k, nil
+}
+
+func (k *keyset) encode() string {
+	data, _ := json.Marshal(k)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (k *keyset) quoted() []string {
+	cols := make([]string, len(k.Columns))
+	for i, col := range k.Columns {
+		cols[i] = `"` + col + `"`
+	}
+	return cols
+}
+
+// nullable reports whether the order column can be NULL, the unique columns are NOT NULL
+func (k *keyset) nullable() bool {
+	return len(k.Columns) > 1
+}
+
+// selectColumns adds the columns of the keyset and the flag of NULL order column to the selected columns
+func (k *keyset) selectColumns(columns string) string {
+	list := strings.Join(k.quoted(), ",") + "," + columns
+	if k.nullable() {
+		list += `,("` + k.Columns[0] + `" IS NULL)::int AS "` + cursorNullColumn + `"`
+	}
+	return list
+}
+
+// orderBy returns the order of rows
+func (k *keyset) orderBy() string {
+	dir := " ASC"
+	if k.Desc {
+		dir = " DESC"
+	}
+	return strings.Join(k.quoted(), dir+",") + dir
+}
+
+// condition returns the condition of the rows after the position, it's empty for the first page.
+// NULL values of the order column follow the other rows in ascending order and precede them
+// in descending order like the default order of postgres
+func (k *keyset) condition() (string, []interface{}) {
+	if len(k.Values) == 0 {
+		return "", nil
+	}
+	op := ">"
+	if k.Desc {
+		op = "<"
+	}
+	cols := k.quoted()
+	params := make([]string, len(k.Values))
+	args := make([]interface{}, len(k.Values))
+	for i, v := range k.Values {
+		params[i] = "?"
+		if k.Binary[i] {
+			params[i] = "decode(?, 'hex')"
+		}
+		args[i] = v
+	}
+	if !k.nullable() {
+		return "(" + strings.Join(cols, ",") + ") " + op + " (" + strings.Join(params, ",") + ")", args
+	}
+	if k.Null {
+		unique := "(" + strings.Join(cols[1:], ",") + ") " + op + " (" + strings.Join(params[1:], ",") + ")"
+		if k.Desc {
+			return "(" + cols[0] + " IS NOT NULL OR " + unique + ")", args[1:]
+		}
+		return "(" + cols[0] + " IS NULL AND " + unique + ")", args[1:]
+	}
+	where := "(" + strings.Join(cols, ",") + ") " + op + " (" + strings.Join(params, ",") + ")"
+	if k.Desc {
+		return where, args
+	}
+	return "(" + where + " OR " + cols[0] + " IS NULL)", args
+}
+
+// after returns the position of the row
+func (k *keyset) after(row map[string]string, binary []bool) (*keyset, error) {
+	next := &keyset{Columns: k.Columns, Desc: k.Desc, Binary: binary}
+	for _, col := range k.Columns {
+		v, ok := row[col]
+		if !ok {
+			return nil, errCursorOrder.Errorf(col)
+		}
+		next.Values = append(next.Values, v)
+	}
+	if k.nullable() {
+		null, ok := row[cursorNullColumn]
+		if !ok {
+			return nil, errCursorOrder.Errorf(k.Columns[0])
+		}
+		next.Null = null == "1"
+	}
+	return next, nil
+}
+
+// removeNullFlag removes the flag of NULL order column from the rows which are sent to the client
+func removeNullFlag(list []map[string]string) {
+	for _, row := range list {
+		delete(row, cursorNullColumn)
+	}
+}
+
+// keysetFetcher returns the rows after the position and the binary flags of the keyset columns
+type keysetFetcher func(k *keyset, limit int) ([]map[string]string, []bool, error)
+
+func readKeysetRows(k *keyset, rows *sql.Rows, getResult func(*sql.Rows) ([]map[string]string, error)) ([]map[string]string, []bool, error) {
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	binary := make([]bool, len(k.Columns))
+	for i, col := range k.Columns {
+		for _, t := range types {
+			if t.Name() == col {
+				binary[i] = t.DatabaseTypeName() == "BYTEA"
+			}
+		}
+	}
+	list, err := getResult(rows)
+	return list, binary, err
+}
+
+// queryFetcher returns the fetcher of the rows of the query
+func queryFetcher(q *gorm.DB, getResult func(*sql.Rows) ([]map[string]string, error)) keysetFetcher {
+	q = q.Session(&gorm.Session{})
+	return func(k *keyset, limit int) ([]map[string]string, []bool, error) {
+		query := q
+		if where, args := k.condition(); len(where) > 0 {
+			query = query.Where(where, args...)
+		}
+		rows, err := query.Order(k.orderBy()).Limit(limit).Rows()
+		if err != nil {
+			return nil, nil, err
+		}
+		return readKeysetRows(k, rows, getResult)
+	}
+}
+
+// keysetResponse writes the page of rows after the position with the cursor of the next page.
+// The stream contains up to streamMaxRows rows after the position, one json object per line.
+// If there are more rows the cursor of the next row is sent in the X-Cursor trailer.
+// If the stream is interrupted by the error the X-Error trailer is sent with the cursor
+func keysetResponse(w http.ResponseWriter, r *http.Request, form *cursorForm, k *keyset, limit int, fetch keysetFetcher) {
+	logger := getLogger(r)
+	if !form.isStream(r) {
+		list, binary, err := fetch(k, limit+1)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting rows after cursor")
+			errorResponse(w, err)
+			return
+		}
+		result := &cursorResult{List: list}
+		if len(list) > limit {
+			result.List = list[:limit]
+			next, err := k.after(result.List[limit-1], binary)
+			if err != nil {
+				errorResponse(w, err)
+				return
+			}
+			result.Next = next.encode()
+		}
+		removeNullFlag(result.List)
+		jsonResponse(w, result)
+		return
+	}
+
+	list, binary, err := fetch(k, streamBatch)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting rows for stream")
+		errorResponse(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("Trailer", streamCursorTrailer+", "+streamErrorTrailer)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	// streamError sends the error of the interrupted stream with the cursor of the last written row
+	streamError := func(err error, msg string) {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error(msg)
+		w.Header().Set(streamErrorTrailer, errQuery.Err)
+		w.Header().Set(streamCursorTrailer, k.encode())
+	}
+	for count := 0; ; {
+		var next *keyset
+		if len(list) == streamBatch {
+			if next, err = k.after(list[len(list)-1], binary); err != nil {
+				streamError(err, "getting position of stream")
+				return
+			}
+		}
+		removeNullFlag(list)
+		for _, row := range list {
+			if err = enc.Encode(row); err != nil {
+				logger.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Debug("writing stream")
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if next == nil {
+			return
+		}
+		k = next
+		if count += len(list); count >= streamMaxRows {
+			w.Header().Set(streamCursorTrailer, k.encode())
+			return
+		}
+		if list, binary, err = fetch(k, streamBatch); err != nil {
+			streamError(err, "getting rows for stream")
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeyset(t *testing.T) {
+	k, err := newKeyset("", "id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, k.Columns)
+	assert.Equal(t, `"id" ASC`, k.orderBy())
+
+	k, err = newKeyset("Amount DESC", "id", "ecosystem")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"amount", "id", "ecosystem"}, k.Columns)
+	assert.Equal(t, `"amount" DESC,"id" DESC,"ecosystem" DESC`, k.orderBy())
+
+	k, err = newKeyset("id desc", "id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, k.Columns)
+
+	for _, order := range []string{"", "id; drop table", "amount up", "amount, id", "a b c"} {
+		_, err = newKeyset(order)
+		assert.Error(t, err, order)
+	}
+}
+
+func TestKeysetCursor(t *testing.T) {
+	k, err := newKeyset("hash desc", "id")
+	require.NoError(t, err)
+	where, args := k.condition()
+	assert.Empty(t, where)
+	assert.Nil(t, args)
+
+	next, err := k.after(map[string]string{"id": "5", "hash": "0a0b", "amount": "1", cursorNullColumn: "0"}, []bool{true, false})
+	require.NoError(t, err)
+	_, err = k.after(map[string]string{"amount": "1"}, []bool{false, false})
+	assert.Error(t, err)
+	// the flag of NULL order column is required
+	_, err = k.after(map[string]string{"id": "5", "hash": "0a0b"}, []bool{true, false})
+	assert.Error(t, err)
+
+	k, err = decodeCursor(next.encode())
+	require.NoError(t, err)
+	assert.Equal(t, next, k)
+	where, args = k.condition()
+	assert.Equal(t, `("hash","id") < (decode(?, 'hex'),?)`, where)
+	assert.Equal(t, []interface{}{"0a0b", "5"}, args)
+
+	for _, cursor := range []string{"abc", "e30", next.encode() + "x"} {
+		_, err = decodeCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
+
+func TestKeysetNull(t *testing.T) {
+	row := map[string]string{"id": "5", "name": "NULL", cursorNullColumn: "1"}
+	for _, v := range []struct {
+		order, where string
+	}{
+		{"name", `("name" IS NULL AND ("id") > (?))`},
+		{"name desc", `("name" IS NOT NULL OR ("id") < (?))`},
+	} {
+		k, err := newKeyset(v.order, "id")
+		require.NoError(t, err)
+		assert.Equal(t, `"name","id",*,("name" IS NULL)::int AS "cursor_null"`, k.selectColumns("*"))
+		next, err := k.after(row, []bool{false, false})
+		require.NoError(t, err)
+		assert.True(t, next.Null)
+		where, args := next.condition()
+		assert.Equal(t, v.where, where)
+		assert.Equal(t, []interface{}{"5"}, args)
+	}
+
+	// the rows with NULL follow the other rows in ascending order
+	k, err := newKeyset("name", "id")
+	require.NoError(t, err)
+	row["name"], row[cursorNullColumn] = "NULL", "0"
+	next, err := k.after(row, []bool{false, false})
+	require.NoError(t, err)
+	assert.False(t, next.Null)
+	where, args := next.condition()
+	assert.Equal(t, `(("name","id") > (?,?) OR "name" IS NULL)`, where)
+	assert.Equal(t, []interface{}{"NULL", "5"}, args)
+
+	// the flag isn't sent to the client and the unique column can't be NULL
+	list := []map[string]string{row}
+	removeNullFlag(list)
+	assert.Equal(t, map[string]string{"id": "5", "name": "NULL"}, list[0])
+	k, err = newKeyset("id", "id")
+	require.NoError(t, err)
+	assert.Equal(t, `"id",*`, k.selectColumns("*"))
+}
+
+func TestCursorPermittedColumns(t *testing.T) {
+	form := &cursorForm{}
+	k, err := form.keyset("amount desc", "*", "id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"amount", "id"}, k.Columns)
+
+	columns := `"id","amount",data::jsonb->>'key' as "data.key"`
+	_, err = form.keyset("amount", columns, "id")
+	assert.NoError(t, err)
+	for _, order := range []string{"secret", "data", "data.key"} {
+		_, err = form.keyset(order, columns, "id")
+		assert.Error(t, err, order)
+	}
+
+	// the cursor can't read the columns which are not permitted
+	form.Cursor = (&keyset{Columns: []string{"secret", "id"}, Values: []string{"a", "1"},
+		Binary: []bool{false, false}}).encode()
+	_, err = form.keyset("", columns, "id")
+	assert.Error(t, err)
+	_, err = form.keyset("", "*", "id")
+	assert.NoError(t, err)
+}
+
+func TestKeysetStreamError(t *testing.T) {
+	k, err := newKeyset("", "id")
+	require.NoError(t, err)
+	var calls int
+	fetch := func(k *keyset, limit int) ([]map[string]string, []bool, error) {
+		if calls++; calls > 1 {
+			return nil, nil, errors.New("connection is lost")
+		}
+		list := make([]map[string]string, limit)
+		for i := range list {
+			list[i] = map[string]string{"id": strconv.Itoa(i + 1)}
+		}
+		return list, []bool{false}, nil
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/v2/list/keys?format=ndjson", nil)
+	keysetResponse(w, r, &cursorForm{Format: ndjsonFormat}, k, 10, fetch)
+
+	resp := w.Result()
+	assert.Equal(t, errQuery.Err, resp.Trailer.Get(streamErrorTrailer))
+	// the cursor points to the last written row
+	next, err := decodeCursor(resp.Trailer.Get(streamCursorTrailer))
+	require.NoError(t, err)
+	assert.Equal(t, []string{strconv.Itoa(streamBatch)}, next.Values)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/model"
 	log "github.com/sirupsen/logrus"
 	"net/http"
+	"strings"
 )
 
 type tableInfoForm struct {
@@ -22,6 +23,21 @@ type columnsInfo struct {
 }
 type rowsInfo struct {
 	columnsInfo
+	cursorForm
+}
+
+// nodeTablesOrder is the order of the node tables without id by their primary keys
+var nodeTablesOrder = map[string]string{
+	"confirmations":         "block_id desc",
+	"info_block":            "hash asc",
+	"install":               "progress asc",
+	"log_transactions":      "hash asc",
+	"queue_blocks":          "hash asc",
+	"queue_tx":              "hash asc",
+	"stop_daemons":          "stop_time asc",
+	"transactions":          "hash asc",
+	"transactions_attempts": "hash asc",
+	"transactions_status":   "hash asc",
 }
 
 func (f *tableInfoForm) Validate(r *http.Request) error {
@@ -146,6 +162,20 @@ func getOpenRowsInfoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if form.isKeyset(r) {
+		order, unique := form.Order, []string{"id"}
+		if v, ok := nodeTablesOrder[form.Name]; ok {
+			order, unique = v, nil
+		}
+		k, err := form.keyset(order, "*", unique...)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		keysetResponse(w, r, &form.cursorForm, k, form.Limit, rowsInfoFetcher(form.Name, form.InWhere))
+		return
+	}
+
 	result, err := GetRowsInfo(form.Name, form.Order, form.Offset, form.Limit, form.InWhere)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("get rows info failed")
@@ -154,25 +184,38 @@ func getOpenRowsInfoHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	jsonResponse(w, result)
 }
+// rowsInfoFetcher returns the fetcher of the rows of the node table
+func rowsInfoFetcher(tableName, where string) keysetFetcher {
+	return func(k *keyset, limit int) ([]map[string]string, []bool, error) {
+		var conds []string
+		if len(where) > 0 {
+			conds = append(conds, "("+where+")")
+		}
+		cond, args := k.condition()
+		if len(cond) > 0 {
+			conds = append(conds, cond)
+		}
+		sqlQuery := fmt.Sprintf(`select %s from "%s"`, k.selectColumns("*"), tableName)
+		if len(conds) > 0 {
+			sqlQuery += " where " + strings.Join(conds, " and ")
+		}
+		sqlQuery += fmt.Sprintf(" order by %s limit %d", k.orderBy(), limit)
+		rows, err := model.GetDB(nil).Raw(sqlQuery, args...).Rows()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getRows raw err:%s in query %s", err, sqlQuery)
+		}
+		return readKeysetRows(k, rows, model.GetResult)
+	}
+}
+
 func GetRowsInfo(tableName, order string, offset, limit int, where string) (*listResult, error) {
 	result := &listResult{}
 	num, err := model.GetNodeRows(tableName)
 	if err != nil {
 		return result, err
 	}
-	var primaryOrder = make(map[string]string)
-	primaryOrder["confirmations"] = "block_id desc"
-	primaryOrder["info_block"] = "hash asc"
-	primaryOrder["install"] = "progress asc"
-	primaryOrder["log_transactions"] = "hash asc"
-	primaryOrder["queue_blocks"] = "hash asc"
-	primaryOrder["queue_tx"] = "hash asc"
-	primaryOrder["stop_daemons"] = "stop_time asc"
-	primaryOrder["transactions"] = "hash asc"
-	primaryOrder["transactions_attempts"] = "hash asc"
-	primaryOrder["transactions_status"] = "hash asc"
 	execOrder := order
-	if v, ok := primaryOrder[tableName]; ok {
+	if v, ok := nodeTablesOrder[tableName]; ok {
 		execOrder = v
 	}
 	if execOrder == "" {
@@ -14,26 +14,36 @@ import (
 var (
 	defaultStatus        = http.StatusBadRequest
 	ErrEcosystemNotFound = errors.New("Ecosystem not found")
+	errAPIKey            = errType{"E_APIKEY", "API key is not valid", http.StatusUnauthorized}
+	errAPIKeyExpire      = errType{"E_APIKEYEXPIRE", "Expire of API key must not exceed %d seconds", http.StatusBadRequest}
 	errContract          = errType{"E_CONTRACT", "There is not %s contract", http.StatusNotFound}
 	errDBNil             = errType{"E_DBNIL", "DB is nil", defaultStatus}
 	errDeletedKey        = errType{"E_DELETEDKEY", "The key is deleted", http.StatusForbidden}
+	errCursor            = errType{"E_CURSOR", "Cursor is invalid", http.StatusBadRequest}
+	errCursorOrder       = errType{"E_CURSORORDER", "Order %s cannot be used with cursor", http.StatusBadRequest}
 	errEcosystem         = errType{"E_ECOSYSTEM", "Ecosystem %d doesn't exist", defaultStatus}
 	errEmptyPublic       = errType{"E_EMPTYPUBLIC", "Public key is undefined", http.StatusBadRequest}
 	errKeyNotFound       = errType{"E_KEYNOTFOUND", "Key has not been found", http.StatusNotFound}
+	errEmptyRoutes       = errType{"E_EMPTYROUTES", "Routes of API key are undefined", http.StatusBadRequest}
 	errEmptySign         = errType{"E_EMPTYSIGN", "Signature is undefined", defaultStatus}
 	errHashWrong         = errType{"E_HASHWRONG", "Hash is incorrect", http.StatusBadRequest}
 	errHashNotFound      = errType{"E_HASHNOTFOUND", "Hash has not been found", defaultStatus}
 	errHeavyPage         = errType{"E_HEAVYPAGE", "This page is heavy", defaultStatus}
 	errInstalled         = errType{"E_INSTALLED", "Chain is already installed", defaultStatus}
 	errInvalidWallet     = errType{"E_INVALIDWALLET", "Wallet %s is not valid", http.StatusBadRequest}
+	errInvalidTopics     = errType{"E_INVALIDTOPICS", "Topics must be json object", http.StatusBadRequest}
 	errLimitForsign      = errType{"E_LIMITFORSIGN", "Length of forsign is too big (%d)", defaultStatus}
 	errLimitTxSize       = errType{"E_LIMITTXSIZE", "The size of tx is too big (%d)", defaultStatus}
 	errNotFound          = errType{"E_NOTFOUND", "Page not found", http.StatusNotFound}
 	errNotFoundRecord    = errType{"E_NOTFOUND", "Record not found", http.StatusNotFound}
 	errParamNotFound     = errType{"E_PARAMNOTFOUND", "Parameter %s has not been found", http.StatusNotFound}
 	errPermission        = errType{"E_PERMISSION", "Permission denied", http.StatusUnauthorized}
+	errPruned            = errType{"E_PRUNED", "Block %d is pruned", http.StatusGone}
 	errQuery             = errType{"E_QUERY", "DB query is wrong", http.StatusInternalServerError}
+	errQuorum            = errType{"E_QUORUM", "Value has not been confirmed by honor nodes", http.StatusServiceUnavailable}
+	errRateLimit         = errType{"E_RATELIMIT", "Too many requests, retry after %d seconds", http.StatusTooManyRequests}
 	errRecovered         = errType{"E_RECOVERED", "API recovered", http.StatusInternalServerError}
+	errScope             = errType{"E_SCOPE", "API key has no access to %s", http.StatusForbidden}
 	errServer            = errType{"E_SERVER", "Server error", defaultStatus}
 	errSignature         = errType{"E_SIGNATURE", "Signature is incorrect", http.StatusBadRequest}
 	errUnknownSign       = errType{"E_UNKNOWNSIGN", "Unknown signature", defaultStatus}
@@ -44,6 +54,7 @@ var (
 	errUnauthorized      = errType{"E_UNAUTHORIZED", "Unauthorized", http.StatusUnauthorized}
 	errUndefineval       = errType{"E_UNDEFINEVAL", "Value %s is undefined", defaultStatus}
 	errUnknownUID        = errType{"E_UNKNOWNUID", "Unknown uid", defaultStatus}
+	errViewFunc          = errType{"E_VIEWFUNC", "There is not %s view function", http.StatusNotFound}
 	errOBS               = errType{"E_OBS", "Virtual Dedicated Ecosystem %d doesn't exist", defaultStatus}
 	errOBSCreated        = errType{"E_OBSCREATED", "Virtual Dedicated Ecosystem is already created", http.StatusBadRequest}
 	Err     string `json:"error"`
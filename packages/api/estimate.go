@@ -0,0 +1,136 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/notificator"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// estimateParams decodes the parameters of the contract from json body.
+// The integer numbers are converted to int64 and other numbers to float64
+func estimateParams(r *http.Request) (map[string]interface{}, error) {
+	params := make(map[string]interface{})
+	if r.ContentLength == 0 {
+		return params, nil
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, err
+	}
+	for key, val := range params {
+		if num, ok := val.(json.Number); ok {
+			if i, err := num.Int64(); err == nil {
+				params[key] = i
+			} else if f, err := num.Float64(); err == nil {
+				params[key] = f
+			}
+		}
+	}
+	return params, nil
+}
+
+func estimateContractHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	client := getClient(r)
+	logger := getLogger(r)
+
+	// the blocks don't flush contracts into vm until the estimation is finished
+	vm := smart.GetVM()
+	vm.RLock()
+	defer vm.RUnlock()
+
+	contract := getContract(r, name)
+	if contract == nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "contract_name": name}).Debug("contract name")
+		errorResponse(w, errContract.Errorf(name))
+		return
+	}
+	info := getContractInfo(contract)
+	if err := checkContractScope(client, info.Name); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	params, err := estimateParams(r)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling contract params")
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	txData := make(map[string]interface{})
+	if info.Tx != nil {
+		if txData, err = smart.FillTxData(*info.Tx, params); err != nil {
+			errorResponse(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	ib := &model.InfoBlock{}
+	if _, err = ib.Get(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		errorResponse(w, err)
+		return
+	}
+	// all changes of the contract are discarded, the contracts are flushed into the copy of vm
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting db transaction")
+		errorResponse(w, err)
+		return
+	}
+	defer dbTransaction.Rollback()
+
+	now := time.Now().Unix()
+	sc := smart.SmartContract{
+		VM: vm,
+		TxSmart: tx.SmartContract{
+			Header: tx.Header{
+				ID:          int(info.Owner.TableID + consts.ShiftContractID),
+				Time:        now,
+				EcosystemID: client.EcosystemID,
+				KeyID:       client.KeyID,
+				NetworkID:   conf.Config.NetworkID,
+			},
+			Params: params,
+		},
+		TxData:     txData,
+		TxContract: contract,
+		BlockData: &utils.BlockData{
+			BlockID:      ib.BlockID + 1,
+			Time:         now,
+			EcosystemID:  ib.EcosystemID,
+			KeyID:        ib.KeyID,
+			NodePosition: converter.StrToInt64(ib.NodePosition),
+		},
+		PreBlockData:  &utils.BlockData{BlockID: ib.BlockID, Hash: ib.Hash},
+		DbTransaction: dbTransaction,
+		Rand:          rand.New(rand.NewSource(now)),
+		Notifications: notificator.NewQueue(),
+		RollBackTx:    make([]*model.RollbackTx, 0),
+	}
+	result, err := sc.EstimateContract()
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, result)
+}
@@ -0,0 +1,92 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// eventsForm is the filter of the events of the ecosystem of the client. The pages are requested
+// with after_id which is the next field of the previous page
+type eventsForm struct {
+	paginatorForm
+	Contract  string `schema:"contract"`
+	Name      string `schema:"name"`
+	FromBlock int64  `schema:"from_block"`
+	ToBlock   int64  `schema:"to_block"`
+	Topics    string `schema:"topics"`
+	AfterID   int64  `schema:"after_id"`
+}
+
+type eventsResult struct {
+	List []*model.ContractEventInfo `json:"list"`
+	Next int64                      `json:"next,omitempty"`
+}
+
+func (f *eventsForm) Validate(r *http.Request) error {
+	if len(f.Topics) > 0 {
+		var topics map[string]string
+		if err := json.Unmarshal([]byte(f.Topics), &topics); err != nil {
+			return errInvalidTopics
+		}
+	}
+	return f.paginatorForm.Validate(r)
+}
+
+func getEventsHandler(w http.ResponseWriter, r *http.Request) {
+	form := &eventsForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	client := getClient(r)
+	logger := getLogger(r)
+
+	filter := &model.ContractEventFilter{
+		EcosystemID: client.EcosystemID,
+		Contract:    form.Contract,
+		Name:        form.Name,
+		FromBlock:   form.FromBlock,
+		ToBlock:     form.ToBlock,
+		Topics:      form.Topics,
+		AfterID:     form.AfterID,
+		Limit:       form.Limit,
+	}
+	// the api key which is limited by contracts gets only the events of its contracts
+	if len(form.Contract) > 0 {
+		if err := checkContractScope(client, form.Contract); err != nil {
+			errorResponse(w, err)
+			return
+		}
+	} else if client.Scope != nil {
+		filter.Contracts = client.Scope.ecosystemContracts(client.EcosystemID)
+		if filter.Contracts != nil && len(filter.Contracts) == 0 {
+			errorResponse(w, errScope.Errorf("events"))
+			return
+		}
+	}
+	events, err := model.GetContractEvents(filter)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting contract events")
+		errorResponse(w, err)
+		return
+	}
+
+	result := &eventsResult{List: make([]*model.ContractEventInfo, 0, len(events))}
+	for i := range events {
+		result.List = append(result.List, events[i].Info())
+	}
+	if len(events) == form.Limit {
+		result.Next = events[len(events)-1].ID
+	}
+	jsonResponse(w, result)
+}
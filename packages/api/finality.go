@@ -0,0 +1,61 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// FinalityResult is the finality certificate of the block
+type FinalityResult struct {
+	BlockID int64         `json:"block_id"`
+	Hash    []byte        `json:"hash"`
+	Time    int64         `json:"time"`
+	Votes   []*utils.Vote `json:"votes"`
+}
+
+// getFinalityHandler returns the certificate of the last finalized block or the first certificate
+// which finalizes the specified block. All blocks up to the certified block cannot be rolled back
+func getFinalityHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+
+	var (
+		found bool
+		err   error
+	)
+	fc := &model.FinalityCertificate{}
+	if id, ok := mux.Vars(r)["id"]; ok {
+		found, err = fc.GetFor(converter.StrToInt64(id))
+	} else {
+		found, err = fc.GetLast(nil)
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting finality certificate")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errNotFoundRecord)
+		return
+	}
+
+	result := &FinalityResult{BlockID: fc.BlockID, Hash: fc.Hash, Time: fc.Time}
+	if err = json.Unmarshal(fc.Votes, &result.Votes); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling votes")
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, result)
+}
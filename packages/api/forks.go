@@ -0,0 +1,81 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// lastReorgsLimit is the count of the latest reorgs which are returned by /forks
+const lastReorgsLimit = 10
+
+// ForkResult is the alternative branch which starts from the block ForkID
+type ForkResult struct {
+	ForkID int64             `json:"fork_id"`
+	Blocks []model.ForkBlock `json:"blocks"`
+}
+
+// ReorgResult is the replacement of the blocks of the node with another branch
+type ReorgResult struct {
+	BlockID int64           `json:"block_id"`
+	Depth   int64           `json:"depth"`
+	OldHash []byte          `json:"old_hash"`
+	NewHash []byte          `json:"new_hash"`
+	Host    string          `json:"host"`
+	Txs     json.RawMessage `json:"txs"`
+	Time    int64           `json:"time"`
+}
+
+// ForksResult is the result of /forks
+type ForksResult struct {
+	Forks  []ForkResult  `json:"forks"`
+	Reorgs []ReorgResult `json:"reorgs"`
+}
+
+// getForksHandler returns the known alternative branches and the latest reorgs of the node
+func getForksHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+
+	blocks, err := model.GetForkBlocks()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting fork blocks")
+		errorResponse(w, err)
+		return
+	}
+	reorgs, err := model.GetLastReorgs(lastReorgsLimit)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting reorgs")
+		errorResponse(w, err)
+		return
+	}
+
+	result := &ForksResult{Forks: make([]ForkResult, 0), Reorgs: make([]ReorgResult, 0, len(reorgs))}
+	for _, b := range blocks {
+		if last := len(result.Forks) - 1; last >= 0 && result.Forks[last].ForkID == b.ForkID {
+			result.Forks[last].Blocks = append(result.Forks[last].Blocks, b)
+			continue
+		}
+		result.Forks = append(result.Forks, ForkResult{ForkID: b.ForkID, Blocks: []model.ForkBlock{b}})
+	}
+	for _, reorg := range reorgs {
+		result.Reorgs = append(result.Reorgs, ReorgResult{
+			BlockID: reorg.BlockID,
+			Depth:   reorg.Depth,
+			OldHash: reorg.OldHash,
+			NewHash: reorg.NewHash,
+			Host:    reorg.Host,
+			Txs:     json.RawMessage(reorg.Txs),
+			Time:    reorg.Time,
+		})
+	}
+	jsonResponse(w, result)
+}
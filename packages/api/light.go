@@ -0,0 +1,108 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/light"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// LightRowResult is the row which is confirmed by honor nodes at the verified block
+type LightRowResult struct {
+	BlockID int64             `json:"block_id"`
+	Value   map[string]string `json:"value"`
+}
+
+func getLightRow(r *http.Request, name string, id int64) (*LightRowResult, error) {
+	logger := getLogger(r)
+
+	ecosystem := converter.StrToInt64(r.FormValue("ecosystem"))
+	if ecosystem <= 0 {
+		ecosystem = 1
+	}
+	proof, err := light.GetRow(r.Context(), name, id, ecosystem)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.NotFound, "error": err, "table": name, "id": id}).Warn("getting row from honor nodes")
+		return nil, errQuorum
+	}
+	if len(proof.Data) == 0 || string(proof.Data) == "null" {
+		return nil, errNotFoundRecord
+	}
+	result := &LightRowResult{BlockID: proof.BlockID}
+	if err = json.Unmarshal(proof.Data, &result.Value); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling row")
+		return nil, err
+	}
+	return result, nil
+}
+
+func getLightMaxBlockHandler(w http.ResponseWriter, r *http.Request) {
+	header := &model.LightHeader{}
+	found, err := header.GetLast()
+	if err != nil {
+		getLogger(r).WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last header")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errNotFound)
+		return
+	}
+	jsonResponse(w, &maxBlockResult{header.BlockID})
+}
+
+func getLightHeaderHandler(w http.ResponseWriter, r *http.Request) {
+	header := &model.LightHeader{}
+	found, err := header.Get(converter.StrToInt64(mux.Vars(r)["id"]))
+	if err != nil {
+		getLogger(r).WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting header")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errNotFoundRecord)
+		return
+	}
+	jsonResponse(w, header)
+}
+
+func getLightRowHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	result, err := getLightRow(r, params["name"], converter.StrToInt64(params["id"]))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, result)
+}
+
+func getLightBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	wallet := mux.Vars(r)["wallet"]
+	keyID := converter.StringToAddress(wallet)
+	if keyID == 0 {
+		errorResponse(w, errInvalidWallet.Errorf(wallet))
+		return
+	}
+
+	result, err := getLightRow(r, "keys", keyID)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, &balanceResult{
+		Amount: result.Value["amount"],
+		Money:  converter.ChainMoney(result.Value["amount"]),
+	})
+}
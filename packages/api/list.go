@@ -41,6 +41,7 @@ type listForm struct {
 
 type listWhereForm struct {
 	listForm
+	cursorForm
 	Order   string `schema:"order"`
 	InWhere string `schema:"where"`
 }
@@ -185,6 +186,21 @@ func getListWhereHandler(w http.ResponseWriter, r *http.Request) {
 		q = q.Where(where)
 	}
 
+	if form.isKeyset(r) {
+		k, err := form.keyset(form.Order, form.Columns, "id")
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		columns := "*"
+		if len(form.Columns) > 0 {
+			columns = smart.PrepareColumns([]string{form.Columns})
+		}
+		q = q.Select(k.selectColumns(columns))
+		keysetResponse(w, r, &form.cursorForm, k, form.Limit, queryFetcher(q, model.GetResult))
+		return
+	}
+
 	result := new(listResult)
 	err = q.Count(&result.Count).Error
 
@@ -278,6 +294,26 @@ func getnodeListWhereHandler(w http.ResponseWriter, r *http.Request) {
 		q = q.Where(where)
 	}
 
+	if form.isKeyset(r) {
+		// the rows of all ecosystems are in the tables of the first ecosystem
+		unique := []string{"id"}
+		if converter.FirstEcosystemTables[params["name"]] {
+			unique = append(unique, "ecosystem")
+		}
+		k, err := form.keyset(form.Order, form.Columns, unique...)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		columns := "*"
+		if len(form.Columns) > 0 {
+			columns = smart.PrepareColumns([]string{form.Columns})
+		}
+		q = q.Select(k.selectColumns(columns))
+		keysetResponse(w, r, &form.cursorForm, k, form.Limit, queryFetcher(q, model.GetNodeResult))
+		return
+	}
+
 	result := new(listResult)
 	err = q.Count(&result.Count).Error
 
@@ -6,12 +6,17 @@
 package api
 
 import (
+	"bufio"
 	"fmt"
+	"net"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/metrics"
 	"github.com/IBAX-io/go-ibax/packages/service"
 	"github.com/IBAX-io/go-ibax/packages/statsd"
 
@@ -78,6 +83,16 @@ func tokenMiddleware(next http.Handler) http.Handler {
 	const authHeader = "AUTHORIZATION"
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header := r.Header.Get(authHeader); strings.HasPrefix(header, apiKeyPrefix) {
+			key, err := findAPIKey(header, time.Now())
+			if err != nil {
+				getLogger(r).WithFields(log.Fields{"type": consts.AccessDenied, "error": err}).Warning("starting session by api key")
+				errorResponse(w, err)
+				return
+			}
+			next.ServeHTTP(w, setAPIKey(r, key))
+			return
+		}
 		//token, err := RefreshToken(r.Header.Get(authHeader))
 		token, err := parseJWTToken(r.Header.Get(authHeader))
 		if err != nil {
@@ -101,11 +116,30 @@ func (m Mode) clientMiddleware(next http.Handler) http.Handler {
 				errorResponse(w, err)
 				return
 			}
+		} else if key := getAPIKey(r); key != nil {
+			var err error
+			if client, err = getClientFromAPIKey(key, m.EcosysNameGetter); err != nil {
+				errorResponse(w, err)
+				return
+			}
 		}
 		if client == nil {
 			// create client with default ecosystem
 			client = &Client{EcosystemID: 1}
 		}
+		if client.Scope != nil {
+			if err := checkScope(r, client); err != nil {
+				getLogger(r).WithFields(log.Fields{"type": consts.AccessDenied, "key_id": client.KeyID, "error": err}).Warning("api key scope")
+				errorResponse(w, err)
+				return
+			}
+			// the role may have been taken away from the account after the key was minted
+			if err := checkKeyRole(client); err != nil {
+				getLogger(r).WithFields(log.Fields{"type": consts.AccessDenied, "key_id": client.KeyID, "role": client.RoleID, "error": err}).Warning("api key role")
+				errorResponse(w, err)
+				return
+			}
+		}
 		r = setClient(r, client)
 		next.ServeHTTP(w, r)
 	})
@@ -127,3 +161,43 @@ func statsdMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// statusWriter keeps the status of the response, it supports streaming and WebSocket
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := sw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer doesn't support hijacking")
+	}
+	sw.status = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
+
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if tpl, err := mux.CurrentRoute(r).GetPathTemplate(); err == nil {
+			route = tpl
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		startTime := time.Now()
+
+		next.ServeHTTP(sw, r)
+		metrics.HTTPDuration.WithLabelValues(r.Method, route, strconv.Itoa(sw.status)).Observe(time.Since(startTime).Seconds())
+	})
+}
@@ -0,0 +1,315 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/metrics"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ecosystemRateLimitParam is the ecosystem parameter "rate,burst" which lowers the limit of keys
+	ecosystemRateLimitParam = "api_rate_limit"
+	// ecosystemLimitTTL is the time of caching the limits of ecosystems
+	ecosystemLimitTTL = time.Minute
+	// sweepPeriod is the period of deleting the full buckets
+	sweepPeriod = 10 * time.Minute
+
+	scopeIP    = "ip"
+	scopeKey   = "key"
+	scopeRoute = "route"
+	scopeQuota = "quota"
+)
+
+var errRateLimitValue = errors.New("rate limit must be in the format rate,burst with the positive rate and burst")
+
+var apiLimiter = newRateLimiter(loadEcosystemLimit)
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens which have been accumulated since the last request
+func (b *tokenBucket) refill(limit conf.RateLimit, now time.Time) {
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+}
+
+// quotaWindow counts the requests until the window expires
+type quotaWindow struct {
+	count   int64
+	expires time.Time
+}
+
+type ecosystemLimit struct {
+	limit   conf.RateLimit
+	found   bool
+	expires time.Time
+}
+
+// rateLimiter keeps the token buckets of addresses, keys and groups of routes
+type rateLimiter struct {
+	mu         sync.Mutex
+	buckets    map[string]*tokenBucket
+	quotas     map[string]*quotaWindow
+	ecosystems map[int64]ecosystemLimit
+	lastSweep  time.Time
+	now        func() time.Time
+	loadLimit  func(ecosystemID int64) (conf.RateLimit, bool, error)
+}
+
+func newRateLimiter(loadLimit func(int64) (conf.RateLimit, bool, error)) *rateLimiter {
+	return &rateLimiter{
+		buckets:    make(map[string]*tokenBucket),
+		quotas:     make(map[string]*quotaWindow),
+		ecosystems: make(map[int64]ecosystemLimit),
+		now:        time.Now,
+		loadLimit:  loadLimit,
+	}
+}
+
+// allow takes the token from the bucket, otherwise it returns the time of waiting for the token
+func (rl *rateLimiter) allow(key string, limit conf.RateLimit) (bool, time.Duration) {
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		rl.buckets[key] = b
+	}
+	b.refill(limit, now)
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// quota counts the request in the current window of the period, otherwise it returns
+// the time of waiting for the next window
+func (rl *rateLimiter) quota(key string, max int64, period time.Duration) (bool, time.Duration) {
+	if max <= 0 || period <= 0 {
+		return true, 0
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+	q, ok := rl.quotas[key]
+	if !ok || !now.Before(q.expires) {
+		q = &quotaWindow{expires: now.Add(period)}
+		rl.quotas[key] = q
+	}
+	if q.count >= max {
+		return false, q.expires.Sub(now)
+	}
+	q.count++
+	return true, 0
+}
+
+// sweep deletes the buckets which have been refilled and the expired quotas, they are the same as new ones
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < sweepPeriod {
+		return
+	}
+	rl.lastSweep = now
+	for key, b := range rl.buckets {
+		if now.Sub(b.last) >= sweepPeriod {
+			delete(rl.buckets, key)
+		}
+	}
+	for key, q := range rl.quotas {
+		if !now.Before(q.expires) {
+			delete(rl.quotas, key)
+		}
+	}
+}
+
+// keyLimit returns the limit of the keys of the ecosystem. The ecosystem can only lower the limit of the node
+func (rl *rateLimiter) keyLimit(ecosystemID int64) conf.RateLimit {
+	rl.mu.Lock()
+	cached, ok := rl.ecosystems[ecosystemID]
+	rl.mu.Unlock()
+
+	if now := rl.now(); !ok || now.After(cached.expires) {
+		limit, found, err := rl.loadLimit(ecosystemID)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "ecosystem": ecosystemID}).Error("getting rate limit of ecosystem")
+		}
+		cached = ecosystemLimit{limit: limit, found: found && err == nil, expires: now.Add(ecosystemLimitTTL)}
+		rl.mu.Lock()
+		rl.ecosystems[ecosystemID] = cached
+		rl.mu.Unlock()
+	}
+	if cached.found {
+		return capLimit(conf.Config.RateLimit.Key, cached.limit)
+	}
+	return conf.Config.RateLimit.Key
+}
+
+// capLimit returns the limit which doesn't exceed the limit of the node
+func capLimit(node, limit conf.RateLimit) conf.RateLimit {
+	if node.Rate <= 0 {
+		return limit
+	}
+	if node.Burst < limit.Burst {
+		limit.Burst = node.Burst
+	}
+	limit.Rate = math.Min(node.Rate, limit.Rate)
+	return limit
+}
+
+// parseRateLimit parses the value "rate,burst" of the ecosystem parameter. The zero rate isn't
+// allowed, the ecosystem can't make the keys unlimited
+func parseRateLimit(value string) (conf.RateLimit, error) {
+	var limit conf.RateLimit
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return limit, errRateLimitValue
+	}
+	var err error
+	if limit.Rate, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil || limit.Rate <= 0 {
+		return limit, errRateLimitValue
+	}
+	if limit.Burst, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil || limit.Burst < 1 {
+		return limit, errRateLimitValue
+	}
+	return limit, nil
+}
+
+func loadEcosystemLimit(ecosystemID int64) (conf.RateLimit, bool, error) {
+	sp := &model.StateParameter{}
+	sp.SetTablePrefix(converter.Int64ToStr(ecosystemID))
+	found, err := sp.Get(nil, ecosystemRateLimitParam)
+	if err != nil || !found {
+		return conf.RateLimit{}, false, err
+	}
+	limit, err := parseRateLimit(sp.Value)
+	if err != nil {
+		return limit, false, err
+	}
+	return limit, true, nil
+}
+
+// routeGroup returns the first part of the route after the version of API, e.g. listWhere
+func routeGroup(tpl string) string {
+	tpl = strings.TrimPrefix(tpl, "/")
+	if i := strings.IndexByte(tpl, '/'); strings.HasPrefix(tpl, "api/") && i >= 0 {
+		tpl = tpl[i+1:]
+		if i = strings.IndexByte(tpl, '/'); i >= 0 {
+			tpl = tpl[i+1:]
+		}
+	}
+	if i := strings.IndexByte(tpl, '/'); i >= 0 {
+		tpl = tpl[:i]
+	}
+	return tpl
+}
+
+// rateCheck is the bucket which is checked for the request
+type rateCheck struct {
+	scope, bucket string
+	limit         conf.RateLimit
+}
+
+// requestGroup returns the group of the route of the request
+func requestGroup(r *http.Request) string {
+	if tpl, err := mux.CurrentRoute(r).GetPathTemplate(); err == nil {
+		return routeGroup(tpl)
+	}
+	return "unknown"
+}
+
+// ipOwner returns the owner of the buckets of the address
+func ipOwner(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return scopeIP + ":" + ip
+}
+
+// rejectRequest responds 429 with the time of waiting in the Retry-After header
+func rejectRequest(w http.ResponseWriter, r *http.Request, scope, bucket string, wait time.Duration) {
+	retry := int64(math.Ceil(wait.Seconds()))
+	metrics.RateLimited.WithLabelValues(scope, requestGroup(r)).Inc()
+	getLogger(r).WithFields(log.Fields{"type": consts.ParameterExceeded, "bucket": bucket, "retry": retry}).Debug("rate limit exceeded")
+	w.Header().Set("Retry-After", strconv.FormatInt(retry, 10))
+	errorResponse(w, errRateLimit.Errorf(retry))
+}
+
+// ipRateLimitMiddleware limits the requests of the address. It goes before tokenMiddleware
+// so the lookups of api keys are limited too
+func ipRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		owner := ipOwner(r)
+		if ok, wait := apiLimiter.allow(owner, conf.Config.RateLimit.IP); !ok {
+			rejectRequest(w, r, scopeIP, owner, wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitMiddleware limits the requests of the key and the group of routes and counts the quota of the key
+func rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := conf.Config.RateLimit
+		group := requestGroup(r)
+		owner := ipOwner(r)
+
+		var checks []rateCheck
+		client := getClient(r)
+		if client != nil && client.KeyID != 0 {
+			owner = scopeKey + ":" + converter.Int64ToStr(client.KeyID)
+			checks = append(checks, rateCheck{scopeKey, owner, apiLimiter.keyLimit(client.EcosystemID)})
+		}
+		if limit, ok := cfg.Routes[group]; ok {
+			checks = append(checks, rateCheck{scopeRoute, scopeRoute + ":" + group + ":" + owner, limit})
+		}
+
+		for _, c := range checks {
+			if ok, wait := apiLimiter.allow(c.bucket, c.limit); !ok {
+				rejectRequest(w, r, c.scope, c.bucket, wait)
+				return
+			}
+		}
+		if client != nil && client.KeyID != 0 {
+			bucket := scopeQuota + ":" + owner
+			if ok, wait := apiLimiter.quota(bucket, cfg.KeyQuota, time.Duration(cfg.QuotaPeriod)*time.Minute); !ok {
+				rejectRequest(w, r, scopeQuota, bucket, wait)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,130 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := newRateLimiter(nil)
+	rl.now = func() time.Time { return now }
+	limit := conf.RateLimit{Rate: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		ok, _ := rl.allow("key:1", limit)
+		assert.True(t, ok, i)
+	}
+	ok, wait := rl.allow("key:1", limit)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	ok, _ = rl.allow("key:2", limit)
+	assert.True(t, ok)
+
+	now = now.Add(wait)
+	ok, _ = rl.allow("key:1", limit)
+	assert.True(t, ok)
+	ok, _ = rl.allow("key:1", limit)
+	assert.False(t, ok)
+
+	ok, _ = rl.allow("key:1", conf.RateLimit{})
+	assert.True(t, ok)
+
+	now = now.Add(sweepPeriod)
+	rl.allow("key:3", limit)
+	assert.Len(t, rl.buckets, 1)
+}
+
+func TestRateLimiterKeyLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	calls := 0
+	rl := newRateLimiter(func(ecosystemID int64) (conf.RateLimit, bool, error) {
+		calls++
+		switch ecosystemID {
+		case 1:
+			return conf.RateLimit{Rate: 10, Burst: 20}, true, nil
+		case 2:
+			return conf.RateLimit{}, false, errors.New("db")
+		}
+		return conf.RateLimit{}, false, nil
+	})
+	rl.now = func() time.Time { return now }
+	conf.Config.RateLimit.Key = conf.RateLimit{Rate: 1, Burst: 5}
+
+	// the ecosystem can't raise the limit of the node
+	assert.Equal(t, conf.RateLimit{Rate: 1, Burst: 5}, rl.keyLimit(1))
+	assert.Equal(t, conf.RateLimit{Rate: 1, Burst: 5}, rl.keyLimit(2))
+	assert.Equal(t, conf.RateLimit{Rate: 1, Burst: 5}, rl.keyLimit(3))
+	rl.keyLimit(1)
+	assert.Equal(t, 3, calls)
+
+	now = now.Add(ecosystemLimitTTL + time.Second)
+	conf.Config.RateLimit.Key = conf.RateLimit{Rate: 100, Burst: 10}
+	assert.Equal(t, conf.RateLimit{Rate: 10, Burst: 10}, rl.keyLimit(1))
+	assert.Equal(t, 4, calls)
+
+	conf.Config.RateLimit.Key = conf.RateLimit{}
+	assert.Equal(t, conf.RateLimit{Rate: 10, Burst: 20}, rl.keyLimit(1))
+}
+
+func TestRateLimiterQuota(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := newRateLimiter(nil)
+	rl.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := rl.quota("quota:key:1", 3, time.Hour)
+		assert.True(t, ok, i)
+	}
+	now = now.Add(time.Minute)
+	ok, wait := rl.quota("quota:key:1", 3, time.Hour)
+	assert.False(t, ok)
+	assert.Equal(t, 59*time.Minute, wait)
+
+	ok, _ = rl.quota("quota:key:2", 3, time.Hour)
+	assert.True(t, ok)
+	ok, _ = rl.quota("quota:key:1", 0, time.Hour)
+	assert.True(t, ok)
+
+	now = now.Add(wait)
+	ok, _ = rl.quota("quota:key:1", 3, time.Hour)
+	assert.True(t, ok)
+
+	now = now.Add(sweepPeriod + time.Hour)
+	rl.quota("quota:key:3", 3, time.Hour)
+	assert.Len(t, rl.quotas, 1)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := parseRateLimit("0.5, 10")
+	require.NoError(t, err)
+	assert.Equal(t, conf.RateLimit{Rate: 0.5, Burst: 10}, limit)
+
+	for _, v := range []string{"", "1", "1,2,3", "a,1", "1,b", "-1,1", "1,-1", "0,10", "1,0"} {
+		_, err = parseRateLimit(v)
+		assert.Error(t, err, v)
+	}
+}
+
+func TestRouteGroup(t *testing.T) {
+	for tpl, group := range map[string]string{
+		"/api/v2/listWhere/{name}": "listWhere",
+		"/api/v2/ws":               "ws",
+		"/metrics":                 "metrics",
+		"/api/v2/":                 "",
+	} {
+		assert.Equal(t, group, routeGroup(tpl), tpl)
+	}
+}
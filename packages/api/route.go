@@ -37,13 +37,16 @@ func (r Router) NewVersion(preffix string) *mux.Router {
 func (m Mode) SetCommonRoutes(r Router) {
 	api := r.NewVersion("/api/v2")
 
-	api.Use(nodeStateMiddleware, tokenMiddleware, m.clientMiddleware)
+	api.Use(nodeStateMiddleware, ipRateLimitMiddleware, tokenMiddleware, m.clientMiddleware, rateLimitMiddleware)
 
 	SetOtherCommonRoutes(api, m)
 	api.HandleFunc("/data/{prefix}_binaries/{id}/data/{hash}", getBinaryHandler).Methods("GET")
 	api.HandleFunc("/data/{table}/{id}/{column}/{hash}", getDataHandler).Methods("GET")
 	api.HandleFunc("/avatar/{ecosystem}/{account}", getAvatarHandler).Methods("GET")
 	api.HandleFunc("/auth/status", getAuthStatus).Methods("GET")
+	api.HandleFunc("/apikey", authRequire(m.createAPIKeyHandler)).Methods("POST")
+	api.HandleFunc("/apikeys", authRequire(getAPIKeysHandler)).Methods("GET")
+	api.HandleFunc("/apikey/revoke/{id}", authRequire(revokeAPIKeyHandler)).Methods("POST")
 	api.HandleFunc("/row/{name}/{column}/{id}", authRequire(getRowHandler)).Methods("GET")
 	api.HandleFunc("/interface/page/{name}", authRequire(getPageRowHandler)).Methods("GET")
 	api.HandleFunc("/interface/menu/{name}", authRequire(getMenuRowHandler)).Methods("GET")
@@ -71,6 +74,7 @@ func (m Mode) SetCommonRoutes(r Router) {
 	api.HandleFunc("/metrics/keys", keysCountHandler).Methods("GET")
 	api.HandleFunc("/metrics/mem", memStatHandler).Methods("GET")
 	api.HandleFunc("/metrics/ban", banStatHandler).Methods("GET")
+	api.HandleFunc("/ws", m.wsHandler).Methods("GET")
 
 }
 
@@ -80,6 +84,15 @@ func (m Mode) SetBlockchainRoutes(r Router) {
 	api.HandleFunc("/metrics/honornodes", honorNodesCountHandler).Methods("GET")
 	api.HandleFunc("/txinfo/{hash}", authRequire(getTxInfoHandler)).Methods("GET")
 	api.HandleFunc("/txinfomultiple", authRequire(getTxInfoMultiHandler)).Methods("GET")
+	api.HandleFunc("/txproof/{hash}", authRequire(getTxProofHandler)).Methods("GET")
+	api.HandleFunc("/events", authRequire(getEventsHandler)).Methods("GET")
+	api.HandleFunc("/txfees", getTxFeesHandler).Methods("GET")
+	api.HandleFunc("/finality", getFinalityHandler).Methods("GET")
+	api.HandleFunc("/finality/{id}", getFinalityHandler).Methods("GET")
+	api.HandleFunc("/rowproof/{name}/{id}", authRequire(getRowProofHandler)).Methods("GET")
+	api.HandleFunc("/forks", getForksHandler).Methods("GET")
+	api.HandleFunc("/contract/{name}/estimate", authRequire(estimateContractHandler)).Methods("POST")
+	api.HandleFunc("/call/{contract}/{func}", authRequire(callViewHandler)).Methods("POST")
 	api.HandleFunc("/appparam/{appID}/{name}", authRequire(m.GetAppParamHandler)).Methods("GET")
 	api.HandleFunc("/appparams/{appID}", authRequire(m.getAppParamsHandler)).Methods("GET")
 	api.HandleFunc("/appcontent/{appID}", authRequire(m.getAppContentHandler)).Methods("GET")
@@ -281,7 +294,7 @@ func (m Mode) SetVDESrcRoutes(r Router) {
 func NewRouter(m Mode) Router {
 	r := mux.NewRouter()
 	r.StrictSlash(true)
-	r.Use(loggerMiddleware, recoverMiddleware, statsdMiddleware)
+	r.Use(loggerMiddleware, recoverMiddleware, statsdMiddleware, metricsMiddleware)
 
 	api := Router{
 		main:        r,
@@ -291,6 +304,27 @@ func NewRouter(m Mode) Router {
 	return api
 }
 
+// NewLightRouter returns the router of the light mode, the node doesn't have the database
+// so only the verified headers and the rows confirmed by honor nodes are available
+func NewLightRouter() Router {
+	r := mux.NewRouter()
+	r.StrictSlash(true)
+	r.Use(loggerMiddleware, recoverMiddleware, statsdMiddleware, metricsMiddleware)
+
+	api := Router{
+		main:        r,
+		apiVersions: make(map[string]*mux.Router),
+	}
+	v2 := api.NewVersion("/api/v2")
+	// the rows are requested from all honor nodes, so the addresses are limited
+	v2.Use(ipRateLimitMiddleware, rateLimitMiddleware)
+	v2.HandleFunc("/maxblockid", getLightMaxBlockHandler).Methods("GET")
+	v2.HandleFunc("/header/{id}", getLightHeaderHandler).Methods("GET")
+	v2.HandleFunc("/row/{name}/{id}", getLightRowHandler).Methods("GET")
+	v2.HandleFunc("/balance/{wallet}", getLightBalanceHandler).Methods("GET")
+	return api
+}
+
 func WithCors(h http.Handler) http.Handler {
 	return handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}),
@@ -0,0 +1,102 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// rowProofAttempts is the count of attempts to read the row at the same block
+const rowProofAttempts = 3
+
+func getRowData(table, name, columns string, id, ecosystem int64) ([]byte, error) {
+	q := model.GetDB(nil).Table(table).Limit(1)
+	if converter.FirstEcosystemTables[name] {
+		q = q.Where("id = ? and ecosystem = ?", id, ecosystem)
+	} else {
+		q = q.Where("id = ?", id)
+	}
+	if len(columns) > 0 {
+		q = q.Select(columns)
+	}
+	rows, err := q.Rows()
+	if err != nil {
+		return nil, err
+	}
+	result, err := model.GetResult(rows)
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	return json.Marshal(result[0])
+}
+
+// getRowProofHandler returns the row signed by the node key together with the last block.
+// The light nodes request the proofs from honor nodes and accept the row confirmed by the quorum.
+// The row is read with the access rights of the client in its ecosystem like getRowHandler does
+func getRowProofHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+	params := mux.Vars(r)
+	client := getClient(r)
+
+	name := params["name"]
+	table, columns, err := checkAccess(name, "", client)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	if !model.IsTable(table) {
+		errorResponse(w, errTableNotFound.Errorf(table))
+		return
+	}
+	ecosystem := client.EcosystemID
+	proof := &utils.RowProof{Table: table, ID: converter.StrToInt64(params["id"]), Ecosystem: ecosystem}
+
+	// the row is read again if the new block has been played during reading
+	for i := 0; i < rowProofAttempts && proof.BlockID == 0; i++ {
+		before := &model.InfoBlock{}
+		if _, err := before.Get(); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+			errorResponse(w, err)
+			return
+		}
+		data, err := getRowData(table, name, columns, proof.ID, ecosystem)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": table}).Error("getting row")
+			errorResponse(w, errQuery)
+			return
+		}
+		after := &model.InfoBlock{}
+		if _, err = after.Get(); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+			errorResponse(w, err)
+			return
+		}
+		if after.BlockID == before.BlockID && bytes.Equal(after.Hash, before.Hash) {
+			proof.BlockID, proof.Hash, proof.Data = after.BlockID, after.Hash, data
+		}
+	}
+	if proof.BlockID == 0 {
+		errorResponse(w, errServer)
+		return
+	}
+	if err := utils.SignRowProof(proof, syspar.GetNodePubKey(), syspar.GetNodePrivKey()); err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing row proof")
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, proof)
+}
@@ -0,0 +1,54 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// txFeesResult contains the percentiles of expedite fees per byte of unused transactions
+type txFeesResult struct {
+	Count       int               `json:"count"`
+	Min         string            `json:"min"`
+	Max         string            `json:"max"`
+	Percentiles map[string]string `json:"percentiles"`
+}
+
+func getTxFeesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+
+	fees, err := model.GetUnusedFees()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting fees of unused transactions")
+		errorResponse(w, errServer)
+		return
+	}
+
+	result := &txFeesResult{
+		Count:       len(fees),
+		Min:         "0",
+		Max:         "0",
+		Percentiles: make(map[string]string),
+	}
+	if len(fees) > 0 {
+		result.Min = fees[0].String()
+		result.Max = fees[len(fees)-1].String()
+	}
+	for _, p := range model.FeePercentiles {
+		result.Percentiles[strconv.Itoa(p)] = "0"
+	}
+	for p, fee := range model.GetFeePercentiles(fees, model.FeePercentiles) {
+		result.Percentiles[strconv.Itoa(p)] = fee.String()
+	}
+
+	jsonResponse(w, result)
+}
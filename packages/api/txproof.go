@@ -0,0 +1,166 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// TxProofHeader is the block header which is required to check the proof offline
+type TxProofHeader struct {
+	BlockID           int64  `json:"block_id"`
+	Time              int64  `json:"time"`
+	EcosystemID       int64  `json:"ecosystem_id"`
+	KeyID             int64  `json:"key_id"`
+	NodePosition      int64  `json:"node_position"`
+	Version           int    `json:"version"`
+	Sign              []byte `json:"sign"`
+	Hash              []byte `json:"hash"`
+	PrevHash          []byte `json:"prev_hash"`
+	PrevRollbacksHash []byte `json:"prev_rollbacks_hash"`
+	PrevStateRoot     []byte `json:"prev_state_root,omitempty"`
+}
+
+// TxProofResult is the response of txproof
+type TxProofResult struct {
+	TxHash string             `json:"tx_hash"`
+	Header TxProofHeader      `json:"header"`
+	Proof  *utils.MerkleProof `json:"proof"`
+}
+
+// BlockData returns the headers of the block and the previous block
+func (h TxProofHeader) BlockData() (header, prev *utils.BlockData) {
+	header = &utils.BlockData{
+		BlockID:      h.BlockID,
+		Time:         h.Time,
+		EcosystemID:  h.EcosystemID,
+		KeyID:        h.KeyID,
+		NodePosition: h.NodePosition,
+		Version:      h.Version,
+		Sign:         h.Sign,
+		Hash:         h.Hash,
+	}
+	prev = &utils.BlockData{
+		BlockID:       h.BlockID - 1,
+		Hash:          h.PrevHash,
+		RollbacksHash: h.PrevRollbacksHash,
+		StateRoot:     h.PrevStateRoot,
+	}
+	return
+}
+
+func getTxProof(r *http.Request, txHash string) (*TxProofResult, error) {
+	logger := getLogger(r)
+
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding tx hash from hex")
+		return nil, errHashWrong
+	}
+	ltx := &model.LogTransaction{}
+	found, err := ltx.GetByHash(hash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting log transaction by hash")
+		return nil, err
+	}
+	if !found {
+		return nil, errHashNotFound
+	}
+
+	bm := &model.Block{}
+	found, err = bm.Get(ltx.Block)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": ltx.Block}).Error("getting block")
+		return nil, err
+	}
+	if !found {
+		return nil, errNotFoundRecord
+	}
+	if err = checkPrunedBlock(bm.ID); err != nil {
+		return nil, err
+	}
+	prev := &model.Block{}
+	if ltx.Block > 1 {
+		if found, err = prev.Get(ltx.Block - 1); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": ltx.Block - 1}).Error("getting previous block")
+			return nil, err
+		}
+		// the proof can't be verified without the hashes of the previous block
+		if !found {
+			logger.WithFields(log.Fields{"type": consts.NotFound, "block_id": ltx.Block - 1}).Error("previous block is not found")
+			return nil, errNotFoundRecord
+		}
+	}
+
+	blck, err := block.UnmarshallBlock(bytes.NewBuffer(bm.Data), false)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err, "block_id": bm.ID}).Error("on unmarshalling block")
+		return nil, err
+	}
+
+	var (
+		mrklArray [][]byte
+		index     = -1
+	)
+	for _, tx := range blck.Transactions {
+		if len(tx.TxFullData) == 0 {
+			continue
+		}
+		if bytes.Equal(tx.TxHash, hash) {
+			index = len(mrklArray)
+		}
+		mrklArray = append(mrklArray, converter.BinToHex(crypto.DoubleHash(tx.TxFullData)))
+	}
+	if index < 0 {
+		return nil, errHashNotFound
+	}
+	proof, err := utils.MerkleTreeProof(mrklArray, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxProofResult{
+		TxHash: txHash,
+		Header: TxProofHeader{
+			BlockID:           blck.Header.BlockID,
+			Time:              blck.Header.Time,
+			EcosystemID:       blck.Header.EcosystemID,
+			KeyID:             blck.Header.KeyID,
+			NodePosition:      blck.Header.NodePosition,
+			Version:           blck.Header.Version,
+			Sign:              blck.Header.Sign,
+			Hash:              bm.Hash,
+			PrevHash:          prev.Hash,
+			PrevRollbacksHash: blck.PrevRollbacksHash,
+			PrevStateRoot:     blck.PrevStateRoot,
+		},
+		Proof: proof,
+	}, nil
+}
+
+func getTxProofHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	result, err := getTxProof(r, params["hash"])
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, result)
+}
@@ -0,0 +1,88 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/notificator"
+	"github.com/IBAX-io/go-ibax/packages/publisher"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// wsHandler serves the subscriptions of the built-in WebSocket server. Browsers cannot set
+// the authorization header of WebSocket so the token can be passed in the query too
+func (m Mode) wsHandler(w http.ResponseWriter, r *http.Request) {
+	client := getClient(r)
+	if token := r.URL.Query().Get("token"); client.KeyID == 0 && len(token) > 0 {
+		jwtToken, err := parseJWTToken(jwtPrefix + token)
+		if err != nil || !jwtToken.Valid {
+			getLogger(r).WithFields(log.Fields{"type": consts.JWTError, "error": err}).Warning("parsing websocket token")
+			errorResponse(w, errUnauthorized)
+			return
+		}
+		if c, err := getClientFromToken(jwtToken, m.EcosysNameGetter); err != nil {
+			errorResponse(w, err)
+			return
+		} else if c != nil {
+			client = c
+		}
+	}
+	if err := checkScope(r, client); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	publisher.WebSocketHub.ServeWebSocket(w, r, func(channel string) error {
+		return canSubscribe(client, channel)
+	})
+}
+
+// canSubscribe checks the access of the client to the channel. The blocks and the reorgs
+// are public, the other channels require the authorized client. The table channel requires
+// the read access to the table of the ecosystem of the client like the list of rows.
+// The events of the ecosystem are available to the api key which isn't limited by contracts
+func canSubscribe(client *Client, channel string) error {
+	switch channel {
+	case notificator.BlocksChannel, notificator.ReorgsChannel:
+		return nil
+	}
+	if client == nil || client.KeyID == 0 {
+		return errUnauthorized
+	}
+	switch {
+	case strings.HasPrefix(channel, notificator.TxChannelPrefix):
+		return nil
+	case strings.HasPrefix(channel, notificator.EventsChannelPrefix):
+		if channel == notificator.EventsChannel(client.EcosystemID) {
+			if client.Scope != nil && client.Scope.ecosystemContracts(client.EcosystemID) != nil {
+				return errScope.Errorf("events")
+			}
+			return nil
+		}
+	case strings.HasPrefix(channel, notificator.RoleChannelPrefix):
+		if channel == notificator.RoleChannel(client.EcosystemID, client.RoleID) {
+			return nil
+		}
+	case strings.HasPrefix(channel, notificator.TableChannelPrefix):
+		table := strings.TrimPrefix(channel, notificator.TableChannelPrefix)
+		if under := strings.IndexByte(table, '_'); under > 0 &&
+			converter.StrToInt64(table[:under]) == client.EcosystemID {
+			_, _, err := checkAccess(table[under+1:], "", client)
+			return err
+		}
+	case strings.HasPrefix(channel, notificator.AccountChannelPrefix):
+		if channel == notificator.AccountChannelPrefix+client.AccountID {
+			return nil
+		}
+	default:
+		return errNotFound
+	}
+	return errPermission
+}
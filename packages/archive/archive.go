@@ -0,0 +1,128 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+// Package archive writes and reads the portable archives of blocks. The archive starts with
+// the magic bytes and the version which are followed by the records of blocks. Every record
+// is the length of the block, the checksum of the block and the raw block as it is marshalled
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+// Version is the version of the archive format
+const Version = 1
+
+// MaxBlockSize is the max size of the block in the archive
+const MaxBlockSize = 64 << 20
+
+var magic = []byte("IBAXBLKS")
+
+var (
+	ErrFormat    = errors.New("File is not the archive of blocks")
+	ErrVersion   = errors.New("Unsupported archive version")
+	ErrBlockSize = errors.New("Wrong size of block in archive")
+	ErrChecksum  = errors.New("Checksum of block in archive doesn't match")
+)
+
+// Writer writes the blocks to the archive
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter writes the header of the archive and returns the writer of blocks
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(magic); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, uint16(Version)); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw}, nil
+}
+
+// Write appends the record of the block
+func (aw *Writer) Write(data []byte) error {
+	if len(data) == 0 || len(data) > MaxBlockSize {
+		return ErrBlockSize
+	}
+	checksum, err := crypto.CalcChecksum(data)
+	if err != nil {
+		return err
+	}
+	if err = binary.Write(aw.w, binary.LittleEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	if err = binary.Write(aw.w, binary.LittleEndian, checksum); err != nil {
+		return err
+	}
+	_, err = aw.w.Write(data)
+	return err
+}
+
+// Flush writes the buffered records to the underlying writer
+func (aw *Writer) Flush() error {
+	return aw.w.Flush()
+}
+
+// Reader reads the blocks from the archive
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader checks the header of the archive and returns the reader of blocks
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, ErrFormat
+	}
+	if !bytes.Equal(head, magic) {
+		return nil, ErrFormat
+	}
+	var version uint16
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return nil, ErrFormat
+	}
+	if version != Version {
+		return nil, ErrVersion
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next block of the archive after checking its checksum.
+// io.EOF is returned after the last block
+func (ar *Reader) Next() ([]byte, error) {
+	var size uint32
+	if err := binary.Read(ar.r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+	if size == 0 || size > MaxBlockSize {
+		return nil, ErrBlockSize
+	}
+	var checksum uint64
+	if err := binary.Read(ar.r, binary.LittleEndian, &checksum); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(ar.r, data); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	sum, err := crypto.CalcChecksum(data)
+	if err != nil {
+		return nil, err
+	}
+	if sum != checksum {
+		return nil, ErrChecksum
+	}
+	return data, nil
+}
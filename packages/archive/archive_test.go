@@ -0,0 +1,64 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package archive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	blocks := [][]byte{[]byte("first block"), bytes.Repeat([]byte{0xab}, 10000), {0x01}}
+
+	buf := &bytes.Buffer{}
+	aw, err := NewWriter(buf)
+	require.NoError(t, err)
+	for _, b := range blocks {
+		require.NoError(t, aw.Write(b))
+	}
+	require.NoError(t, aw.Flush())
+	assert.Equal(t, ErrBlockSize, aw.Write(nil))
+	data := buf.Bytes()
+
+	ar, err := NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	for _, b := range blocks {
+		next, err := ar.Next()
+		require.NoError(t, err)
+		assert.Equal(t, b, next)
+	}
+	_, err = ar.Next()
+	assert.Equal(t, io.EOF, err)
+
+	// the damaged block
+	damaged := append([]byte{}, data...)
+	damaged[len(damaged)-2] ^= 0xff
+	ar, err = NewReader(bytes.NewReader(damaged))
+	require.NoError(t, err)
+	_, err = ar.Next()
+	require.NoError(t, err)
+	_, err = ar.Next()
+	require.NoError(t, err)
+	_, err = ar.Next()
+	assert.Equal(t, ErrChecksum, err)
+
+	// the truncated archive
+	ar, err = NewReader(bytes.NewReader(data[:len(data)-1]))
+	require.NoError(t, err)
+	ar.Next()
+	ar.Next()
+	_, err = ar.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = NewReader(bytes.NewReader([]byte("IBAXSNAP")))
+	assert.Equal(t, ErrFormat, err)
+	_, err = NewReader(bytes.NewReader(append(append([]byte{}, magic...), 2, 0)))
+	assert.Equal(t, ErrVersion, err)
+}
@@ -0,0 +1,131 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// exportBatch is the count of blocks which are read by one query
+const exportBatch = 100
+
+var (
+	ErrPruned = errors.New("Blocks of the range are pruned")
+	ErrGap    = errors.New("Archive doesn't continue the blockchain")
+)
+
+// Export writes the blocks [from, to] to the archive, to <= 0 means the last block.
+// It returns the count of written blocks
+func Export(w io.Writer, from, to int64) (int64, error) {
+	logger := log.WithFields(log.Fields{"type": consts.DBError})
+	if from < 1 {
+		from = 1
+	}
+	prunedID, err := model.GetPrunedBlockID()
+	if err != nil {
+		logger.WithError(err).Error("getting pruned block")
+		return 0, err
+	}
+	if from <= prunedID {
+		return 0, ErrPruned
+	}
+	aw, err := NewWriter(w)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for id := from - 1; to <= 0 || id < to; {
+		end := id + exportBatch
+		if to > 0 && end > to {
+			end = to
+		}
+		blocks, err := model.GetBlockchain(id, end, model.OrderASC)
+		if err != nil {
+			logger.WithFields(log.Fields{"error": err, "from": id + 1, "to": end}).Error("getting blocks")
+			return count, err
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		for _, b := range blocks {
+			if err = aw.Write(b.Data); err != nil {
+				log.WithFields(log.Fields{"type": consts.IOError, "error": err, "block_id": b.ID}).Error("writing block")
+				return count, err
+			}
+			count++
+		}
+		id = blocks[len(blocks)-1].ID
+	}
+	return count, aw.Flush()
+}
+
+// Import checks and plays the blocks of the archive after the last block of the blockchain.
+// The blocks which are already in the blockchain are skipped. It returns the count of
+// played blocks
+func Import(r io.Reader, logger *log.Entry) (int64, error) {
+	ar, err := NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	infoBlock := &model.InfoBlock{}
+	if _, err = infoBlock.Get(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return 0, err
+	}
+	lastID := infoBlock.BlockID
+	var count int64
+	for {
+		data, err := ar.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.ParseError, "error": err, "block_id": lastID + 1}).Error("reading block")
+			return count, err
+		}
+		header, _, err := utils.ParseBlockHeader(bytes.NewBuffer(data))
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.ParseError, "error": err, "block_id": lastID + 1}).Error("parsing block header")
+			return count, err
+		}
+		if header.BlockID <= lastID {
+			continue
+		}
+		if header.BlockID != lastID+1 {
+			logger.WithFields(log.Fields{"type": consts.BlockError, "block_id": header.BlockID, "last_block_id": lastID}).Error("archive doesn't continue the blockchain")
+			return count, ErrGap
+		}
+		if err = block.InsertBlockWOForks(data, false, header.BlockID == 1); err != nil {
+			logger.WithFields(log.Fields{"type": consts.BlockError, "error": err, "block_id": header.BlockID}).Error("inserting block")
+			return count, err
+		}
+		if header.BlockID == 1 {
+			if err = syspar.SysUpdate(nil); err != nil {
+				return count, err
+			}
+			if data, ok := block.GetDataFromFirstBlock(); ok {
+				syspar.SetFirstBlockData(data)
+			}
+			// the next blocks call the contracts of the first block
+			if err = smart.LoadContracts(); err != nil {
+				return count, err
+			}
+		}
+		lastID = header.BlockID
+		count++
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/metrics"
 	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/protocols"
 	"github.com/IBAX-io/go-ibax/packages/script"
@@ -29,11 +30,14 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
 
 var (
 	ErrIncorrectRollbackHash = errors.New("Rollback hash doesn't match")
+	ErrStateDivergence       = errors.New("State root of the previous block doesn't match, the state of the node has diverged")
+	ErrBlockVersion          = utils.WithBan(errors.New("Block version doesn't match the activated features"))
 	ErrEmptyBlock            = errors.New("Block doesn't contain transactions")
 	ErrIncorrectBlockTime    = utils.WithBan(errors.New("Incorrect block time"))
 )
@@ -43,12 +47,15 @@ type Block struct {
 	Header            utils.BlockData
 	PrevHeader        *utils.BlockData
 	PrevRollbacksHash []byte
+	PrevStateRoot     []byte
 	MrklRoot          []byte
 	BinData           []byte
 	Transactions      []*transaction.Transaction
 	SysUpdate         bool
 	GenBlock          bool // it equals true when we are generating a new block
 	Notifications     []types.Notifications
+	Events            []*model.ContractEvent
+	changes           map[string][]string
 }
 
 func (b Block) String() string {
@@ -127,10 +134,23 @@ func (b *Block) PlaySafe() error {
 		return err
 	}
 
+	b.SendNotifications()
+	return nil
+}
+
+// SendNotifications publishes the notifications, the events, the header, the statuses
+// of transactions and the modified tables of the committed block
+func (b *Block) SendNotifications() {
 	for _, q := range b.Notifications {
 		q.Send()
 	}
-	return nil
+	notificator.SendEvents(b.Events)
+	notificator.SendBlock(b.Header.BlockID, b.Header.Time, b.Header.KeyID, b.Header.NodePosition,
+		b.Header.Hash, len(b.Transactions))
+	for _, t := range b.Transactions {
+		notificator.SendTxStatus(t.TxHash, b.Header.BlockID, "")
+	}
+	notificator.SendTableChanges(b.Header.BlockID, b.changes)
 }
 
 func (b *Block) repeatMarshallBlock() error {
@@ -173,6 +193,8 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) (batchErr error) {
 	var (
 		playTxs model.AfterTxs
 	)
+	timer := prometheus.NewTimer(metrics.BlockPlayDuration)
+	defer timer.ObserveDuration()
 	logger := b.GetLogger()
 	limits := NewLimits(b)
 	rand := utils.NewRand(b.Header.Time)
@@ -215,6 +237,7 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) (batchErr error) {
 		}
 		if err != nil {
 			if flush != nil {
+				smart.GetVM().Lock()
 				for i := len(flush) - 1; i >= 0; i-- {
 					finfo := flush[i]
 					if finfo.Prev == nil {
@@ -230,6 +253,7 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) (batchErr error) {
 						smart.GetVM().Objects[finfo.Name] = finfo.Info
 					}
 				}
+				smart.GetVM().Unlock()
 			}
 			if err == custom.ErrNetworkStopping {
 				return err
@@ -283,12 +307,22 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) (batchErr error) {
 		if t.Notifications.Size() > 0 {
 			b.Notifications = append(b.Notifications, t.Notifications)
 		}
+		b.Events = append(b.Events, t.Events...)
 		playTxs.UsedTx = append(playTxs.UsedTx, t.TxHash)
 		playTxs.Lts = append(playTxs.Lts, &model.LogTransaction{Block: b.Header.BlockID, Hash: t.TxHash})
 		playTxs.Rts = append(playTxs.Rts, t.RollBackTx...)
 		proccessedTx = append(proccessedTx, t)
 	}
 
+	b.changes = tableChanges(modifiedRows(playTxs.Rts))
+	if b.Header.Version >= consts.BvStateRoot {
+		stateRoot, err := StateRoot(dbTransaction, playTxs.Rts)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("calculating state root")
+			return err
+		}
+		b.Header.StateRoot = stateRoot
+	}
 	return nil
 }
 
@@ -304,6 +338,16 @@ func (b *Block) Check() error {
 			return err
 		}
 	}
+	// the state root is included only from the activation block
+	if (b.Header.Version >= consts.BvStateRoot) != (syspar.GetBlockVersion(b.Header.BlockID) >= consts.BvStateRoot) {
+		logger.WithFields(log.Fields{"type": consts.BlockError, "state_root_block": syspar.GetStateRootBlock()}).Error("incorrect block version")
+		return ErrBlockVersion
+	}
+	if b.Header.Version >= consts.BvStateRoot && !bytes.Equal(b.PrevStateRoot, b.PrevHeader.StateRoot) {
+		logger.WithFields(log.Fields{"type": consts.BlockError, "state_root": fmt.Sprintf("%x", b.PrevHeader.StateRoot),
+			"block_state_root": fmt.Sprintf("%x", b.PrevStateRoot)}).Error("state root of the previous block doesn't match")
+		return ErrStateDivergence
+	}
 	if b.Header.Time > time.Now().Unix() {
 		logger.WithFields(log.Fields{"type": consts.ParameterExceeded}).Error("block time is larger than now")
 		return ErrIncorrectBlockTime
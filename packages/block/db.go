@@ -105,6 +105,7 @@ func InsertIntoBlockchain(transaction *model.DbTransaction, block *Block) error
 		Time:          block.Header.Time,
 		RollbacksHash: rollbacksHash,
 		Tx:            int32(len(block.Transactions)),
+		StateRoot:     block.Header.StateRoot,
 	}
 	validBlockTime := true
 	if blockID > 1 {
@@ -152,6 +153,7 @@ func GetBlockDataFromBlockChain(blockID int64) (*utils.BlockData, error) {
 	BlockData = &header
 	BlockData.Hash = block.Hash
 	BlockData.RollbacksHash = block.RollbacksHash
+	BlockData.StateRoot = block.StateRoot
 	return BlockData, nil
 }
 
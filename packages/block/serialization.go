@@ -56,6 +56,9 @@ func MarshallBlock(header *utils.BlockData, trData [][]byte, prev *utils.BlockDa
 	buf.Write(converter.EncodeLenInt64InPlace(header.KeyID))
 	buf.Write(converter.DecToBin(header.NodePosition, 1))
 	buf.Write(converter.EncodeLengthPlusData(prev.RollbacksHash))
+	if header.Version >= consts.BvStateRoot {
+		buf.Write(converter.EncodeLengthPlusData(prev.StateRoot))
+	}
 
 	// fill signature
 	buf.Write(converter.EncodeLengthPlusData(signed))
@@ -118,6 +121,7 @@ func UnmarshallBlock(blockBuffer *bytes.Buffer, fillData bool) (*Block, error) {
 	return &Block{
 		Header:            header,
 		PrevRollbacksHash: prev.RollbacksHash,
+		PrevStateRoot:     prev.StateRoot,
 		Transactions:      transactions,
 		MrklRoot:          mrkl,
 	}, nil
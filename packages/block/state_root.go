@@ -0,0 +1,111 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package block
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+)
+
+// stateRow is the row of the table which has been modified by the block
+type stateRow struct {
+	table     string
+	id        string
+	ecosystem string
+}
+
+// modifiedRows returns the sorted rows which have the rollback records of the block.
+// The tables of the first ecosystem contain the rows of all ecosystems so their rows
+// are identified by the ecosystem too
+func modifiedRows(rts []*model.RollbackTx) []stateRow {
+	rows := make([]stateRow, 0, len(rts))
+	exists := make(map[stateRow]bool, len(rts))
+	for _, rt := range rts {
+		if rt.NameTable == smart.SysName {
+			continue
+		}
+		row := stateRow{table: rt.NameTable, id: rt.TableID}
+		if under := strings.IndexByte(rt.NameTable, '_'); under > 0 &&
+			converter.FirstEcosystemTables[rt.NameTable[under+1:]] {
+			if comma := strings.IndexByte(rt.TableID, ','); comma >= 0 {
+				row.id, row.ecosystem = rt.TableID[:comma], rt.TableID[comma+1:]
+			} else if len(rt.Data) > 0 {
+				var data map[string]string
+				if err := json.Unmarshal([]byte(rt.Data), &data); err == nil {
+					row.ecosystem = data["ecosystem"]
+				}
+			}
+		}
+		if !exists[row] {
+			exists[row] = true
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].table != rows[j].table {
+			return rows[i].table < rows[j].table
+		}
+		if rows[i].ecosystem != rows[j].ecosystem {
+			return rows[i].ecosystem < rows[j].ecosystem
+		}
+		return rows[i].id < rows[j].id
+	})
+	return rows
+}
+
+// tableChanges returns the ids of the modified rows grouped by the table. The rows of the tables
+// of the first ecosystem are grouped by the name of the table in their ecosystem
+func tableChanges(rows []stateRow) map[string][]string {
+	changes := make(map[string][]string)
+	for _, row := range rows {
+		table := row.table
+		if len(row.ecosystem) > 0 {
+			table = row.ecosystem + table[strings.IndexByte(table, '_'):]
+		}
+		changes[table] = append(changes[table], row.id)
+	}
+	return changes
+}
+
+// hashRows returns the hash of the rows and their values
+func hashRows(rows []stateRow, values []string) []byte {
+	var buf []byte
+	for i, row := range rows {
+		for _, v := range []string{row.table, row.ecosystem, row.id, values[i]} {
+			buf = append(buf, converter.EncodeLengthPlusData([]byte(v))...)
+		}
+	}
+	return crypto.Hash(buf)
+}
+
+// StateRoot returns the hash of the current values of all rows which have been modified by
+// the block. The deleted rows and the rows of the dropped tables have the empty value
+func StateRoot(dbTransaction *model.DbTransaction, rts []*model.RollbackTx) ([]byte, error) {
+	rows := modifiedRows(rts)
+	values := make([]string, len(rows))
+	tables := make(map[string]bool)
+	for i, row := range rows {
+		exists, ok := tables[row.table]
+		if !ok {
+			exists = model.Namer{TableType: "table"}.HasExists(dbTransaction, row.table)
+			tables[row.table] = exists
+		}
+		if !exists {
+			continue
+		}
+		var err error
+		if values[i], err = model.GetRowState(dbTransaction, row.table, row.id, row.ecosystem); err != nil {
+			return nil, err
+		}
+	}
+	return hashRows(rows, values), nil
+}
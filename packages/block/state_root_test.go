@@ -0,0 +1,62 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package block
+
+import (
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModifiedRows(t *testing.T) {
+	rts := []*model.RollbackTx{
+		{NameTable: "2_goods", TableID: "5", Data: `{"amount":"1"}`},
+		{NameTable: "1_keys", TableID: "100,2"},
+		{NameTable: "@system", TableID: "1", Data: `{"type":"NewTable"}`},
+		{NameTable: "1_keys", TableID: "100", Data: `{"amount":"10","ecosystem":"1"}`},
+		{NameTable: "2_goods", TableID: "5", Data: `{"amount":"2"}`},
+		{NameTable: "1_keys", TableID: "100", Data: `{"amount":"20","ecosystem":"2"}`},
+		{NameTable: "2_goods", TableID: "11"},
+	}
+	assert.Equal(t, []stateRow{
+		{table: "1_keys", id: "100", ecosystem: "1"},
+		{table: "1_keys", id: "100", ecosystem: "2"},
+		{table: "2_goods", id: "11"},
+		{table: "2_goods", id: "5"},
+	}, modifiedRows(rts))
+
+	// the order of rollback records doesn't change the rows
+	reversed := make([]*model.RollbackTx, len(rts))
+	for i, rt := range rts {
+		reversed[len(rts)-1-i] = rt
+	}
+	assert.Equal(t, modifiedRows(rts), modifiedRows(reversed))
+}
+
+func TestTableChanges(t *testing.T) {
+	rows := []stateRow{
+		{table: "1_keys", id: "100", ecosystem: "1"},
+		{table: "1_keys", id: "100", ecosystem: "2"},
+		{table: "1_keys", id: "200", ecosystem: "2"},
+		{table: "2_goods", id: "5"},
+	}
+	assert.Equal(t, map[string][]string{
+		"1_keys":  {"100"},
+		"2_keys":  {"100", "200"},
+		"2_goods": {"5"},
+	}, tableChanges(rows))
+}
+
+func TestHashRows(t *testing.T) {
+	rows := []stateRow{{table: "1_keys", id: "1", ecosystem: "1"}, {table: "1_keys", id: "2", ecosystem: "1"}}
+	root := hashRows(rows, []string{`{"amount": "1"}`, ""})
+	assert.Equal(t, root, hashRows(rows, []string{`{"amount": "1"}`, ""}))
+	assert.NotEqual(t, root, hashRows(rows, []string{`{"amount": "2"}`, ""}))
+	assert.NotEqual(t, root, hashRows(rows, []string{"", `{"amount": "1"}`}))
+	assert.NotEqual(t, root, hashRows(rows[:1], []string{`{"amount": "1"}`}))
+}
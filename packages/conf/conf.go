@@ -111,20 +111,57 @@ type PoolPubConfig struct {
 	Path        string
 }
 
+// LightConfig is the config of the light mode
+type LightConfig struct {
+	Path       string // Path is the path of leveldb with verified headers
+	HonorNodes string // HonorNodes is the trusted json list of honor nodes in the format of the honor_nodes parameter
+}
+
+// PeersConfig is the config of the discovery of peers
+type PeersConfig struct {
+	Path    string // Path is the path of leveldb with the table of peers, it isn't used if leveldb is opened by the pool
+	Address string // Address is the public tcp address of the node which is announced to peers, empty if it isn't reachable
+	Max     int    // Max is the max count of peers in the table
+}
+
+// RateLimit is the token bucket which is refilled with Rate tokens per second up to Burst tokens.
+// Every request takes one token, the zero rate is unlimited
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig is the config of the rate limiting of API requests
+type RateLimitConfig struct {
+	IP          RateLimit            // IP limits the requests from one address
+	Key         RateLimit            // Key limits the requests of one key, the ecosystem can lower it with the api_rate_limit parameter
+	Routes      map[string]RateLimit // Routes limits the requests of one key or address to the group of routes, e.g. listWhere
+	KeyQuota    int64                // KeyQuota is the max count of requests of one key per QuotaPeriod, 0 is unlimited
+	QuotaPeriod int                  // QuotaPeriod is the period of the quota in minutes
+}
+
 // GlobalConfig is storing all startup config as global struct
 type GlobalConfig struct {
 	KeyID        int64  `toml:"-"`
 	TLS                   bool   // TLS is on/off. It is required for https
 	TLSCert               string // TLSCert is a filepath of the fullchain of certificate.
 	TLSKey                string // TLSKey is a filepath of the private key.
+	NodeTLS               bool   // NodeTLS is on/off. It encrypts tcp traffic between honor nodes authenticated by node keys
 	OBSMode               string
 	HTTPServerMaxBodySize int64
+	MaxTxPoolSize         int   // MaxTxPoolSize is the maximum count of unused transactions, the cheapest ones are evicted
+	SnapshotInterval      int64 // SnapshotInterval is the count of blocks between snapshots of honor nodes, it must be the same on all honor nodes, 0 is off
+	FastSync              bool  // FastSync is on/off. New node imports the snapshot instead of playing all blocks
+	PruneBlocks           int64 // PruneBlocks is the count of the last blocks whose bodies are kept, 0 is off
+	PruneToSnapshot       bool  // PruneToSnapshot keeps the blocks after the last finalized snapshot instead of PruneBlocks
 	NetworkID             int64
+	WebSocketOrigins      []string // WebSocketOrigins are the origins of web pages which can connect to the WebSocket server, * allows all
 
 	MaxPageGenerationTime int64 // in milliseconds
 
 	TCPServer HostPort
 	HTTP      HostPort
+	Metrics   HostPort // Metrics is the address of the Prometheus metrics endpoint which is separate from API, 0 port is off
 
 	DB             DBConfig
 	Redis          RedisConfig
@@ -135,6 +172,9 @@ type GlobalConfig struct {
 	BanKey         BanKeyConfig
 	GFiles         GFilesConfig
 	PoolPub        PoolPubConfig
+	Light          LightConfig
+	Peers          PeersConfig
+	RateLimit      RateLimitConfig
 	NodesAddr      []string
 	CryptoSettings CryptoSettings
 }
@@ -326,6 +366,11 @@ func (c GlobalConfig) IsSubNode() bool {
 	return RunMode(c.OBSMode).IsSubNode()
 }
 
+// IsLight check running mode
+func (c GlobalConfig) IsLight() bool {
+	return RunMode(c.OBSMode).IsLight()
+}
+
 func registerCrypto(c CryptoSettings) {
 	crypto.InitCurve(c.Cryptoer)
 	crypto.InitHash(c.Hasher)
@@ -20,6 +20,14 @@ const node RunMode = "NONE"
 //Add sub node processing
 const subNode RunMode = "SubNode"
 
+// light const label for running mode, the node syncs and verifies only block headers
+const light RunMode = "Light"
+
+// IsLight returns true if mode equal light
+func (rm RunMode) IsLight() bool {
+	return rm == light
+}
+
 // IsOBSMaster returns true if mode equal obsMaster
 func (rm RunMode) IsOBSMaster() bool {
 	return rm == obsMaster
@@ -63,6 +63,8 @@ const (
 	Test = `test`
 	// PrivateBlockchain is value defining blockchain mode
 	PrivateBlockchain = `private_blockchain`
+	// StateRootBlock is the id of the block from which the blocks include the state root
+	StateRootBlock = `state_root_block`
 
 	// CostDefault is the default maximum cost of F
 	CostDefault = int64(20000000)
@@ -159,6 +161,19 @@ func SysUpdate(dbTransaction *model.DbTransaction) error {
 	return err
 }
 
+// SetParams sets values of system parameters without database, it is used by the light mode
+func SetParams(params map[string]string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for name, value := range params {
+		cache[name] = value
+	}
+	if _, ok := params[HonorNodes]; ok {
+		return updateNodes()
+	}
+	return nil
+}
+
 func updateNodes() (err error) {
 	items := make([]*HonorNode, 0)
 	if len(cache[HonorNodes]) > 0 {
@@ -432,6 +447,20 @@ func GetIncorrectBlocksPerDay() int {
 	return converter.StrToInt(SysString(IncorrectBlocksPerDay))
 }
 
+// GetStateRootBlock returns the id of the block from which the blocks include the state root
+// or 0 if the state root isn't activated
+func GetStateRootBlock() int64 {
+	return converter.StrToInt64(SysString(StateRootBlock))
+}
+
+// GetBlockVersion returns the version of the block by its id
+func GetBlockVersion(blockID int64) int {
+	if activation := GetStateRootBlock(); activation > 0 && blockID >= activation {
+		return consts.BvStateRoot
+	}
+	return consts.BlockVersion
+}
+
 func GetNodeBanTime() time.Duration {
 	return time.Millisecond * time.Duration(converter.StrToInt64(SysString(NodeBanTime)))
 }
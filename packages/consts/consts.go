@@ -17,6 +17,10 @@ const VERSION = "1.3.0"
 const BvRollbackHash = 2
 const BvIncludeRollbackHash = 3
 
+// BvStateRoot is the version of block which includes the state root of the previous block.
+// The blocks have this version from the block of the state_root_block system parameter
+const BvStateRoot = 4
+
 // BlockVersion is block version
 const BlockVersion = BvIncludeRollbackHash
 
@@ -87,6 +91,9 @@ const MaxTXAttempt = 10
 // ChainSize 1M = 1048576 byte
 const ChainSize = 1 << 20
 
+// SnapshotChunkSize is the size of the part of the snapshot which is sent by one request
+const SnapshotChunkSize = 1 << 20
+
 const (
 	TxTypeFirstBlock     = 1
 	TxTypeApiContract    = 2
@@ -201,6 +208,7 @@ const (
 const (
 	SavePointMarkBlock = "block"
 	SavePointMarkTx    = "tx"
+	SavePointMarkTry   = "try"
 )
 
 func Version() string {
@@ -210,3 +218,7 @@ func Version() string {
 func SetSavePointMarkBlock(idTx int) string {
 	return fmt.Sprintf("\"%s-%d\";", SavePointMarkBlock, idTx)
 }
+
+func SetSavePointMarkTry(point int) string {
+	return fmt.Sprintf("\"%s-%d\";", SavePointMarkTry, point)
+}
@@ -150,6 +150,16 @@ func generateNextBlock(blockHeader *utils.BlockData, trs []*model.Transaction, k
 	for _, tr := range trs {
 		trData = append(trData, tr.Data)
 	}
+	blockHeader.Version = syspar.GetBlockVersion(blockHeader.BlockID)
+	// the state root of the previous block is saved only in the blockchain table
+	if blockHeader.Version >= consts.BvStateRoot && prevBlock.StateRoot == nil {
+		prev := &model.Block{}
+		if _, err := prev.Get(prevBlock.BlockID); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": prevBlock.BlockID}).Error("getting previous block")
+			return nil, err
+		}
+		prevBlock.StateRoot = prev.StateRoot
+	}
 
 	return block.MarshallBlock(blockHeader, trData, prevBlock, key)
 }
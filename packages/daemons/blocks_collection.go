@@ -16,7 +16,6 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/smart"
 
 	"github.com/IBAX-io/go-ibax/packages/block"
-	"github.com/IBAX-io/go-ibax/packages/network"
 	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
 
 	"github.com/IBAX-io/go-ibax/packages/conf"
@@ -83,6 +82,13 @@ func blocksCollection(ctx context.Context, d *daemon) (err error) {
 		DBUnlock()
 	}()
 
+	// the new node imports the state from the snapshot and plays only the following blocks
+	if conf.Config.FastSync && infoBlock.BlockID == 1 {
+		if err := syncFromSnapshot(ctx, d, host); err != nil {
+			d.logger.WithFields(log.Fields{"error": err, "host": host}).Warn("syncing from snapshot, all blocks will be played")
+		}
+	}
+
 	// update our chain till maxBlockID from the host
 	return UpdateChain(ctx, d, host, maxBlockID)
 }
@@ -101,7 +107,7 @@ func UpdateChain(ctx context.Context, d *daemon, host string, maxBlockID int64)
 		return ctx.Err()
 	}
 
-	playRawBlock := func(rb []byte) error {
+	playRawBlock := func(host string, rb []byte) error {
 		var lastBlockID, lastBlockTime int64
 		var err error
 		defer func(err2 *error) {
@@ -166,54 +172,28 @@ func UpdateChain(ctx context.Context, d *daemon, host string, maxBlockID int64)
 	//}
 
 	d.logger.WithFields(log.Fields{"min_block": curBlock.BlockID, "max_block": maxBlockID, "count": maxBlockID - curBlock.BlockID}).Info("starting downloading blocks")
-	for blockID := curBlock.BlockID + 1; blockID <= maxBlockID; blockID += int64(network.BlocksPerRequest) {
 
-		if loopErr := func() error {
-			ctxDone, cancel := context.WithCancel(ctx)
-			defer func() {
-				cancel()
-				d.logger.WithFields(log.Fields{"count": count, "time": time.Since(st).String()}).Info("blocks downloaded")
-			}()
+	ctxDone, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		d.logger.WithFields(log.Fields{"count": count, "time": time.Since(st).String()}).Info("blocks downloaded")
+	}()
 
-			rawBlocksChan, err := tcpclient.GetBlocksBodies(ctxDone, host, blockID, false)
-			if err != nil {
-				d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("getting block body")
+	// the ranges of blocks are downloaded from several hosts and played in the order of block ids
+	for r := range downloadBlocks(ctxDone, getDownloadHosts(host), curBlock.BlockID+1, maxBlockID, d.logger) {
+		if r.err != nil {
+			d.logger.WithFields(log.Fields{"error": r.err, "type": consts.BlockError, "from": r.from}).Error("getting block body")
+			return r.err
+		}
+		for _, rawBlock := range r.blocks {
+			if err := playRawBlock(r.host, rawBlock); err != nil {
+				d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("playing raw block")
 				return err
 			}
-
-			for rawBlock := range rawBlocksChan {
-
-				//if conf.Config.PoolPub.Enable {
-				//	bi := model.BlockID{}
-				//	f, err := bi.GetRangeByName(consts.MintMax, consts.ChainMax, 2000)
-				//	if err != nil {
-				//		return err
-				//	}
-				//	if !f {
-				//		if err = playRawBlock(rawBlock); err != nil {
-				//			d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("playing raw block")
-				//			return err
-				//		}
-				//	} else {
-				//		time.Sleep(4 * time.Second)
-				//		return errors.New("deal mint blockid please wait")
-				//	}
-				//} else {
-
-				if err = playRawBlock(rawBlock); err != nil {
-					d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("playing raw block")
-					return err
-				}
-				count++
-				//}
-			}
-
-			return nil
-		}(); loopErr != nil {
-			return loopErr
+			count++
 		}
 	}
-	return nil
+	return ctx.Err()
 }
 
 func banNodePause(host string, blockID, blockTime int64, err error) {
@@ -234,7 +214,7 @@ func getHostWithMaxID(ctx context.Context, logger *log.Entry) (host string, maxB
 		logger.WithFields(log.Fields{"error": err}).Error("on filtering banned hosts")
 	}
 
-	host, maxBlockID, err = tcpclient.HostWithMaxBlock(ctx, hosts)
+	host, maxBlockID, err = tcpclient.HostWithMaxBlock(ctx, peerHosts(hosts))
 	if len(hosts) == 0 || err == tcpclient.ErrNodesUnavailable {
 		hosts = conf.GetNodesAddr()
 		return tcpclient.HostWithMaxBlock(ctx, hosts)
@@ -251,6 +231,9 @@ func ReplaceBlocksFromHost(ctx context.Context, host string, blockID, replaceCou
 	if err != nil {
 		return err
 	}
+	if err = chooseFork(host, blocks, log.WithFields(log.Fields{"host": host})); err != nil {
+		return err
+	}
 	transaction.CleanCache()
 
 	// mark all transaction as unverified
@@ -268,6 +251,11 @@ func ReplaceBlocksFromHost(ctx context.Context, host string, blockID, replaceCou
 		blockID = blocks[len(blocks)-1].Header.BlockID
 	}
 
+	// finalized blocks cannot be replaced
+	if err = rollback.CheckFinalized(blockID); err != nil {
+		return err
+	}
+
 	// we have the slice of blocks for applying
 	// first of all we should rollback old blocks
 	b := &model.Block{}
@@ -290,6 +278,7 @@ func ReplaceBlocksFromHost(ctx context.Context, host string, blockID, replaceCou
 		return err
 	}
 	smart.ReleaseSmartVMObjects()
+	registerReorg(host, myRollbackBlocks, blocks, log.WithFields(log.Fields{"host": host}))
 	return err
 }
 
@@ -367,6 +356,7 @@ func processBlocks(blocks []*block.Block) error {
 		if prevBlocks[b.Header.BlockID-1] != nil {
 			b.PrevHeader.Hash = prevBlocks[b.Header.BlockID-1].Header.Hash
 			b.PrevHeader.RollbacksHash = prevBlocks[b.Header.BlockID-1].Header.RollbacksHash
+			b.PrevHeader.StateRoot = prevBlocks[b.Header.BlockID-1].Header.StateRoot
 			b.PrevHeader.Time = prevBlocks[b.Header.BlockID-1].Header.Time
 			b.PrevHeader.BlockID = prevBlocks[b.Header.BlockID-1].Header.BlockID
 			b.PrevHeader.EcosystemID = prevBlocks[b.Header.BlockID-1].Header.EcosystemID
@@ -413,5 +403,11 @@ func processBlocks(blocks []*block.Block) error {
 		}
 	}
 
-	return dbTransaction.Commit()
+	if err := dbTransaction.Commit(); err != nil {
+		return err
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		blocks[i].SendNotifications()
+	}
+	return nil
 }
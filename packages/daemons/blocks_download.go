@@ -0,0 +1,202 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+	"github.com/IBAX-io/go-ibax/packages/service"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// downloadRangeTimeout is the time for downloading one range, the range of slow host is reassigned
+	downloadRangeTimeout = 30 * time.Second
+	// maxRangeAttempts is the count of attempts to download the range from different hosts
+	maxRangeAttempts = 3
+	// maxHostFailures is the count of failures after which the host isn't used for downloading
+	maxHostFailures = 2
+	// rangesPerHost limits the count of ranges which are downloaded ahead of playing
+	rangesPerHost = 2
+)
+
+var (
+	errNoDownloadHosts = errors.New("No hosts for downloading blocks")
+	errBlockRange      = errors.New("Block ids of the range do not match")
+	errIncompleteRange = errors.New("Host has sent incomplete range of blocks")
+)
+
+// fetchRange downloads the range of blocks from the host
+var fetchRange = downloadRange
+
+// blocksRange is the range of blocks [from, to] which is downloaded from one host
+type blocksRange struct {
+	from, to int64
+}
+
+// rangeResult is the downloaded range of blocks
+type rangeResult struct {
+	blocksRange
+	host   string
+	blocks [][]byte
+	err    error
+}
+
+// getDownloadHosts returns the host with the max block and other not banned honor nodes
+func getDownloadHosts(host string) []string {
+	hosts := []string{host}
+	remote, err := service.GetNodesBanService().FilterBannedHosts(syspar.GetRemoteHosts())
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("on filtering banned hosts")
+		return hosts
+	}
+	for _, h := range remote {
+		if h != host {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// downloadBlocks downloads the disjoint ranges of blocks from several hosts concurrently
+// and returns them in the order of block ids. The range of the failed or slow host is
+// reassigned to another host. The channel is closed after the last range or the first
+// range which cannot be downloaded, such range is returned with the error
+func downloadBlocks(ctx context.Context, hosts []string, from, to int64, logger *log.Entry) <-chan *rangeResult {
+	out := make(chan *rangeResult)
+	go func() {
+		defer close(out)
+
+		var queue []blocksRange
+		for id := from; id <= to; id += int64(network.BlocksPerRequest) {
+			last := id + int64(network.BlocksPerRequest) - 1
+			if last > to {
+				last = to
+			}
+			queue = append(queue, blocksRange{from: id, to: last})
+		}
+		var (
+			free     = append([]string{}, hosts...)
+			results  = make(chan *rangeResult, len(hosts))
+			pending  = make(map[int64]*rangeResult)
+			attempts = make(map[int64]int)
+			failures = make(map[string]int)
+			window   = len(hosts) * rangesPerHost
+			active   int
+			next     = from
+		)
+		send := func(r *rangeResult) bool {
+			select {
+			case out <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for next <= to {
+			// the range of the next block is assigned even if the window is full
+			for len(free) > 0 && len(queue) > 0 && (active+len(pending) < window || queue[0].from == next) {
+				host, r := free[0], queue[0]
+				free, queue = free[1:], queue[1:]
+				active++
+				go func() {
+					results <- fetchRange(ctx, host, r, logger)
+				}()
+			}
+			if active == 0 {
+				send(&rangeResult{blocksRange: blocksRange{from: next, to: to}, err: errNoDownloadHosts})
+				return
+			}
+
+			var res *rangeResult
+			select {
+			case <-ctx.Done():
+				return
+			case res = <-results:
+				active--
+			}
+			if res.err != nil {
+				logger.WithFields(log.Fields{"error": res.err, "host": res.host, "from": res.from, "to": res.to}).Warn("downloading blocks, the range is reassigned")
+				if attempts[res.from]++; attempts[res.from] >= maxRangeAttempts {
+					send(res)
+					return
+				}
+				queue = append([]blocksRange{res.blocksRange}, queue...)
+				if failures[res.host]++; failures[res.host] < maxHostFailures {
+					free = append(free, res.host)
+				}
+				continue
+			}
+			free = append(free, res.host)
+			pending[res.from] = res
+			for r, ok := pending[next]; ok; r, ok = pending[next] {
+				delete(pending, next)
+				if !send(r) {
+					return
+				}
+				next = r.to + 1
+			}
+		}
+	}()
+	return out
+}
+
+// downloadRange downloads the range of blocks from the host and checks the headers of blocks
+func downloadRange(ctx context.Context, host string, r blocksRange, logger *log.Entry) *rangeResult {
+	res := &rangeResult{blocksRange: r, host: host}
+	ctx, cancel := context.WithTimeout(ctx, downloadRangeTimeout)
+	defer cancel()
+
+	rawBlocksChan, err := tcpclient.GetBlocksBodies(ctx, host, r.from, false)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	for blockID := r.from; blockID <= r.to; blockID++ {
+		var (
+			rawBlock []byte
+			ok       bool
+		)
+		select {
+		case <-ctx.Done():
+			res.err = ctx.Err()
+			return res
+		case rawBlock, ok = <-rawBlocksChan:
+		}
+		if !ok {
+			res.err = errIncompleteRange
+			return res
+		}
+		// the body is in the buffer of the connection which is released after the download
+		data := make([]byte, len(rawBlock))
+		copy(data, rawBlock)
+
+		header, _, err := utils.ParseBlockHeader(bytes.NewBuffer(data))
+		if err != nil {
+			res.err = err
+			return res
+		}
+		if header.BlockID != blockID {
+			logger.WithFields(log.Fields{"header_block_id": header.BlockID, "block_id": blockID, "host": host}).Error("block ids do not match")
+			res.err = errBlockRange
+			return res
+		}
+		if _, err = syspar.GetNodePublicKeyByPosition(header.NodePosition); err != nil {
+			res.err = err
+			return res
+		}
+		res.blocks = append(res.blocks, data)
+	}
+	return res
+}
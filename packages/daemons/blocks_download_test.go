@@ -0,0 +1,61 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadBlocks(t *testing.T) {
+	defer func(f func(context.Context, string, blocksRange, *log.Entry) *rangeResult) {
+		fetchRange = f
+	}(fetchRange)
+
+	fetchRange = func(ctx context.Context, host string, r blocksRange, logger *log.Entry) *rangeResult {
+		res := &rangeResult{blocksRange: r, host: host}
+		switch host {
+		case "bad":
+			res.err = errors.New("connection refused")
+			return res
+		case "slow":
+			time.Sleep(10 * time.Millisecond)
+		}
+		for id := r.from; id <= r.to; id++ {
+			res.blocks = append(res.blocks, []byte{byte(id)})
+		}
+		return res
+	}
+
+	var (
+		next  = int64(2)
+		last  = int64(3*network.BlocksPerRequest + 10)
+		hosts = make(map[string]bool)
+	)
+	for r := range downloadBlocks(context.Background(), []string{"bad", "slow", "fast"}, next, last, log.WithFields(log.Fields{})) {
+		assert.NoError(t, r.err)
+		assert.Equal(t, next, r.from)
+		assert.Len(t, r.blocks, int(r.to-r.from+1))
+		next = r.to + 1
+		hosts[r.host] = true
+	}
+	assert.Equal(t, last+1, next)
+	assert.False(t, hosts["bad"])
+
+	var errs int
+	for r := range downloadBlocks(context.Background(), []string{"bad"}, 1, 10, log.WithFields(log.Fields{})) {
+		assert.Error(t, r.err)
+		errs++
+	}
+	assert.Equal(t, 1, errs)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/metrics"
 	"github.com/IBAX-io/go-ibax/packages/statsd"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
@@ -42,6 +43,9 @@ var daemonsList = map[string]func(context.Context, *daemon) error{
 	"QueueParserTx":     QueueParserTx,
 	"QueueParserBlocks": QueueParserBlocks,
 	"Confirmations":     Confirmations,
+	"Snapshots":         Snapshots,
+	"Pruning":           Pruning,
+	"PeerDiscovery":     PeerDiscovery,
 	"Scheduler":         Scheduler,
 	"ExternalNetwork":   ExternalNetwork,
 
@@ -119,8 +123,12 @@ func daemonLoop(ctx context.Context, goRoutineName string, handler func(context.
 			MonitorDaemonCh <- []string{d.goRoutineName, converter.Int64ToStr(time.Now().Unix())}
 			startTime := time.Now()
 			counterName := statsd.DaemonCounterName(goRoutineName)
-			handler(ctx, d)
-			statsd.Client.TimingDuration(counterName+statsd.Time, time.Now().Sub(startTime), 1.0)
+			if err := handler(ctx, d); err != nil {
+				metrics.DaemonErrors.WithLabelValues(goRoutineName).Inc()
+			}
+			duration := time.Now().Sub(startTime)
+			statsd.Client.TimingDuration(counterName+statsd.Time, duration, 1.0)
+			metrics.DaemonDuration.WithLabelValues(goRoutineName).Observe(duration.Seconds())
 		}
 	}
 }
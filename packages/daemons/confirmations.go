@@ -68,7 +68,10 @@ func Confirmations(ctx context.Context, d *daemon) error {
 		startBlockID = lastBlockID
 	}
 
-	return confirmationsBlocks(ctx, d, lastBlockID, startBlockID)
+	if err = confirmationsBlocks(ctx, d, lastBlockID, startBlockID); err != nil {
+		return err
+	}
+	return finalizeBlocks(ctx, d, lastBlockID)
 }
 
 func confirmationsBlocks(ctx context.Context, d *daemon, lastBlockID, startBlockID int64) error {
@@ -21,7 +21,7 @@ import (
 
 // Disseminator is send to all nodes from nodes_connections the following data
 // if we are honor node: sends blocks and transactions hashes
-// else send the full transactions
+// else announce the transactions hashes and send the transactions which are requested
 func Disseminator(ctx context.Context, d *daemon) error {
 	if atomic.CompareAndSwapUint32(&d.atomic, 0, 1) {
 		defer atomic.StoreUint32(&d.atomic, 0)
@@ -62,9 +62,9 @@ func sendTransactions(ctx context.Context, logger *log.Entry) error {
 		return nil
 	}
 
-	hosts := syspar.GetDefaultRemoteHosts()
+	hosts := peerHosts(syspar.GetDefaultRemoteHosts())
 
-	if err := tcpclient.SendTransacitionsToAll(ctx, hosts, *trs); err != nil {
+	if err := tcpclient.AnnounceTransactions(ctx, hosts, *trs); err != nil {
 		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("on sending transactions")
 		return err
 	}
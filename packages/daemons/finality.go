@@ -0,0 +1,135 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// finalityDepth is the count of the last blocks which are checked for the finality
+const finalityDepth = 10
+
+func isHonorNodeKey(key []byte) bool {
+	_, err := syspar.GetNodePositionByPublicKey(key)
+	return err == nil
+}
+
+// finalizeBlocks collects the signed votes of honor nodes for the last blocks and saves
+// the finality certificate of the highest block which has got more than 2/3 of votes
+func finalizeBlocks(ctx context.Context, d *daemon, lastBlockID int64) error {
+	finalID, err := model.GetFinalizedBlockID(nil)
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting finalized block")
+		return err
+	}
+	nodes := syspar.GetNumberOfNodes()
+	if nodes == 0 {
+		return nil
+	}
+	for blockID := lastBlockID; blockID > finalID && blockID > lastBlockID-finalityDepth; blockID-- {
+		if err := ctx.Err(); err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.ContextError, "error": err}).Error("error in context")
+			return err
+		}
+		block := &model.Block{}
+		found, err := block.Get(blockID)
+		if err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("getting block by ID")
+			return err
+		}
+		if !found {
+			continue
+		}
+		votes := collectVotes(block, syspar.GetRemoteHosts(), true, d.logger)
+		if utils.VerifyVotes(block.ID, block.Hash, votes, nodes, isHonorNodeKey) != nil {
+			continue
+		}
+		data, err := json.Marshal(votes)
+		if err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling votes")
+			return err
+		}
+		fc := &model.FinalityCertificate{
+			BlockID: block.ID,
+			Hash:    block.Hash,
+			Votes:   data,
+			Time:    time.Now().Unix(),
+		}
+		if err = fc.Create(nil); err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("saving finality certificate")
+			return err
+		}
+		d.logger.WithFields(log.Fields{"block_id": blockID, "votes": len(votes)}).Info("block is finalized")
+		break
+	}
+	return nil
+}
+
+// collectVotes returns the valid votes of honor nodes for the block. The vote of this node is
+// included if self is true
+func collectVotes(block *model.Block, hosts []string, self bool, logger *log.Entry) []*utils.Vote {
+	votes := make([]*utils.Vote, 0, len(hosts)+1)
+	keys := make(map[string]bool)
+	add := func(vote *utils.Vote) {
+		key := hex.EncodeToString(vote.NodeKey)
+		if keys[key] || !bytes.Equal(vote.Hash, block.Hash) || !isHonorNodeKey(vote.NodeKey) || vote.Verify() != nil {
+			return
+		}
+		keys[key] = true
+		votes = append(votes, vote)
+	}
+
+	nodeKey := syspar.GetNodePubKey()
+	if self && isHonorNodeKey(nodeKey) {
+		vote, err := utils.SignVote(model.NodeVotes{}, block.ID, block.Hash, nodeKey, syspar.GetNodePrivKey())
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "block_id": block.ID}).Error("signing vote")
+		} else {
+			add(vote)
+		}
+	}
+
+	ch := make(chan *utils.Vote, len(hosts))
+	for _, item := range hosts {
+		host, err := tcpclient.NormalizeHostAddress(item, consts.DEFAULT_TCP_PORT)
+		if err != nil {
+			logger.WithFields(log.Fields{"host": item, "type": consts.ParseError, "error": err}).Error("wrong host address")
+			ch <- nil
+			continue
+		}
+		go func(host string) {
+			vote, err := tcpclient.GetVote(host, block.ID, logger)
+			if err != nil {
+				vote = nil
+			}
+			ch <- vote
+		}(host)
+	}
+	timeout := time.After(consts.WAIT_CONFIRMED_NODES * time.Second)
+	for i := 0; i < len(hosts); i++ {
+		select {
+		case vote := <-ch:
+			if vote != nil {
+				add(vote)
+			}
+		case <-timeout:
+			return votes
+		}
+	}
+	return votes
+}
@@ -0,0 +1,250 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+	"github.com/IBAX-io/go-ibax/packages/notificator"
+	"github.com/IBAX-io/go-ibax/packages/service"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Fork choice rule. When the blocks of the host don't match the blocks of the node,
+// the branches are compared by the first different block:
+//  1. the finalized blocks are never replaced;
+//  2. the branch whose first block is confirmed by more honor nodes wins. Every honor node is counted once,
+//     the node itself confirms its own branch only if it is honor node;
+//  3. if the confirmations are equal, the branch whose first block has the lower hash wins.
+// The losing branch is kept in fork_blocks until it is older than rollback_blocks and the host
+// of the losing branch is marked as failed, so the node doesn't download the same branch again
+
+var errForkRejected = errors.New("Branch of the host loses to the branch of the node")
+
+// branch is the first block of the branch with the count of honor nodes which have it
+type branch struct {
+	hash          []byte
+	confirmations int64
+}
+
+// preferBranch reports whether the branch b is better than the branch a
+func preferBranch(a, b branch) bool {
+	if a.confirmations != b.confirmations {
+		return b.confirmations > a.confirmations
+	}
+	return bytes.Compare(b.hash, a.hash) < 0
+}
+
+// newBranches returns the branches of the node and the host by the counts of remote honor nodes.
+// self is true if the node is honor node, then it is added to the confirmations of its own branch
+func newBranches(counts map[string]int64, ours, hash []byte, self bool) (ourBranch, hostBranch branch) {
+	ourBranch = branch{hash: ours, confirmations: counts[string(converter.BinToHex(ours))]}
+	hostBranch = branch{hash: hash, confirmations: counts[string(converter.BinToHex(hash))]}
+	if self {
+		ourBranch.confirmations++
+	}
+	return
+}
+
+// rejectHost marks the host of the rejected branch as failed. The honor node is banned locally,
+// the peer is registered as failed in the table of peers
+func rejectHost(host string, blockID, blockTime int64, reason error, logger *log.Entry) {
+	if node, err := syspar.GetNodeByHost(host); err == nil {
+		if nbs := service.GetNodesBanService(); nbs != nil {
+			if err = nbs.RegisterBadBlock(node, blockID, blockTime, reason.Error(), false); err != nil {
+				logger.WithFields(log.Fields{"error": err}).Error("banning node")
+			}
+		}
+		return
+	}
+	if ps := service.GetPeersService(); ps != nil {
+		ps.Failure(host)
+	}
+}
+
+// countConfirmations returns the count of honor nodes which have every hash of the block
+func countConfirmations(blockID int64, logger *log.Entry) map[string]int64 {
+	counts := make(map[string]int64)
+	hosts, err := service.GetNodesBanService().FilterBannedHosts(syspar.GetRemoteHosts())
+	if err != nil {
+		logger.WithFields(log.Fields{"error": err}).Error("on filtering banned hosts")
+		return counts
+	}
+	ch := make(chan string)
+	var count int
+	for _, h := range hosts {
+		host, err := tcpclient.NormalizeHostAddress(h, consts.DEFAULT_TCP_PORT)
+		if err != nil {
+			logger.WithFields(log.Fields{"host": h, "type": consts.ParseError, "error": err}).Error("wrong host address")
+			continue
+		}
+		count++
+		go IsReachable(host, blockID, ch, logger)
+	}
+	for i := 0; i < count; i++ {
+		counts[<-ch]++
+	}
+	return counts
+}
+
+// chooseFork applies the fork choice rule to the blocks of the host which are ordered from
+// the last block to the first one. The first block follows the common block of both branches.
+// If the branch of the node wins, the branch of the host is saved as the fork and errForkRejected is returned
+func chooseFork(host string, blocks []*block.Block, logger *log.Entry) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	first := blocks[len(blocks)-1]
+	hash := crypto.DoubleHash([]byte(first.Header.ForSha(first.PrevHeader, first.MrklRoot)))
+	ours := &model.Block{}
+	found, err := ours.Get(first.Header.BlockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
+		return err
+	}
+	if !found || bytes.Equal(ours.Hash, hash) {
+		return nil
+	}
+
+	counts := countConfirmations(first.Header.BlockID, logger)
+	ourBranch, hostBranch := newBranches(counts, ours.Hash, hash, isHonorNodeKey(syspar.GetNodePubKey()))
+	logger.WithFields(log.Fields{"block_id": first.Header.BlockID, "host": host, "confirmations": ourBranch.confirmations,
+		"host_confirmations": hostBranch.confirmations}).Info("choosing fork")
+	if preferBranch(ourBranch, hostBranch) {
+		return nil
+	}
+
+	// the hashes of the following blocks are known only after the blocks are played,
+	// so the rejected branch is kept by its first block
+	fb := &model.ForkBlock{
+		Hash:         hash,
+		BlockID:      first.Header.BlockID,
+		ForkID:       first.Header.BlockID,
+		NodePosition: first.Header.NodePosition,
+		Time:         first.Header.Time,
+		Host:         host,
+		Data:         first.BinData,
+	}
+	if err = fb.Save(nil); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving fork block")
+		return err
+	}
+	rejectHost(host, first.Header.BlockID, first.Header.Time, errForkRejected, logger)
+	return errForkRejected
+}
+
+// recordQueueFork saves the block of the queue as the fork if the node has another block with the same id
+func recordQueueFork(qb *model.QueueBlock, logger *log.Entry) {
+	ours := &model.Block{}
+	found, err := ours.Get(qb.BlockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
+		return
+	}
+	if !found || bytes.Equal(ours.Hash, qb.Hash) {
+		return
+	}
+	host, _ := syspar.GetNodeHostByPosition(qb.HonorNodeID)
+	fb := &model.ForkBlock{Hash: qb.Hash, BlockID: qb.BlockID, ForkID: qb.BlockID, NodePosition: qb.HonorNodeID,
+		Time: time.Now().Unix(), Host: host, Data: []byte{}}
+	if err = fb.Save(nil); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving fork block")
+	}
+}
+
+// txHashes returns the hashes of transactions of the blocks
+func txHashes(data [][]byte) (map[string]bool, error) {
+	hashes := make(map[string]bool)
+	for _, d := range data {
+		b, err := block.UnmarshallBlock(bytes.NewBuffer(d), false)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range b.Transactions {
+			hashes[hex.EncodeToString(tx.TxHash)] = true
+		}
+	}
+	return hashes, nil
+}
+
+// registerReorg saves the replaced blocks of the node as the fork, saves the reorg with the
+// transactions which are not in the new branch and sends the reorg event
+func registerReorg(host string, replaced []model.Block, blocks []*block.Block, logger *log.Entry) {
+	if len(replaced) == 0 || len(blocks) == 0 {
+		return
+	}
+	first := replaced[len(replaced)-1]
+	var (
+		oldData = make([][]byte, 0, len(replaced))
+		newData = make([][]byte, 0, len(blocks))
+	)
+	for _, b := range replaced {
+		oldData = append(oldData, b.Data)
+		fb := &model.ForkBlock{Hash: b.Hash, BlockID: b.ID, ForkID: first.ID, NodePosition: b.NodePosition, Time: b.Time, Data: b.Data}
+		if err := fb.Save(nil); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving fork block")
+		}
+	}
+	for _, b := range blocks {
+		newData = append(newData, b.BinData)
+		if err := model.DeleteForkBlock(nil, b.Header.Hash); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting fork block")
+		}
+	}
+	if err := model.DeleteForkBlocksBefore(nil, first.ID-syspar.GetRbBlocks1()); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting old fork blocks")
+	}
+
+	oldTxs, err := txHashes(oldData)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("unmarshalling replaced blocks")
+		return
+	}
+	newTxs, err := txHashes(newData)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("unmarshalling new blocks")
+		return
+	}
+	txs := make([]string, 0)
+	for hash := range oldTxs {
+		if !newTxs[hash] {
+			txs = append(txs, hash)
+		}
+	}
+	data, err := json.Marshal(txs)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling transactions")
+		return
+	}
+	reorg := &model.Reorg{
+		BlockID: first.ID,
+		Depth:   int64(len(replaced)),
+		OldHash: first.Hash,
+		NewHash: blocks[len(blocks)-1].Header.Hash,
+		Host:    host,
+		Txs:     string(data),
+		Time:    time.Now().Unix(),
+	}
+	if err = reorg.Create(nil); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving reorg")
+		return
+	}
+	logger.WithFields(log.Fields{"type": consts.BlockError, "block_id": reorg.BlockID, "depth": reorg.Depth, "host": host,
+		"txs": len(txs)}).Warn("blocks are replaced with another branch")
+	notificator.SendReorg(reorg)
+}
@@ -0,0 +1,53 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferBranch(t *testing.T) {
+	low, high := []byte{0x01, 0xff}, []byte{0x02, 0x00}
+
+	// more confirmations win regardless of the hash
+	assert.True(t, preferBranch(branch{hash: low, confirmations: 2}, branch{hash: high, confirmations: 3}))
+	assert.False(t, preferBranch(branch{hash: high, confirmations: 3}, branch{hash: low, confirmations: 2}))
+
+	// the lower hash wins if the confirmations are equal
+	assert.True(t, preferBranch(branch{hash: high, confirmations: 2}, branch{hash: low, confirmations: 2}))
+	assert.False(t, preferBranch(branch{hash: low, confirmations: 2}, branch{hash: high, confirmations: 2}))
+
+	// the same branch is not replaced
+	assert.False(t, preferBranch(branch{hash: low, confirmations: 1}, branch{hash: low, confirmations: 1}))
+}
+
+func TestNewBranches(t *testing.T) {
+	ours, hash := []byte{0x02}, []byte{0x01}
+	counts := map[string]int64{
+		string(converter.BinToHex(ours)): 1,
+		string(converter.BinToHex(hash)): 2,
+	}
+
+	// the node which isn't honor node doesn't confirm its branch
+	ourBranch, hostBranch := newBranches(counts, ours, hash, false)
+	assert.Equal(t, int64(1), ourBranch.confirmations)
+	assert.Equal(t, int64(2), hostBranch.confirmations)
+	assert.True(t, preferBranch(ourBranch, hostBranch))
+
+	// honor node is counted once for its own branch, the lower hash wins the tie
+	ourBranch, hostBranch = newBranches(counts, ours, hash, true)
+	assert.Equal(t, int64(2), ourBranch.confirmations)
+	assert.Equal(t, int64(2), hostBranch.confirmations)
+	assert.True(t, preferBranch(ourBranch, hostBranch))
+
+	ourBranch, hostBranch = newBranches(counts, hash, ours, true)
+	assert.Equal(t, int64(3), ourBranch.confirmations)
+	assert.False(t, preferBranch(ourBranch, hostBranch))
+}
@@ -0,0 +1,107 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+	"github.com/IBAX-io/go-ibax/packages/service"
+)
+
+const (
+	// peersExchangeInterval is the period of the exchange of addresses
+	peersExchangeInterval = 30 * time.Second
+	// peersExchangeCount is the count of peers which are asked for addresses at once
+	peersExchangeCount = 3
+	// minPeers is the count of peers below which the honor nodes and the config nodes are asked too
+	minPeers = 10
+	// peerHostsCount is the count of peers which are used instead of honor nodes by non-honor nodes
+	peerHostsCount = 5
+)
+
+// PeerDiscovery exchanges the known addresses with random peers. The answers and the latency
+// of peers are registered in the table of peers which is used by non-honor nodes
+func PeerDiscovery(ctx context.Context, d *daemon) error {
+	d.sleepTime = peersExchangeInterval
+	ps := service.GetPeersService()
+	if ps == nil {
+		return nil
+	}
+	if !atomic.CompareAndSwapUint32(&d.atomic, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreUint32(&d.atomic, 0)
+
+	hosts := ps.Probe(peersExchangeCount)
+	if ps.Len() < minPeers {
+		hosts = append(hosts, conf.GetNodesAddr()...)
+		hosts = append(hosts, syspar.GetRemoteHosts()...)
+	}
+	known := make([]string, 0, network.MaxPeersPerRequest)
+	if len(conf.Config.Peers.Address) > 0 {
+		known = append(known, conf.Config.Peers.Address)
+	}
+	known = append(known, ps.Hosts(int(network.MaxPeersPerRequest)-len(known))...)
+
+	var wg sync.WaitGroup
+	for _, host := range uniqueHosts(hosts) {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			start := time.Now()
+			addresses, err := tcpclient.ExchangePeers(host, known, d.logger)
+			if err != nil {
+				ps.Failure(host)
+				return
+			}
+			latency := time.Since(start)
+			// the peer is used only after it has proved its node key
+			nodeKey, err := tcpclient.PeerNodeKey(host)
+			if err != nil {
+				ps.Failure(host)
+				return
+			}
+			ps.Success(host, latency, nodeKey)
+			ps.Add(host, addresses)
+		}(host)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// peerHosts returns the best verified peers and one random honor node for non-honor nodes,
+// so the replicas don't ask the same honor nodes. The honor nodes are returned if there are few peers
+func peerHosts(hosts []string) []string {
+	ps := service.GetPeersService()
+	if ps == nil || len(hosts) == 0 || isHonorNodeKey(syspar.GetNodePubKey()) {
+		return hosts
+	}
+	peers := ps.Hosts(peerHostsCount)
+	if len(peers) < peersExchangeCount {
+		return hosts
+	}
+	return uniqueHosts(append(peers, hosts[rand.Intn(len(hosts))]))
+}
+
+func uniqueHosts(hosts []string) []string {
+	seen := make(map[string]bool, len(hosts))
+	result := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		if !seen[h] {
+			seen[h] = true
+			result = append(result, h)
+		}
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// pruneInterval is the period of checking the blocks which can be pruned
+	pruneInterval = time.Minute
+	// pruneBatch is the count of blocks which are pruned by one transaction
+	pruneBatch = 100
+)
+
+// pruneBoundary returns the id of the last block which can be pruned. The first block,
+// the blocks which can be rolled back and the blocks after oldBlockID are never pruned.
+// oldBlockID is the last block whose transactions can't be replayed, the log of transactions
+// of the following blocks is needed for checking the duplicate transactions
+func pruneBoundary(lastBlockID, keepBlocks, rollbackBlocks, snapshotBlockID, oldBlockID int64, toSnapshot bool) int64 {
+	boundary := lastBlockID - keepBlocks
+	if toSnapshot {
+		boundary = snapshotBlockID
+	}
+	if limit := lastBlockID - rollbackBlocks; boundary > limit {
+		boundary = limit
+	}
+	if boundary > oldBlockID {
+		boundary = oldBlockID
+	}
+	if boundary < 2 {
+		return 0
+	}
+	return boundary
+}
+
+// Pruning deletes the bodies, the rollback data and the log of transactions of old blocks.
+// The headers of blocks are kept. The blocks are pruned only after their transactions are too old
+// to be included again, so the log of transactions still protects against replays. The hashes
+// of stop network transactions are kept in the log of transactions because the time of such
+// transactions isn't checked
+func Pruning(ctx context.Context, d *daemon) error {
+	d.sleepTime = pruneInterval
+	if conf.Config.PruneBlocks <= 0 && !conf.Config.PruneToSnapshot {
+		return nil
+	}
+	if !atomic.CompareAndSwapUint32(&d.atomic, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreUint32(&d.atomic, 0)
+
+	infoBlock := &model.InfoBlock{}
+	if _, err := infoBlock.Get(); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return err
+	}
+	var snapshotBlockID int64
+	if conf.Config.PruneToSnapshot {
+		s := &model.Snapshot{}
+		found, err := s.GetLast()
+		if err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last snapshot")
+			return err
+		}
+		finalID, err := model.GetFinalizedBlockID(nil)
+		if err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting finalized block")
+			return err
+		}
+		if found && s.BlockID <= finalID {
+			snapshotBlockID = s.BlockID
+		}
+	}
+	// the transaction can get into the block during MAX_TX_BACK seconds after its time
+	// and its time can be ahead of the block time by MAX_TX_FORW seconds
+	oldBlock := &model.Block{}
+	if _, err := oldBlock.GetLastBefore(time.Now().Unix() - consts.MAX_TX_BACK - consts.MAX_TX_FORW); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting old block")
+		return err
+	}
+	boundary := pruneBoundary(infoBlock.BlockID, conf.Config.PruneBlocks, syspar.GetRbBlocks1(),
+		snapshotBlockID, oldBlock.ID, conf.Config.PruneToSnapshot)
+
+	prunedID, err := model.GetPrunedBlockID()
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pruned block")
+		return err
+	}
+	if prunedID < 1 {
+		prunedID = 1
+	}
+	for prunedID < boundary && ctx.Err() == nil {
+		to := prunedID + pruneBatch
+		if to > boundary {
+			to = boundary
+		}
+		if err = pruneBlocks(prunedID, to); err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "from": prunedID + 1, "to": to}).Error("pruning blocks")
+			return err
+		}
+		d.logger.WithFields(log.Fields{"from": prunedID + 1, "to": to}).Debug("blocks are pruned")
+		prunedID = to
+	}
+	return nil
+}
+
+// pruneBlocks prunes the blocks (from, to]
+func pruneBlocks(from, to int64) error {
+	blocks, err := model.GetBlockchain(from, to, model.OrderASC)
+	if err != nil {
+		return err
+	}
+	DBLock()
+	defer DBUnlock()
+
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		if err = pruneBlock(dbTransaction, &b); err != nil {
+			dbTransaction.Rollback()
+			return err
+		}
+	}
+	return dbTransaction.Commit()
+}
+
+func pruneBlock(dbTransaction *model.DbTransaction, b *model.Block) error {
+	buf := bytes.NewBuffer(b.Data)
+	if _, _, err := utils.ParseBlockHeader(buf); err != nil {
+		return err
+	}
+	header := b.Data[:len(b.Data)-buf.Len()]
+	blck, err := block.UnmarshallBlock(bytes.NewBuffer(b.Data), false)
+	if err != nil {
+		return err
+	}
+	var keepHashes [][]byte
+	for _, tx := range blck.Transactions {
+		if tx.TxType == consts.TxTypeStopNetwork {
+			keepHashes = append(keepHashes, tx.TxHash)
+		}
+	}
+	return model.PruneBlock(dbTransaction, b.ID, header, blck.MrklRoot, keepHashes)
+}
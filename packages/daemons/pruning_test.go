@@ -0,0 +1,35 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneBoundary(t *testing.T) {
+	// the last N blocks are kept
+	assert.Equal(t, int64(900), pruneBoundary(1000, 100, 10, 0, 1000, false))
+
+	// the blocks which can be rolled back are kept even if N is less
+	assert.Equal(t, int64(990), pruneBoundary(1000, 5, 10, 0, 1000, false))
+
+	// everything after the snapshot is kept
+	assert.Equal(t, int64(500), pruneBoundary(1000, 100, 10, 500, 1000, true))
+	assert.Equal(t, int64(990), pruneBoundary(1000, 100, 10, 1000, 1000, true))
+
+	// the first block is never pruned
+	assert.Equal(t, int64(0), pruneBoundary(1000, 100, 10, 0, 1000, true))
+	assert.Equal(t, int64(0), pruneBoundary(50, 100, 10, 0, 1000, false))
+	assert.Equal(t, int64(0), pruneBoundary(11, 10, 10, 0, 1000, false))
+	assert.Equal(t, int64(2), pruneBoundary(12, 10, 10, 0, 1000, false))
+
+	// the blocks whose transactions can be replayed are kept
+	assert.Equal(t, int64(700), pruneBoundary(1000, 100, 10, 0, 700, false))
+	assert.Equal(t, int64(500), pruneBoundary(1000, 100, 10, 500, 700, true))
+	assert.Equal(t, int64(0), pruneBoundary(1000, 100, 10, 0, 0, false))
+}
@@ -53,6 +53,7 @@ func QueueParserBlocks(ctx context.Context, d *daemon) error {
 
 	// is it old block in queue ?
 	if queueBlock.BlockID <= infoBlock.BlockID {
+		recordQueueFork(queueBlock, d.logger)
 		queueBlock.DeleteOldBlocks()
 		return utils.ErrInfo(fmt.Errorf("old block %d <= %d", queueBlock.BlockID, infoBlock.BlockID))
 	}
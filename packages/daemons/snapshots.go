@@ -0,0 +1,234 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/snapshot"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Snapshots exports the state of the blockchain at the blocks which are multiples of
+// conf.Config.SnapshotInterval. Only honor nodes make snapshots, the previous snapshot is removed.
+// The honor nodes export the same blocks so they confirm the snapshots of each other
+func Snapshots(ctx context.Context, d *daemon) error {
+	if conf.Config.SnapshotInterval <= 0 || !isHonorNodeKey(syspar.GetNodePubKey()) {
+		return nil
+	}
+	if !atomic.CompareAndSwapUint32(&d.atomic, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreUint32(&d.atomic, 0)
+
+	infoBlock := &model.InfoBlock{}
+	if _, err := infoBlock.Get(); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block")
+		return err
+	}
+	last := &model.Snapshot{}
+	if _, err := last.GetLast(); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last snapshot")
+		return err
+	}
+	if infoBlock.BlockID%conf.Config.SnapshotInterval != 0 || infoBlock.BlockID == last.BlockID {
+		return nil
+	}
+
+	// the transaction reads the state at the same block while new blocks are being played
+	dbTransaction, err := model.StartSnapshotTransaction()
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.Rollback()
+	if err = model.GetDB(dbTransaction).Last(infoBlock).Error; err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting info block of snapshot")
+		return err
+	}
+	if infoBlock.BlockID%conf.Config.SnapshotInterval != 0 {
+		// the next block has been played
+		return nil
+	}
+
+	s, err := snapshot.Create(dbTransaction)
+	if err != nil {
+		return err
+	}
+	d.logger.WithFields(log.Fields{"block_id": s.BlockID, "size": s.Size}).Info("snapshot is created")
+	return snapshot.RemoveBefore(s.BlockID)
+}
+
+// nextBlock returns the hash of the block following the snapshot block and the state root of
+// the snapshot block which is committed by its header. The state root is nil for the blocks
+// before the state root is activated
+func nextBlock(host string, header *snapshot.Header, logger *log.Entry) (*model.Block, []byte, error) {
+	headers, err := tcpclient.GetHeaders(host, header.BlockID+1, 1, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(headers) == 0 {
+		return nil, nil, snapshot.ErrBlockHash
+	}
+	next, prev, err := utils.ParseBlockHeader(bytes.NewBuffer(headers[0].Data))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err, "host": host}).Error("parsing block header")
+		return nil, nil, err
+	}
+	if next.BlockID != header.BlockID+1 {
+		return nil, nil, snapshot.ErrBlockHash
+	}
+	prev.BlockID = header.BlockID
+	prev.Hash = header.Hash
+	block := &model.Block{
+		ID:   next.BlockID,
+		Hash: crypto.DoubleHash([]byte(next.ForSha(&prev, headers[0].MrklRoot))),
+		Time: next.Time,
+	}
+	if next.Version < consts.BvStateRoot {
+		return block, nil, nil
+	}
+	return block, prev.StateRoot, nil
+}
+
+// collectSnapshotSigns requests the signed hash of the snapshot of the block from the hosts.
+// The signatures of the different hash or not by honor nodes are skipped
+func collectSnapshotSigns(blockID int64, hash []byte, hosts []string, logger *log.Entry) []*snapshot.Sign {
+	signs := make([]*snapshot.Sign, 0, len(hosts))
+	ch := make(chan *snapshot.Sign, len(hosts))
+	for _, item := range hosts {
+		host, err := tcpclient.NormalizeHostAddress(item, consts.DEFAULT_TCP_PORT)
+		if err != nil {
+			logger.WithFields(log.Fields{"host": item, "type": consts.ParseError, "error": err}).Error("wrong host address")
+			ch <- nil
+			continue
+		}
+		go func(host string) {
+			resp, err := tcpclient.GetSnapshotChunk(host, blockID, network.SnapshotHashChunk, logger)
+			if err != nil || len(resp.Sign) == 0 {
+				ch <- nil
+				return
+			}
+			ch <- &snapshot.Sign{BlockID: resp.BlockID, Hash: resp.Hash, NodeKey: resp.NodeKey, Sign: resp.Sign}
+		}(host)
+	}
+	timeout := time.After(consts.WAIT_CONFIRMED_NODES * time.Second)
+	for i := 0; i < len(hosts); i++ {
+		select {
+		case s := <-ch:
+			if s != nil && s.BlockID == blockID && bytes.Equal(s.Hash, hash) && isHonorNodeKey(s.NodeKey) {
+				signs = append(signs, s)
+			}
+		case <-timeout:
+			return signs
+		}
+	}
+	return signs
+}
+
+// syncFromSnapshot downloads the last snapshot from the host and imports it if the hash of the
+// snapshot file is signed by the quorum of honor nodes, so every row of the snapshot is confirmed.
+// The next block must be finalized by the votes of honor nodes, its hash commits to the hash of
+// the snapshot block. The honor nodes are taken from the current state of the new node
+// so the snapshot cannot replace them
+func syncFromSnapshot(ctx context.Context, d *daemon, host string) error {
+	f, err := ioutil.TempFile(conf.Config.TempDir, "snapshot")
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("creating snapshot file")
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	blockID, err := tcpclient.DownloadSnapshot(ctx, host, f, d.logger)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, 0); err != nil {
+		return err
+	}
+	header, err := snapshot.ReadHeader(f)
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.ParseError, "error": err, "block_id": blockID}).Error("reading snapshot header")
+		return err
+	}
+	if header.BlockID != blockID {
+		return snapshot.ErrBlockHash
+	}
+	hash, err := snapshot.FileHash(f.Name())
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("getting hash of snapshot")
+		return err
+	}
+	signs := collectSnapshotSigns(blockID, hash, syspar.GetRemoteHosts(), d.logger)
+	if err = snapshot.VerifySigns(blockID, hash, signs, syspar.GetNumberOfNodes(), isHonorNodeKey); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.BlockError, "error": err, "block_id": blockID}).Warn("snapshot is not confirmed by honor nodes")
+		return err
+	}
+
+	block, stateRoot, err := nextBlock(host, header, d.logger)
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.BlockError, "error": err, "block_id": blockID}).Warn("getting next block of snapshot")
+		return err
+	}
+	// the node hasn't played the next block so it doesn't vote for it
+	votes := collectVotes(block, syspar.GetRemoteHosts(), false, d.logger)
+	if err = utils.VerifyVotes(block.ID, block.Hash, votes, syspar.GetNumberOfNodes(), isHonorNodeKey); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.BlockError, "error": err, "block_id": block.ID}).Warn("next block of snapshot is not finalized")
+		return err
+	}
+	data, err := json.Marshal(votes)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, 0); err != nil {
+		return err
+	}
+
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		return err
+	}
+	if _, err = snapshot.Import(dbTransaction, f, stateRoot); err != nil {
+		dbTransaction.Rollback()
+		return err
+	}
+	fc := &model.FinalityCertificate{BlockID: block.ID, Hash: block.Hash, Votes: data, Time: block.Time}
+	if err = fc.Create(dbTransaction); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving finality certificate")
+		dbTransaction.Rollback()
+		return err
+	}
+	if err = dbTransaction.Commit(); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("committing snapshot")
+		return err
+	}
+
+	if err = syspar.SysUpdate(nil); err != nil {
+		return err
+	}
+	// the contracts of the snapshot are compiled over the contracts of the first block
+	if err = smart.LoadContracts(); err != nil {
+		return err
+	}
+	d.logger.WithFields(log.Fields{"block_id": blockID, "host": host}).Info("state is imported from snapshot")
+	return nil
+}
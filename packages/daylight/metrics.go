@@ -0,0 +1,108 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daylight
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/metrics"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/service"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	log "github.com/sirupsen/logrus"
+)
+
+// metricsCacheTime is the time while the values which are read from the database are reused by scrapes
+const metricsCacheTime = 15 * time.Second
+
+// cachedValue returns the function which calls value at most once per metricsCacheTime
+func cachedValue(value func() float64) func() float64 {
+	var (
+		mu      sync.Mutex
+		last    float64
+		updated time.Time
+	)
+	return func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		if time.Since(updated) >= metricsCacheTime {
+			last, updated = value(), time.Now()
+		}
+		return last
+	}
+}
+
+// initMetrics registers the metrics of the database and serves the metrics endpoint
+// at the separate address, the endpoint is off if the port isn't specified
+func initMetrics() {
+	if conf.Config.Metrics.Port == 0 {
+		return
+	}
+	if model.DBConn != nil {
+		initDBMetrics()
+	}
+
+	listenHost := conf.Config.Metrics.Str()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	go func() {
+		if err := http.ListenAndServe(listenHost, mux); err != nil {
+			log.WithFields(log.Fields{"host": listenHost, "error": err, "type": consts.NetworkError}).Error("serving metrics")
+		}
+	}()
+	log.WithFields(log.Fields{"host": listenHost}).Info("serving metrics at")
+}
+
+func initDBMetrics() {
+	sqlDB, err := model.DBConn.DB()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting sql DB for metrics")
+		return
+	}
+	gauges := []struct {
+		name, help string
+		value      func() float64
+	}{
+		{"block_height", "The id of the last block of the node.", func() float64 {
+			ib := &model.InfoBlock{}
+			if _, err := ib.Get(); err != nil {
+				return 0
+			}
+			return float64(ib.BlockID)
+		}},
+		{"mempool_size", "Count of transactions which are waiting for a block.", func() float64 {
+			count, _ := model.GetUnusedTransactionsCount()
+			return float64(count)
+		}},
+		{"banned_nodes", "Count of honor nodes which are banned by the node.", func() float64 {
+			var count int
+			b := service.GetNodesBanService()
+			if b == nil {
+				return 0
+			}
+			for _, n := range syspar.GetNodes() {
+				if b.IsBanned(n) {
+					count++
+				}
+			}
+			return float64(count)
+		}},
+	}
+	for _, g := range gauges {
+		if err = metrics.RegisterGauge(g.name, g.help, cachedValue(g.value)); err != nil {
+			log.WithFields(log.Fields{"error": err, "name": g.name}).Error("registering metric")
+		}
+	}
+	if err = metrics.Register(collectors.NewDBStatsCollector(sqlDB, conf.Config.DB.Name)); err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("registering db metrics")
+	}
+}
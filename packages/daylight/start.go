@@ -222,13 +222,17 @@ func Start() {
 		//}
 	}
 
-	initGorm(conf.Config.DB)
+	// the light mode doesn't have the database
+	if !conf.Config.IsLight() {
+		initGorm(conf.Config.DB)
+	}
 	log.WithFields(log.Fields{"work_dir": conf.Config.DataDir, "version": consts.Version()}).Info("started with")
 
 	killOld()
 
 	publisher.InitCentrifugo(conf.Config.Centrifugo)
 	initStatsd()
+	initMetrics()
 
 	err = initLogs()
 	if err != nil {
@@ -246,9 +250,11 @@ func Start() {
 	defer delPidFile()
 
 	smart.InitVM()
-	if err := syspar.ReadNodeKeys(); err != nil {
-		log.Errorf("can't read node keys: %s", err)
-		Exit(1)
+	if !conf.Config.IsLight() {
+		if err := syspar.ReadNodeKeys(); err != nil {
+			log.Errorf("can't read node keys: %s", err)
+			Exit(1)
+		}
 	}
 	if model.DBConn != nil {
 		if err := model.UpdateSchema(); err != nil {
@@ -269,6 +275,11 @@ func Start() {
 
 		obsmanager.InitOBSManager()
 	}
+	if conf.Config.IsLight() {
+		if err := daemonsctl.RunAllDaemons(context.Background()); err != nil {
+			Exit(1)
+		}
+	}
 	daemons.WaitForSignals()
 
 	initRoutes(conf.Config.HTTP.Str())
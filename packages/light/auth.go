@@ -0,0 +1,97 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package light
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+// apiTokens are the tokens of the node key at the api of honor nodes by the api address and ecosystem
+var apiTokens = struct {
+	sync.Mutex
+	tokens map[string]string
+}{tokens: make(map[string]string)}
+
+func apiRequest(ctx context.Context, method, apiURL, token string, form url.Values, v interface{}) (int, error) {
+	req, err := http.NewRequest(method, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// login signs in the api of honor node with the node key and returns the token
+func login(ctx context.Context, apiAddress string, ecosystem int64) (string, error) {
+	apiAddress = strings.TrimSuffix(apiAddress, "/")
+	uid := &struct {
+		UID       string `json:"uid"`
+		Token     string `json:"token"`
+		NetworkID string `json:"network_id"`
+	}{}
+	if _, err := apiRequest(ctx, "GET", apiAddress+"/api/v2/getuid", "", nil, uid); err != nil {
+		return "", err
+	}
+	sign, err := crypto.Sign(syspar.GetNodePrivKey(), []byte("LOGIN"+uid.NetworkID+uid.UID))
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"pubkey":    {hex.EncodeToString(syspar.GetNodePubKey())},
+		"signature": {hex.EncodeToString(sign)},
+		"ecosystem": {converter.Int64ToStr(ecosystem)},
+	}
+	result := &struct {
+		Token string `json:"token"`
+	}{}
+	if _, err = apiRequest(ctx, "POST", apiAddress+"/api/v2/login", uid.Token, form, result); err != nil {
+		return "", err
+	}
+	return result.Token, nil
+}
+
+// authToken returns the token for the api of honor node. The token is requested again if renew is true
+func authToken(ctx context.Context, apiAddress string, ecosystem int64, renew bool) (string, error) {
+	key := fmt.Sprintf("%s,%d", apiAddress, ecosystem)
+	apiTokens.Lock()
+	token, ok := apiTokens.tokens[key]
+	apiTokens.Unlock()
+	if ok && !renew {
+		return token, nil
+	}
+	token, err := login(ctx, apiAddress, ecosystem)
+	if err != nil {
+		return "", err
+	}
+	apiTokens.Lock()
+	apiTokens.tokens[key] = token
+	apiTokens.Unlock()
+	return token, nil
+}
@@ -0,0 +1,232 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+// Package light implements the light mode of the node. The node downloads only the headers of
+// blocks from honor nodes, verifies them and stores them in leveldb. The list of honor nodes is
+// trusted and is taken from the config, the first block is taken from the honor node as is.
+// The state is not stored, the rows are requested from honor nodes with signed proofs
+package light
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// syncInterval is the pause between the checks of new blocks
+	syncInterval = 5 * time.Second
+	// hostBanTime is the time during which the host that has sent the wrong headers is skipped
+	hostBanTime = 10 * time.Minute
+)
+
+var (
+	ErrNoHosts       = errors.New("No honor nodes for the light mode")
+	ErrNoHeaders     = errors.New("Host has not sent headers")
+	ErrHeaderOrder   = errors.New("Block ids of headers do not match")
+	ErrHeaderSign    = errors.New("Block header signature is incorrect")
+	ErrRollbacksHash = errors.New("Previous rollbacks hash of the header doesn't match")
+)
+
+// bannedHosts are the hosts which are skipped until the time
+var bannedHosts = struct {
+	sync.Mutex
+	till map[string]time.Time
+}{till: make(map[string]time.Time)}
+
+func banHost(host string) {
+	bannedHosts.Lock()
+	defer bannedHosts.Unlock()
+	bannedHosts.till[host] = time.Now().Add(hostBanTime)
+}
+
+// filterBannedHosts returns the hosts which are not banned
+func filterBannedHosts(hosts []string) []string {
+	bannedHosts.Lock()
+	defer bannedHosts.Unlock()
+	now := time.Now()
+	good := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if till, ok := bannedHosts.till[host]; ok {
+			if now.Before(till) {
+				continue
+			}
+			delete(bannedHosts.till, host)
+		}
+		good = append(good, host)
+	}
+	return good
+}
+
+// verifyHeader checks the header against the previous verified header and returns the header
+// with the calculated hash. prev is nil for the first block
+func verifyHeader(h *network.BlockHeader, prev *model.LightHeader) (*model.LightHeader, error) {
+	header, prevData, err := utils.ParseBlockHeader(bytes.NewBuffer(h.Data))
+	if err != nil {
+		return nil, err
+	}
+	if prev == nil {
+		if header.BlockID != 1 {
+			return nil, ErrHeaderOrder
+		}
+	} else {
+		if header.BlockID != prev.BlockID+1 {
+			return nil, ErrHeaderOrder
+		}
+		if header.Version >= consts.BvRollbackHash && !bytes.Equal(prevData.RollbacksHash, prev.RollbacksHash) {
+			return nil, ErrRollbacksHash
+		}
+		prevData.BlockID = prev.BlockID
+		prevData.Hash = prev.Hash
+
+		nodePublicKey, err := syspar.GetNodePublicKeyByPosition(header.NodePosition)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := utils.CheckSign([][]byte{nodePublicKey}, []byte(header.ForSign(&prevData, h.MrklRoot)), header.Sign, true)
+		if err != nil || !ok {
+			return nil, ErrHeaderSign
+		}
+	}
+	return &model.LightHeader{
+		BlockID:           header.BlockID,
+		Time:              header.Time,
+		EcosystemID:       header.EcosystemID,
+		KeyID:             header.KeyID,
+		NodePosition:      header.NodePosition,
+		Version:           header.Version,
+		Sign:              header.Sign,
+		Hash:              crypto.DoubleHash([]byte(header.ForSha(&prevData, h.MrklRoot))),
+		MrklRoot:          h.MrklRoot,
+		PrevRollbacksHash: prevData.RollbacksHash,
+		RollbacksHash:     h.RollbacksHash,
+		PrevStateRoot:     prevData.StateRoot,
+	}, nil
+}
+
+// isRolledBack reports whether the quorum of honor nodes has the header of another block
+// with the same id as the last verified header
+func isRolledBack(hosts []string, last *model.LightHeader, logger *log.Entry) (bool, error) {
+	var before *model.LightHeader
+	if last.BlockID > 1 {
+		before = &model.LightHeader{}
+		found, err := before.Get(last.BlockID - 1)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting header")
+			return false, err
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	var count int
+	for _, host := range hosts {
+		headers, err := tcpclient.GetHeaders(host, last.BlockID, 1, logger)
+		if err != nil || len(headers) == 0 {
+			continue
+		}
+		header, err := verifyHeader(headers[0], before)
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(header.Hash, last.Hash) {
+			count++
+		}
+	}
+	return count >= utils.FinalityQuorum(int64(len(syspar.GetNodes()))), nil
+}
+
+// syncHeaders downloads and verifies the headers up to the max block of honor nodes.
+// The host which has sent the wrong headers is banned for hostBanTime. If the first downloaded
+// header doesn't match the last verified header and the quorum of honor nodes has another
+// block with the id of the last header, the last header has been rolled back and it is deleted
+func syncHeaders(ctx context.Context, logger *log.Entry) error {
+	hosts := filterBannedHosts(syspar.GetRemoteHosts())
+	if len(hosts) == 0 {
+		return ErrNoHosts
+	}
+	host, maxBlockID, err := tcpclient.HostWithMaxBlock(ctx, hosts)
+	if err != nil {
+		return err
+	}
+
+	var prev *model.LightHeader
+	last := &model.LightHeader{}
+	found, err := last.GetLast()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last header")
+		return err
+	}
+	if found {
+		prev = last
+	}
+	for prev == nil || prev.BlockID < maxBlockID {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		from := int64(1)
+		if prev != nil {
+			from = prev.BlockID + 1
+		}
+		headers, err := tcpclient.GetHeaders(host, from, network.HeadersPerRequest, logger)
+		if err != nil {
+			return err
+		}
+		if len(headers) == 0 {
+			banHost(host)
+			return ErrNoHeaders
+		}
+		for i, h := range headers {
+			header, err := verifyHeader(h, prev)
+			if err != nil {
+				logger.WithFields(log.Fields{"type": consts.BlockError, "error": err, "host": host, "block_id": from + int64(i)}).Warn("verifying header")
+				if i == 0 && prev != nil && (err == ErrHeaderSign || err == ErrRollbacksHash) {
+					rolledBack, errRollback := isRolledBack(hosts, prev, logger)
+					if errRollback != nil {
+						return errRollback
+					}
+					if rolledBack {
+						return model.DeleteLightHeader(prev.BlockID)
+					}
+				}
+				banHost(host)
+				return err
+			}
+			if err = header.Save(); err != nil {
+				logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving header")
+				return err
+			}
+			prev = header
+		}
+	}
+	return nil
+}
+
+// Sync keeps the headers of the light mode up to date
+func Sync(ctx context.Context) {
+	logger := log.WithFields(log.Fields{"daemon_name": "LightSync"})
+	for {
+		if err := syncHeaders(ctx, logger); err != nil {
+			logger.WithFields(log.Fields{"error": err}).Debug("syncing headers")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(syncInterval):
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package light
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// proofTimeout is the time for getting the row proof from honor node
+const proofTimeout = 10 * time.Second
+
+var ErrRowQuorum = errors.New("Not enough honor nodes have confirmed the row")
+
+func isHonorNode(key []byte) bool {
+	_, err := syspar.GetNodePositionByPublicKey(key)
+	return err == nil
+}
+
+// isVerifiedBlock reports whether the block hash matches the verified header
+func isVerifiedBlock(blockID int64, hash []byte) bool {
+	header := &model.LightHeader{}
+	found, err := header.Get(blockID)
+	return err == nil && found && bytes.Equal(header.Hash, hash)
+}
+
+// getRowProof requests the proof of the row in the ecosystem. The row is read with the access rights
+// of the node key so the node signs in the ecosystem at honor node
+func getRowProof(ctx context.Context, apiAddress, name string, id, ecosystem int64) (*utils.RowProof, error) {
+	ctx, cancel := context.WithTimeout(ctx, proofTimeout)
+	defer cancel()
+
+	proofURL := fmt.Sprintf("%s/api/v2/rowproof/%s/%d", strings.TrimSuffix(apiAddress, "/"), url.PathEscape(name), id)
+	proof := &utils.RowProof{}
+	for renew := false; ; renew = true {
+		token, err := authToken(ctx, apiAddress, ecosystem, renew)
+		if err != nil {
+			return nil, err
+		}
+		status, err := apiRequest(ctx, "GET", proofURL, token, nil, proof)
+		// the token has expired
+		if status == http.StatusUnauthorized && !renew {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	}
+}
+
+// rowByQuorum returns the proof of the requested row which is confirmed by the quorum of honor nodes.
+// The nodes can have different last blocks, the proof with the latest verified block is returned
+func rowByQuorum(table string, id, ecosystem int64, proofs []*utils.RowProof, nodes int64,
+	isHonorNode func([]byte) bool, isVerifiedBlock func(int64, []byte) bool) (*utils.RowProof, error) {
+	var (
+		keys   = make(map[string]bool)
+		counts = make(map[string]int)
+		latest = make(map[string]*utils.RowProof)
+	)
+	for _, p := range proofs {
+		// the signed proof of another row is not the answer
+		if p.Table != table || p.ID != id || p.Ecosystem != ecosystem {
+			continue
+		}
+		key := hex.EncodeToString(p.NodeKey)
+		if keys[key] || !isHonorNode(p.NodeKey) || p.Verify() != nil || !isVerifiedBlock(p.BlockID, p.Hash) {
+			continue
+		}
+		keys[key] = true
+		data := string(p.Data)
+		counts[data]++
+		if latest[data] == nil || latest[data].BlockID < p.BlockID {
+			latest[data] = p
+		}
+	}
+	for data, count := range counts {
+		if count >= utils.FinalityQuorum(nodes) {
+			return latest[data], nil
+		}
+	}
+	return nil, ErrRowQuorum
+}
+
+// GetRow requests the row from all honor nodes and returns it if the quorum of honor nodes
+// has signed the same value at the verified blocks. Data of the result is empty if the row doesn't exist
+func GetRow(ctx context.Context, name string, id, ecosystem int64) (*utils.RowProof, error) {
+	nodes := syspar.GetNodes()
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		proofs []*utils.RowProof
+	)
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(apiAddress string) {
+			defer wg.Done()
+			p, err := getRowProof(ctx, apiAddress, name, id, ecosystem)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": apiAddress}).Debug("getting row proof")
+				return
+			}
+			mu.Lock()
+			proofs = append(proofs, p)
+			mu.Unlock()
+		}(node.APIAddress)
+	}
+	wg.Wait()
+
+	return rowByQuorum(converter.ParseTable(name, ecosystem), id, ecosystem, proofs, int64(len(nodes)),
+		isHonorNode, isVerifiedBlock)
+}
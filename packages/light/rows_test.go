@@ -0,0 +1,72 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package light
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowByQuorum(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	crypto.InitHash("SHA256")
+
+	hashes := map[int64][]byte{10: []byte("hash10"), 11: []byte("hash11")}
+	isVerifiedBlock := func(blockID int64, hash []byte) bool {
+		return bytes.Equal(hashes[blockID], hash)
+	}
+	var (
+		keys   [][]byte
+		proofs []*utils.RowProof
+	)
+	for i := 0; i < 4; i++ {
+		priv, pub, err := crypto.GenKeyPair()
+		require.NoError(t, err)
+		keys = append(keys, pub)
+		blockID := int64(10 + i%2)
+		p := &utils.RowProof{BlockID: blockID, Hash: hashes[blockID], Table: "1_keys", ID: 5, Ecosystem: 1,
+			Data: []byte(`{"amount":"100"}`)}
+		require.NoError(t, utils.SignRowProof(p, pub, priv))
+		proofs = append(proofs, p)
+	}
+	isHonorNode := func(key []byte) bool {
+		for _, k := range keys {
+			if bytes.Equal(k, key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	p, err := rowByQuorum("1_keys", 5, 1, proofs[:3], 4, isHonorNode, isVerifiedBlock)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), p.BlockID)
+
+	_, err = rowByQuorum("1_keys", 5, 1, append(proofs[:2:2], proofs[0]), 4, isHonorNode, isVerifiedBlock)
+	assert.Equal(t, ErrRowQuorum, err)
+
+	hashes[11] = []byte("fork")
+	_, err = rowByQuorum("1_keys", 5, 1, proofs[:3], 4, isHonorNode, isVerifiedBlock)
+	assert.Equal(t, ErrRowQuorum, err)
+	hashes[11] = []byte("hash11")
+
+	// the proofs of another row are not counted
+	_, err = rowByQuorum("1_keys", 6, 1, proofs[:3], 4, isHonorNode, isVerifiedBlock)
+	assert.Equal(t, ErrRowQuorum, err)
+	_, err = rowByQuorum("2_keys", 5, 2, proofs[:3], 4, isHonorNode, isVerifiedBlock)
+	assert.Equal(t, ErrRowQuorum, err)
+
+	// the changed value isn't signed by the node
+	proofs[2].Data = []byte(`{"amount":"200"}`)
+	_, err = rowByQuorum("1_keys", 5, 1, proofs[:3], 4, isHonorNode, isVerifiedBlock)
+	assert.Equal(t, ErrRowQuorum, err)
+}
@@ -0,0 +1,106 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+// Package metrics exports the metrics of the node in the Prometheus exposition format
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ibax"
+
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// BlockPlayDuration is the time of playing the transactions of the block
+	BlockPlayDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "block_play_duration_seconds",
+		Help:      "Time of playing the transactions of the block.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	})
+	// DaemonDuration is the time of one loop of the daemon
+	DaemonDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "daemon_loop_duration_seconds",
+		Help:      "Time of one loop of the daemon.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+	}, []string{"daemon"})
+	// DaemonErrors is the count of loops of the daemon which have returned the error
+	DaemonErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "daemon_errors_total",
+		Help:      "Count of loops of the daemon which have returned an error.",
+	}, []string{"daemon"})
+	// HTTPDuration is the time of the API request by the route and the status
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time of the API request.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	// TCPRequests is the count of the requests of the tcp server by the type and the result
+	TCPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tcp_requests_total",
+		Help:      "Count of requests of the tcp server.",
+	}, []string{"type", "result"})
+	// RateLimited is the count of the API requests which are rejected by the rate limits
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_rate_limited_total",
+		Help:      "Count of API requests rejected by rate limits.",
+	}, []string{"scope", "group"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		BlockPlayDuration, DaemonDuration, DaemonErrors, HTTPDuration, TCPRequests, RateLimited,
+	)
+}
+
+// Result returns the label of the result of the operation
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultOK
+}
+
+// Register adds the collectors to the exported metrics
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterGauge adds the gauge which value is returned by the function on every scrape
+func RegisterGauge(name, help string, value func() float64) error {
+	return registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+// Handler returns the handler of the metrics endpoint
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
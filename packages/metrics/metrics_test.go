@@ -0,0 +1,36 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	require.NoError(t, RegisterGauge("test_value", "Test value.", func() float64 { return 42 }))
+	assert.Error(t, RegisterGauge("test_value", "Test value.", func() float64 { return 0 }))
+
+	TCPRequests.WithLabelValues("7", Result(nil)).Inc()
+	TCPRequests.WithLabelValues("7", Result(errors.New("failed"))).Inc()
+	DaemonDuration.WithLabelValues("BlocksCollection").Observe(0.5)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	data, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	body := string(data)
+	assert.Contains(t, body, "ibax_test_value 42")
+	assert.Contains(t, body, `ibax_tcp_requests_total{result="ok",type="7"} 1`)
+	assert.Contains(t, body, `ibax_tcp_requests_total{result="error",type="7"} 1`)
+	assert.Contains(t, body, `ibax_daemon_loop_duration_seconds_count{daemon="BlocksCollection"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
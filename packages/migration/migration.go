@@ -37,6 +37,15 @@ var migrationsCLB = &migration{"0.1.8", migrationInitialTablesCLB, true}
 var updateMigrations = []*migration{
 	&migration{"3.1.0", updates.M310, false},
 	&migration{"3.2.0", updates.M320, false},
+	&migration{"3.3.0", updates.M330, true},
+	&migration{"3.3.1", updates.M331, true},
+	&migration{"3.3.2", updates.M332, true},
+	&migration{"3.3.3", updates.M333, true},
+	&migration{"3.3.4", updates.M334, true},
+	&migration{"3.3.5", updates.M335, true},
+	&migration{"3.3.6", updates.M336, true},
+	&migration{"3.3.7", updates.M337, true},
+	&migration{"3.3.8", updates.M338, false},
 
 type database interface {
 	CurrentVersion() (string, error)
@@ -127,7 +136,8 @@ func InitMigrate(db database) error {
 	return runMigrations(db, mig)
 }
 
-// UpdateMigrate applies update migrations
+// UpdateMigrate applies update migrations. The updates are checked up to the last of them,
+// so the nodes which have applied earlier updates get the new ones too
 func UpdateMigrate(db database) error {
-	return runMigrations(db, updateMigrations)
+	return migrate(db, updateMigrations[len(updateMigrations)-1].version, updateMigrations)
 }
@@ -0,0 +1,21 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M330 adds the table of contract events
+var M330 = `
+	{{headseq "contract_events"}}
+		t.Column("id", "bigint", {"default_raw": "nextval('contract_events_id_seq')"})
+		t.Column("block_id", "bigint", {"default": "0"})
+		t.Column("tx_hash", "bytea", {"default": ""})
+		t.Column("ecosystem", "bigint", {"default": "0"})
+		t.Column("contract", "string", {"default": "", "size":255})
+		t.Column("name", "string", {"default": "", "size":255})
+		t.Column("topics", "jsonb", {"default": "{}"})
+		t.Column("data", "jsonb", {"default": "{}"})
+	{{footer "seq" "primary" "index(block_id)" "index(contract, name)" "index(tx_hash)"}}
+	sql("CREATE INDEX \"contract_events_topics\" ON \"contract_events\" USING GIN (topics);")
+`
@@ -0,0 +1,16 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M331 adds the table of finality certificates
+var M331 = `
+	{{head "finality_certificates"}}
+		t.Column("block_id", "bigint", {"default": "0"})
+		t.Column("hash", "bytea", {"default": ""})
+		t.Column("votes", "bytea", {"default": ""})
+		t.Column("time", "int", {"default": "0"})
+	{{footer "primary(block_id)"}}
+`
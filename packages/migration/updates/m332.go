@@ -0,0 +1,16 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M332 adds the table of state snapshots
+var M332 = `
+	{{head "snapshots"}}
+		t.Column("block_id", "bigint", {"default": "0"})
+		t.Column("hash", "bytea", {"default": ""})
+		t.Column("size", "bigint", {"default": "0"})
+		t.Column("time", "int", {"default": "0"})
+	{{footer "primary(block_id)"}}
+`
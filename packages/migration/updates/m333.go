@@ -0,0 +1,30 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M333 adds the tables of fork branches and reorganizations
+var M333 = `
+	{{head "fork_blocks"}}
+		t.Column("hash", "bytea", {"default": ""})
+		t.Column("block_id", "bigint", {"default": "0"})
+		t.Column("fork_id", "bigint", {"default": "0"})
+		t.Column("node_position", "bigint", {"default": "0"})
+		t.Column("time", "int", {"default": "0"})
+		t.Column("host", "string", {"default": "", "size":255})
+		t.Column("data", "bytea", {"default": ""})
+	{{footer "primary(hash)" "index(fork_id, block_id)"}}
+
+	{{headseq "reorgs"}}
+		t.Column("id", "bigint", {"default_raw": "nextval('reorgs_id_seq')"})
+		t.Column("block_id", "bigint", {"default": "0"})
+		t.Column("depth", "int", {"default": "0"})
+		t.Column("old_hash", "bytea", {"default": ""})
+		t.Column("new_hash", "bytea", {"default": ""})
+		t.Column("host", "string", {"default": "", "size":255})
+		t.Column("txs", "jsonb", {"default": "[]"})
+		t.Column("time", "int", {"default": "0"})
+	{{footer "seq" "primary" "index(block_id)"}}
+`
@@ -0,0 +1,14 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M334 adds the table of pruned blocks
+var M334 = `
+	{{head "pruned_blocks"}}
+		t.Column("id", "bigint", {"default": "0"})
+		t.Column("mrkl_root", "bytea", {"default": ""})
+	{{footer "primary"}}
+`
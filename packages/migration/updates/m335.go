@@ -0,0 +1,11 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M335 adds the state root to the blocks
+var M335 = `
+	add_column("block_chain", "state_root", "bytea", {"null": true})
+`
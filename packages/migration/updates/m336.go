@@ -0,0 +1,24 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M336 adds the table of scoped API keys
+var M336 = `
+	{{headseq "api_keys"}}
+		t.Column("id", "bigint", {"default_raw": "nextval('api_keys_id_seq')"})
+		t.Column("name", "string", {"default": "", "size":255})
+		t.Column("hash", "bytea", {"default": ""})
+		t.Column("key_id", "bigint", {"default": "0"})
+		t.Column("account_id", "string", {"default": "", "size":255})
+		t.Column("ecosystem_id", "bigint", {"default": "0"})
+		t.Column("role_id", "bigint", {"default": "0"})
+		t.Column("routes", "jsonb", {"default": "[]"})
+		t.Column("contracts", "jsonb", {"default": "[]"})
+		t.Column("ecosystems", "jsonb", {"default": "[]"})
+		t.Column("expire", "int", {"default": "0"})
+		t.Column("time", "int", {"default": "0"})
+	{{footer "seq" "primary" "index(key_id)"}}
+`
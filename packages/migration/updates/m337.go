@@ -0,0 +1,14 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M337 adds the table of the block hashes which have been signed by the node
+var M337 = `
+	{{head "node_votes"}}
+		t.Column("block_id", "bigint", {"default": "0"})
+		t.Column("hash", "bytea", {"default": ""})
+	{{footer "primary(block_id)"}}
+`
@@ -0,0 +1,12 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+// M338 adds the block of the activation of the state root, the state root is disabled by 0
+var M338 = `
+INSERT INTO "1_system_parameters" (id, name, value, conditions) VALUES
+	(next_id('1_system_parameters'), 'state_root_block', '0', 'ContractAccess("@1UpdateSysParam")');
+`
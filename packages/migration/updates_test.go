@@ -0,0 +1,27 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateMigrations(t *testing.T) {
+	prev := "0.0.0"
+	for _, m := range updateMigrations {
+		cmp, err := compareVer(prev, m.version)
+		require.NoError(t, err)
+		require.Equal(t, -1, cmp, m.version)
+		prev = m.version
+		if !m.template {
+			continue
+		}
+		_, err = sqlConvert([]string{m.data})
+		require.NoError(t, err, m.version)
+	}
+}
@@ -0,0 +1,52 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// APIKey is the long-lived key of the service account which is minted by the owner of the account.
+// Only the hash of the secret is stored. Routes, Contracts and Ecosystems are json arrays
+// which restrict the requests of the key
+type APIKey struct {
+	ID          int64  `gorm:"primary_key;not null" json:"id"`
+	Name        string `gorm:"not null;size:255" json:"name"`
+	Hash        []byte `gorm:"not null" json:"-"`
+	KeyID       int64  `gorm:"not null" json:"key_id"`
+	AccountID   string `gorm:"not null;size:255" json:"account"`
+	EcosystemID int64  `gorm:"not null" json:"ecosystem_id"`
+	RoleID      int64  `gorm:"not null" json:"role_id"`
+	Routes      string `gorm:"not null;type:jsonb" json:"routes"`
+	Contracts   string `gorm:"not null;type:jsonb" json:"contracts"`
+	Ecosystems  string `gorm:"not null;type:jsonb" json:"ecosystems"`
+	Expire      int64  `gorm:"not null" json:"expire"`
+	Time        int64  `gorm:"not null" json:"time"`
+}
+
+// TableName returns name of table
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Create is creating record of model
+func (k *APIKey) Create(dbTransaction *DbTransaction) error {
+	return GetDB(dbTransaction).Create(k).Error
+}
+
+// Get is retrieving the key by id
+func (k *APIKey) Get(id int64) (bool, error) {
+	return isFound(DBConn.Where("id = ?", id).First(k))
+}
+
+// GetAPIKeys returns the keys which have been minted by the account
+func GetAPIKeys(keyID int64) ([]APIKey, error) {
+	var list []APIKey
+	err := DBConn.Where("key_id = ?", keyID).Order("id").Find(&list).Error
+	return list, err
+}
+
+// DeleteAPIKey revokes the key of the account, it returns false if there is not such key
+func DeleteAPIKey(id, keyID int64) (bool, error) {
+	res := DBConn.Exec("DELETE FROM api_keys WHERE id = ? AND key_id = ?", id, keyID)
+	return res.RowsAffected > 0, res.Error
+}
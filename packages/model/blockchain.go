@@ -20,6 +20,7 @@ type Block struct {
 	NodePosition  int64  `gorm:"not null"`
 	Time          int64  `gorm:"not null"`
 	Tx            int32  `gorm:"not null"`
+	StateRoot     []byte
 }
 
 // TableName returns name of table
@@ -44,6 +45,11 @@ func (b *Block) GetMaxForeignBlock(keyId int64) (bool, error) {
 	return isFound(DBConn.Order("id DESC").Where("key_id != ?", keyId).First(b))
 }
 
+// GetLastBefore returns the last block whose time is less than the time
+func (b *Block) GetLastBefore(time int64) (bool, error) {
+	return isFound(DBConn.Where("time < ?", time).Order("id DESC").First(b))
+}
+
 // GetBlockchain is retrieving chain of blocks from database
 func GetBlockchain(startBlockID int64, endblockID int64, order ordering) ([]Block, error) {
 	var err error
@@ -0,0 +1,108 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ContractEvent is the event which has been emitted by the contract with EmitEvent
+type ContractEvent struct {
+	ID          int64  `gorm:"primary_key;not null" json:"id"`
+	BlockID     int64  `gorm:"not null" json:"block_id"`
+	TxHash      []byte `gorm:"not null" json:"tx_hash"`
+	EcosystemID int64  `gorm:"not null;column:ecosystem" json:"ecosystem"`
+	Contract    string `gorm:"not null;size:255" json:"contract"`
+	Name        string `gorm:"not null;size:255" json:"name"`
+	Topics      string `gorm:"not null;type:jsonb" json:"topics"`
+	Data        string `gorm:"not null;type:jsonb" json:"data"`
+}
+
+// ContractEventInfo is the event in the form which is sent to the clients
+type ContractEventInfo struct {
+	ID          int64           `json:"id"`
+	BlockID     int64           `json:"block_id"`
+	TxHash      string          `json:"tx_hash"`
+	EcosystemID int64           `json:"ecosystem"`
+	Contract    string          `json:"contract"`
+	Name        string          `json:"name"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// ContractEventFilter contains the conditions of the events query. Empty fields are not checked
+type ContractEventFilter struct {
+	EcosystemID int64
+	Contract    string
+	Name        string
+	FromBlock   int64
+	ToBlock     int64
+	// Contracts are the full names of the contracts, one of them must emit the event
+	Contracts []string
+	// Topics is json object, the topics of the event must contain all its fields
+	Topics string
+	// AfterID is the id of the last event of the previous page
+	AfterID int64
+	Limit   int
+}
+
+// TableName returns name of table
+func (*ContractEvent) TableName() string {
+	return "contract_events"
+}
+
+// Info returns the event for the clients
+func (e *ContractEvent) Info() *ContractEventInfo {
+	return &ContractEventInfo{
+		ID:          e.ID,
+		BlockID:     e.BlockID,
+		TxHash:      hex.EncodeToString(e.TxHash),
+		EcosystemID: e.EcosystemID,
+		Contract:    e.Contract,
+		Name:        e.Name,
+		Data:        json.RawMessage(e.Data),
+	}
+}
+
+// Create is creating record of model
+func (e *ContractEvent) Create(transaction *DbTransaction) error {
+	return GetDB(transaction).Create(e).Error
+}
+
+// DeleteContractEventsByHash is deleting the events of the transaction
+func DeleteContractEventsByHash(transaction *DbTransaction, hash []byte) (int64, error) {
+	query := GetDB(transaction).Exec("DELETE FROM contract_events WHERE tx_hash = ?", hash)
+	return query.RowsAffected, query.Error
+}
+
+// GetContractEvents returns the page of events which match the filter ordered by id
+func GetContractEvents(filter *ContractEventFilter) ([]ContractEvent, error) {
+	var events []ContractEvent
+	q := DBConn.Model(&ContractEvent{}).Where("id > ?", filter.AfterID)
+	if filter.EcosystemID > 0 {
+		q = q.Where("ecosystem = ?", filter.EcosystemID)
+	}
+	if len(filter.Contract) > 0 {
+		q = q.Where("contract = ?", filter.Contract)
+	}
+	if filter.Contracts != nil {
+		q = q.Where("contract IN (?)", filter.Contracts)
+	}
+	if len(filter.Name) > 0 {
+		q = q.Where("name = ?", filter.Name)
+	}
+	if filter.FromBlock > 0 {
+		q = q.Where("block_id >= ?", filter.FromBlock)
+	}
+	if filter.ToBlock > 0 {
+		q = q.Where("block_id <= ?", filter.ToBlock)
+	}
+	if len(filter.Topics) > 0 {
+		q = q.Where("topics @> ?::jsonb", filter.Topics)
+	}
+	err := q.Order("id asc").Limit(filter.Limit).Find(&events).Error
+	return events, err
+}
@@ -6,8 +6,12 @@
 package model
 
 import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -170,6 +174,25 @@ func StartTransaction() (*DbTransaction, error) {
 	}, nil
 }
 
+// StartSnapshotTransaction is beginning read-only transaction which sees the database
+// at the moment of the first query. It doesn't block the writes of other transactions
+func StartSnapshotTransaction() (*DbTransaction, error) {
+	conn := DBConn.Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if conn.Error != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": conn.Error}).Error("cannot start snapshot transaction")
+		return nil, conn.Error
+	}
+
+	if err := setupConnOptions(conn); err != nil {
+		conn.Rollback()
+		return nil, err
+	}
+
+	return &DbTransaction{
+		conn: conn,
+	}, nil
+}
+
 // Rollback is transaction rollback
 func (tr *DbTransaction) Rollback() error {
 	return tr.conn.Rollback().Error
@@ -454,6 +477,63 @@ func GetColumnByID(table, column, id string) (result string, err error) {
 	return
 }
 
+// rowState returns the json object of the values of the row with the sorted keys. The values are
+// formatted here so the state doesn't depend on the session settings of the database
+func rowState(columns []string, values []interface{}) (string, error) {
+	row := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		switch v := values[i].(type) {
+		case []byte:
+			row[column] = hex.EncodeToString(v)
+		case time.Time:
+			row[column] = v.UTC().Format(time.RFC3339Nano)
+		case float64:
+			row[column] = strconv.FormatFloat(v, 'g', -1, 64)
+		case float32:
+			row[column] = strconv.FormatFloat(float64(v), 'g', -1, 32)
+		default:
+			row[column] = v
+		}
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GetRowState returns the json representation of the row of the table by id. The row of the table
+// of the first ecosystem is also filtered by ecosystem. Empty string is returned if the row is deleted
+func GetRowState(transaction *DbTransaction, table, id, ecosystem string) (string, error) {
+	query := fmt.Sprintf(`SELECT * FROM "%s" WHERE id = ?`, table)
+	args := []interface{}{id}
+	if len(ecosystem) > 0 {
+		query += ` AND ecosystem = ?`
+		args = append(args, ecosystem)
+	}
+	rows, err := GetDB(transaction).Raw(query, args...).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return "", rows.Err()
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err = rows.Scan(ptrs...); err != nil {
+		return "", err
+	}
+	return rowState(columns, values)
+}
+
 // InitDB drop all tables and exec db schema
 func InitDB(cfg conf.DBConfig) error {
 
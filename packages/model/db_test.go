@@ -0,0 +1,32 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowState(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*3600)
+	columns := []string{"id", "name", "pub", "amount", "rate", "time", "value", "deleted"}
+	values := []interface{}{int64(5), "test", []byte{0x01, 0xab}, "100.5", 0.1, time.Date(2021, 1, 1, 3, 0, 0, 0, moscow),
+		`{"a": 1}`, nil}
+
+	state, err := rowState(columns, values)
+	require.NoError(t, err)
+	assert.Equal(t, `{"amount":"100.5","deleted":null,"id":5,"name":"test","pub":"01ab","rate":"0.1",`+
+		`"time":"2021-01-01T00:00:00Z","value":"{\"a\": 1}"}`, state)
+
+	// the time is formatted in UTC
+	values[5] = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	same, err := rowState(columns, values)
+	require.NoError(t, err)
+	assert.Equal(t, state, same)
+}
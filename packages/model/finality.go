@@ -0,0 +1,77 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import "gorm.io/gorm/clause"
+
+// FinalityCertificate contains the votes of honor nodes which have signed the block hash.
+// The block with the certificate and all previous blocks cannot be rolled back
+type FinalityCertificate struct {
+	BlockID int64  `gorm:"primary_key;not null"`
+	Hash    []byte `gorm:"not null"`
+	Votes   []byte `gorm:"not null"` // json array of signed votes
+	Time    int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (FinalityCertificate) TableName() string {
+	return "finality_certificates"
+}
+
+// Create is creating record of model
+func (fc *FinalityCertificate) Create(dbTransaction *DbTransaction) error {
+	return GetDB(dbTransaction).Create(fc).Error
+}
+
+// Get is retrieving the certificate of the block
+func (fc *FinalityCertificate) Get(blockID int64) (bool, error) {
+	return isFound(DBConn.Where("block_id = ?", blockID).First(fc))
+}
+
+// GetFor is retrieving the first certificate which finalizes the block
+func (fc *FinalityCertificate) GetFor(blockID int64) (bool, error) {
+	return isFound(DBConn.Where("block_id >= ?", blockID).Order("block_id").First(fc))
+}
+
+// GetLast is retrieving the certificate of the last finalized block
+func (fc *FinalityCertificate) GetLast(dbTransaction *DbTransaction) (bool, error) {
+	return isFound(GetDB(dbTransaction).Order("block_id desc").First(fc))
+}
+
+// GetFinalizedBlockID returns the id of the last finalized block or 0
+func GetFinalizedBlockID(dbTransaction *DbTransaction) (int64, error) {
+	fc := &FinalityCertificate{}
+	if _, err := fc.GetLast(dbTransaction); err != nil {
+		return 0, err
+	}
+	return fc.BlockID, nil
+}
+
+// NodeVote is the hash of the block which has been signed by the node
+type NodeVote struct {
+	BlockID int64  `gorm:"primary_key;not null"`
+	Hash    []byte `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (NodeVote) TableName() string {
+	return "node_votes"
+}
+
+// NodeVotes is the storage of the votes of the node
+type NodeVotes struct{}
+
+// SaveVote saves the hash if the node hasn't voted for the block yet and returns the saved hash
+func (NodeVotes) SaveVote(blockID int64, hash []byte) ([]byte, error) {
+	vote := &NodeVote{BlockID: blockID, Hash: hash}
+	if err := DBConn.Clauses(clause.OnConflict{DoNothing: true}).Create(vote).Error; err != nil {
+		return nil, err
+	}
+	if err := DBConn.Where("block_id = ?", blockID).First(vote).Error; err != nil {
+		return nil, err
+	}
+	return vote.Hash, nil
+}
@@ -0,0 +1,77 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// ForkBlock is the block of the alternative branch which isn't in the blockchain of the node.
+// ForkID is the id of the first block of the branch. The blocks which are announced
+// by honor nodes through queue_blocks don't have data
+type ForkBlock struct {
+	Hash         []byte `gorm:"primary_key;not null" json:"hash"`
+	BlockID      int64  `gorm:"not null" json:"block_id"`
+	ForkID       int64  `gorm:"not null" json:"fork_id"`
+	NodePosition int64  `gorm:"not null" json:"node_position"`
+	Time         int64  `gorm:"not null" json:"time"`
+	Host         string `gorm:"not null;size:255" json:"host"`
+	Data         []byte `gorm:"not null" json:"-"`
+}
+
+// TableName returns name of table
+func (ForkBlock) TableName() string {
+	return "fork_blocks"
+}
+
+// Save is saving the block, the existing block with the same hash is replaced
+func (fb *ForkBlock) Save(dbTransaction *DbTransaction) error {
+	return GetDB(dbTransaction).Save(fb).Error
+}
+
+// GetForkBlocks returns the blocks of the alternative branches ordered by branches
+func GetForkBlocks() ([]ForkBlock, error) {
+	var list []ForkBlock
+	err := DBConn.Select("hash, block_id, fork_id, node_position, time, host").
+		Order("fork_id, block_id, hash").Find(&list).Error
+	return list, err
+}
+
+// DeleteForkBlock deletes the block which has become the part of the blockchain
+func DeleteForkBlock(dbTransaction *DbTransaction, hash []byte) error {
+	return GetDB(dbTransaction).Exec("DELETE FROM fork_blocks WHERE hash = ?", hash).Error
+}
+
+// DeleteForkBlocksBefore deletes the branches which have started before the block
+func DeleteForkBlocksBefore(dbTransaction *DbTransaction, blockID int64) error {
+	return GetDB(dbTransaction).Exec("DELETE FROM fork_blocks WHERE fork_id < ?", blockID).Error
+}
+
+// Reorg is the replacement of the blocks of the node with the blocks of another branch.
+// Txs is json array of hashes of transactions which are not included in the new branch
+type Reorg struct {
+	ID      int64  `gorm:"primary_key;not null" json:"id"`
+	BlockID int64  `gorm:"not null" json:"block_id"`
+	Depth   int64  `gorm:"not null" json:"depth"`
+	OldHash []byte `gorm:"not null" json:"old_hash"`
+	NewHash []byte `gorm:"not null" json:"new_hash"`
+	Host    string `gorm:"not null;size:255" json:"host"`
+	Txs     string `gorm:"not null;type:jsonb" json:"txs"`
+	Time    int64  `gorm:"not null" json:"time"`
+}
+
+// TableName returns name of table
+func (Reorg) TableName() string {
+	return "reorgs"
+}
+
+// Create is creating record of model
+func (r *Reorg) Create(dbTransaction *DbTransaction) error {
+	return GetDB(dbTransaction).Create(r).Error
+}
+
+// GetLastReorgs returns the latest reorgs
+func GetLastReorgs(limit int) ([]Reorg, error) {
+	var list []Reorg
+	err := DBConn.Order("id desc").Limit(limit).Find(&list).Error
+	return list, err
+}
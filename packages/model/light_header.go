@@ -0,0 +1,87 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	lightHeaderPrefix = "light_header_"
+	lightHeaderLast   = "light_header_last"
+)
+
+// LightHeader is the verified block header which is stored in leveldb by the light mode
+type LightHeader struct {
+	BlockID           int64  `json:"block_id"`
+	Time              int64  `json:"time"`
+	EcosystemID       int64  `json:"ecosystem_id"`
+	KeyID             int64  `json:"key_id"`
+	NodePosition      int64  `json:"node_position"`
+	Version           int    `json:"version"`
+	Sign              []byte `json:"sign"`
+	Hash              []byte `json:"hash"`
+	MrklRoot          []byte `json:"mrkl_root"`
+	PrevRollbacksHash []byte `json:"prev_rollbacks_hash"`
+	RollbacksHash     []byte `json:"rollbacks_hash"`
+	PrevStateRoot     []byte `json:"prev_state_root,omitempty"`
+}
+
+func lightHeaderKey(blockID int64) []byte {
+	return []byte(fmt.Sprintf("%s%020d", lightHeaderPrefix, blockID))
+}
+
+// Save writes the header and makes it the last one
+func (h *LightHeader) Save() error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	batch.Put(lightHeaderKey(h.BlockID), data)
+	batch.Put([]byte(lightHeaderLast), data)
+	return DBlevel.Write(batch, nil)
+}
+
+func (h *LightHeader) get(key []byte) (bool, error) {
+	data, err := DBlevel.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, h)
+}
+
+// Get is retrieving the header of the block
+func (h *LightHeader) Get(blockID int64) (bool, error) {
+	return h.get(lightHeaderKey(blockID))
+}
+
+// GetLast is retrieving the last verified header
+func (h *LightHeader) GetLast() (bool, error) {
+	return h.get([]byte(lightHeaderLast))
+}
+
+// DeleteLightHeader deletes the last header, the previous header becomes the last one
+func DeleteLightHeader(blockID int64) error {
+	batch := new(leveldb.Batch)
+	batch.Delete(lightHeaderKey(blockID))
+	prev, err := DBlevel.Get(lightHeaderKey(blockID-1), nil)
+	switch err {
+	case nil:
+		batch.Put([]byte(lightHeaderLast), prev)
+	case leveldb.ErrNotFound:
+		batch.Delete([]byte(lightHeaderLast))
+	default:
+		return err
+	}
+	return DBlevel.Write(batch, nil)
+}
@@ -0,0 +1,60 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const peerPrefix = "peer_"
+
+// Peer is the node which has been discovered through the exchange of addresses.
+// The table of peers is stored in leveldb
+type Peer struct {
+	Address  string `json:"address"`
+	Latency  int64  `json:"latency"` // average latency in milliseconds
+	Success  int64  `json:"success"`
+	Failures int64  `json:"failures"` // failures in a row
+	LastSeen int64  `json:"last_seen"`
+	Source   string `json:"source"`   // the address of the peer which has sent this address
+	NodeKey  []byte `json:"node_key"` // the node key which the peer has proved in the handshake
+}
+
+func peerKey(address string) []byte {
+	return []byte(peerPrefix + address)
+}
+
+// Save writes the peer
+func (p *Peer) Save() error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return DBlevel.Put(peerKey(p.Address), data, nil)
+}
+
+// GetPeers returns all saved peers
+func GetPeers() ([]*Peer, error) {
+	iter := DBlevel.NewIterator(util.BytesPrefix([]byte(peerPrefix)), nil)
+	defer iter.Release()
+
+	var list []*Peer
+	for iter.Next() {
+		p := &Peer{}
+		if err := json.Unmarshal(iter.Value(), p); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, iter.Error()
+}
+
+// DeletePeer deletes the peer
+func DeletePeer(address string) error {
+	return DBlevel.Delete(peerKey(address), nil)
+}
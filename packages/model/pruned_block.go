@@ -0,0 +1,54 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// PrunedBlock is the block whose body and rollback data have been deleted. Only the header
+// is kept in block_chain, so the merkle root of transactions is saved here
+type PrunedBlock struct {
+	ID       int64  `gorm:"primary_key;not null"`
+	MrklRoot []byte `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (PrunedBlock) TableName() string {
+	return "pruned_blocks"
+}
+
+// Get is retrieving the pruned block
+func (pb *PrunedBlock) Get(blockID int64) (bool, error) {
+	return isFound(DBConn.Where("id = ?", blockID).First(pb))
+}
+
+// GetPrunedBlockID returns the id of the last pruned block or 0
+func GetPrunedBlockID() (int64, error) {
+	pb := &PrunedBlock{}
+	if _, err := isFound(DBConn.Order("id desc").First(pb)); err != nil {
+		return 0, err
+	}
+	return pb.ID, nil
+}
+
+// PruneBlock replaces the data of the block with its header and deletes the rollback data
+// and the log of transactions of the block except the hashes which must be kept
+func PruneBlock(dbTransaction *DbTransaction, blockID int64, header, mrklRoot []byte, keepHashes [][]byte) error {
+	db := GetDB(dbTransaction)
+	if err := db.Exec("UPDATE block_chain SET data = ? WHERE id = ?", header, blockID).Error; err != nil {
+		return err
+	}
+	if err := db.Exec("DELETE FROM rollback_tx WHERE block_id = ?", blockID).Error; err != nil {
+		return err
+	}
+	var err error
+	if len(keepHashes) > 0 {
+		err = db.Exec("DELETE FROM log_transactions WHERE block = ? AND hash NOT IN (?)", blockID, keepHashes).Error
+	} else {
+		err = db.Exec("DELETE FROM log_transactions WHERE block = ?", blockID).Error
+	}
+	if err != nil {
+		return err
+	}
+	return db.Create(&PrunedBlock{ID: blockID, MrklRoot: mrklRoot}).Error
+}
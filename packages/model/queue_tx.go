@@ -52,7 +52,7 @@ func GetAllUnverifiedAndUnusedTransactions(dbTransaction *DbTransaction, limit i
 			     hash,expedite,time
 		      FROM transactions
 		      WHERE verified = 0 AND used = 0
-			)  AS x ORDER BY expedite DESC,time ASC limit ?`
+			)  AS x ORDER BY ` + feePerByte + ` DESC,time ASC limit ?`
 	var result []*QueueTx
 	err := GetDB(dbTransaction).Raw(query, limit).Scan(&result).Error
 	if err != nil {
@@ -0,0 +1,110 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// localTables are the tables of the node which are not the part of the blockchain state.
+// block_chain and info_block are exported separately
+var localTables = []string{
+	"api_keys",
+	"block_chain",
+	"confirmations",
+	"external_blockchain",
+	"finality_certificates",
+	"fork_blocks",
+	"info_block",
+	"install",
+	"migration_history",
+	"node_votes",
+	"pruned_blocks",
+	"queue_blocks",
+	"queue_tx",
+	"reorgs",
+	"rollback_tx",
+	"snapshots",
+	"stop_daemons",
+	"transactions",
+	"transactions_attempts",
+	"transactions_status",
+}
+
+// IsLocalTable reports whether the table belongs to the node and isn't the part of the blockchain state
+func IsLocalTable(name string) bool {
+	for _, table := range localTables {
+		if table == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot is the exported state of the blockchain at the block
+type Snapshot struct {
+	BlockID int64  `gorm:"primary_key;not null"`
+	Hash    []byte `gorm:"not null"`
+	Size    int64  `gorm:"not null"`
+	Time    int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (Snapshot) TableName() string {
+	return "snapshots"
+}
+
+// Create is creating record of model
+func (s *Snapshot) Create(dbTransaction *DbTransaction) error {
+	return GetDB(dbTransaction).Create(s).Error
+}
+
+// Get is retrieving the snapshot of the block
+func (s *Snapshot) Get(blockID int64) (bool, error) {
+	return isFound(DBConn.Where("block_id = ?", blockID).First(s))
+}
+
+// GetLast is retrieving the last snapshot
+func (s *Snapshot) GetLast() (bool, error) {
+	return isFound(DBConn.Order("block_id desc").First(s))
+}
+
+// GetSnapshotsBefore returns the snapshots which are older than the specified block
+func GetSnapshotsBefore(blockID int64) ([]Snapshot, error) {
+	var list []Snapshot
+	err := DBConn.Where("block_id < ?", blockID).Order("block_id").Find(&list).Error
+	return list, err
+}
+
+// DeleteSnapshot deletes the record of the snapshot
+func DeleteSnapshot(blockID int64) error {
+	return DBConn.Where("block_id = ?", blockID).Delete(&Snapshot{}).Error
+}
+
+// TableColumn is the definition of the table column
+type TableColumn struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	NotNull bool   `json:"not_null"`
+	Default string `json:"default"`
+}
+
+// GetStateTables returns the sorted names of the tables with the blockchain state
+func GetStateTables(dbTransaction *DbTransaction) ([]string, error) {
+	var list []string
+	err := GetDB(dbTransaction).Table("information_schema.tables").
+		Where("table_type = 'BASE TABLE' AND table_schema = 'public' AND table_name NOT IN (?)", localTables).
+		Order(`table_name COLLATE "C"`).Pluck("table_name", &list).Error
+	return list, err
+}
+
+// GetTableColumns returns the columns of the table in the order of their positions
+func GetTableColumns(dbTransaction *DbTransaction, table string) ([]TableColumn, error) {
+	var list []TableColumn
+	err := GetDB(dbTransaction).Raw(`SELECT a.attname AS name, format_type(a.atttypid, a.atttypmod) AS type,
+		a.attnotnull AS not_null, coalesce(pg_get_expr(d.adbin, d.adrelid), '') AS "default"
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, `"`+table+`"`).Scan(&list).Error
+	return list, err
+}
@@ -21,7 +21,7 @@ const (
 	TransactionRateSystemMiner
 	TransactionRateStopNetwork
 )
-const expediteOrder = `high_rate,expedite DESC,time ASC`
+const expediteOrder = `high_rate,` + feePerByte + ` DESC,time ASC`
 
 type transactionRate int8
 
@@ -39,19 +39,23 @@ type Transaction struct {
 	Time     int64           `gorm:"not null"`
 }
 
-// GetAllUnusedTransactions is retrieving all unused transactions
+// GetAllUnusedTransactions is retrieving all unused transactions ordered by fee.
+// The transactions are limited by the fee of the sender so the earlier transactions
+// of each sender are never cut off in favor of its later ones
 func GetAllUnusedTransactions(dbTransaction *DbTransaction, limit int) ([]*Transaction, error) {
 	var transactions []*Transaction
 
-	query := GetDB(dbTransaction).Where("used = ?", "0").Order(expediteOrder)
+	query := `SELECT ` + txColumns + ` FROM (` + senderFeeQuery + `) AS x
+		ORDER BY high_rate, sender_fee DESC, time ASC, hash ASC`
+	args := []interface{}{}
 	if limit > 0 {
-		query = query.Limit(limit)
+		query += ` LIMIT ?`
+		args = append(args, limit)
 	}
-
-	if err := query.Find(&transactions).Error; err != nil {
+	if err := GetDB(dbTransaction).Raw(query, args...).Scan(&transactions).Error; err != nil {
 		return nil, err
 	}
-	return transactions, nil
+	return OrderByFee(transactions), nil
 }
 
 // GetAllUnsentTransactions is retrieving all unset transactions
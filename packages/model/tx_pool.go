@@ -0,0 +1,166 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"bytes"
+	"container/heap"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// feePerByte is sql expression of the expedite fee per byte of the transaction
+const feePerByte = `expedite / GREATEST(octet_length(data), 1)`
+
+const txColumns = `hash, data, used, high_rate, expedite, type, key_id, sent, verified, time`
+
+// senderFeeQuery selects unused transactions with sender_fee which is the lowest fee per byte
+// of the transaction and all earlier transactions of its sender. sender_fee never grows along
+// the transactions of the sender so ordering by it keeps the order of each sender
+const senderFeeQuery = `SELECT ` + txColumns + `, MIN(` + feePerByte + `) OVER
+	(PARTITION BY key_id ORDER BY time ASC, hash ASC) AS sender_fee
+	FROM transactions WHERE used = 0`
+
+// errEvicted is the status of the transaction which has been evicted from the pool
+const errEvicted = `transaction has been evicted from the pool by transactions with higher fee`
+
+// FeePercentiles are the percentiles of the fees which are returned by the api
+var FeePercentiles = []int{10, 25, 50, 75, 90}
+
+// FeePerByte returns the expedite fee per byte of the transaction
+func (t *Transaction) FeePerByte() decimal.Decimal {
+	size := int64(len(t.Data))
+	if size == 0 {
+		size = 1
+	}
+	return t.Expedite.Div(decimal.NewFromInt(size))
+}
+
+// txPriority is the queue of the first pending transactions of the senders
+type txPriority [][]*Transaction
+
+func (q txPriority) Len() int { return len(q) }
+
+func (q txPriority) Less(i, j int) bool {
+	a, b := q[i][0], q[j][0]
+	if a.HighRate != b.HighRate {
+		return a.HighRate < b.HighRate
+	}
+	if cmp := a.FeePerByte().Cmp(b.FeePerByte()); cmp != 0 {
+		return cmp > 0
+	}
+	if a.Time != b.Time {
+		return a.Time < b.Time
+	}
+	return bytes.Compare(a.Hash, b.Hash) < 0
+}
+
+func (q txPriority) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *txPriority) Push(x interface{}) { *q = append(*q, x.([]*Transaction)) }
+
+func (q *txPriority) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// OrderByFee sorts transactions by the expedite fee per byte. The transactions of the same
+// sender keep the order of their time so the cheap transaction of the sender goes ahead of
+// its following expensive transactions
+func OrderByFee(trs []*Transaction) []*Transaction {
+	senders := make(map[int64][]*Transaction)
+	for _, t := range trs {
+		senders[t.KeyID] = append(senders[t.KeyID], t)
+	}
+	queue := make(txPriority, 0, len(senders))
+	for _, list := range senders {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Time < list[j].Time
+		})
+		queue = append(queue, list)
+	}
+	heap.Init(&queue)
+
+	ret := make([]*Transaction, 0, len(trs))
+	for queue.Len() > 0 {
+		list := queue[0]
+		ret = append(ret, list[0])
+		if len(list) > 1 {
+			queue[0] = list[1:]
+			heap.Fix(&queue, 0)
+		} else {
+			heap.Pop(&queue)
+		}
+	}
+	return ret
+}
+
+// GetUnusedTransactionsCount returns the count of transactions which are waiting for a block
+func GetUnusedTransactionsCount() (int64, error) {
+	var count int64
+	err := DBConn.Model(&Transaction{}).Where("used = 0").Count(&count).Error
+	return count, err
+}
+
+// EvictCheapTransactions deletes the unused contract transactions with the lowest fee per byte
+// if the count of unused transactions exceeds maxSize. The transactions are evicted from the tail
+// of each sender and get the error status. Nothing is deleted if maxSize is 0
+func EvictCheapTransactions(dbTransaction *DbTransaction, maxSize int) (int64, error) {
+	if maxSize <= 0 {
+		return 0, nil
+	}
+	var count int64
+	if err := GetDB(dbTransaction).Model(&Transaction{}).Where("used = 0").Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count <= int64(maxSize) {
+		return 0, nil
+	}
+	var evicted []*Transaction
+	err := GetDB(dbTransaction).Raw(`DELETE FROM transactions WHERE hash IN (
+		SELECT hash FROM (`+senderFeeQuery+` AND high_rate = ?) AS x
+		ORDER BY sender_fee ASC, time DESC, hash DESC LIMIT ?) RETURNING hash`,
+		TransactionRateApiContract, count-int64(maxSize)).Scan(&evicted).Error
+	if err != nil || len(evicted) == 0 {
+		return 0, err
+	}
+	hashes := make([][]byte, 0, len(evicted))
+	for _, t := range evicted {
+		hashes = append(hashes, t.Hash)
+	}
+	err = GetDB(dbTransaction).Model(&TransactionStatus{}).Where("hash IN ?", hashes).
+		Update("error", errEvicted).Error
+	return int64(len(evicted)), err
+}
+
+// GetUnusedFees returns the sorted fees per byte of unused transactions
+func GetUnusedFees() ([]decimal.Decimal, error) {
+	var fees []decimal.Decimal
+	err := DBConn.Model(&Transaction{}).Where("used = 0").Order("fee").
+		Pluck(feePerByte+" AS fee", &fees).Error
+	return fees, err
+}
+
+// GetFeePercentiles returns the values of percentiles of the sorted fees by nearest-rank method
+func GetFeePercentiles(fees []decimal.Decimal, percentiles []int) map[int]decimal.Decimal {
+	ret := make(map[int]decimal.Decimal, len(percentiles))
+	if len(fees) == 0 {
+		return ret
+	}
+	for _, p := range percentiles {
+		rank := (p*len(fees) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		} else if rank > len(fees) {
+			rank = len(fees)
+		}
+		ret[p] = fees[rank-1]
+	}
+	return ret
+}
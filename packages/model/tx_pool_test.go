@@ -0,0 +1,52 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderByFee(t *testing.T) {
+	newTx := func(hash string, keyID, time, fee int64) *Transaction {
+		return &Transaction{
+			Hash:     []byte(hash),
+			Data:     make([]byte, 10),
+			HighRate: TransactionRateApiContract,
+			KeyID:    keyID,
+			Time:     time,
+			Expedite: decimal.NewFromInt(fee),
+		}
+	}
+	trs := []*Transaction{
+		newTx("a1", 1, 1, 1),
+		newTx("a2", 1, 2, 100),
+		newTx("b1", 2, 1, 50),
+		newTx("c1", 3, 5, 10),
+		newTx("c2", 3, 3, 20),
+	}
+	var hashes []string
+	for _, t := range OrderByFee(trs) {
+		hashes = append(hashes, string(t.Hash))
+	}
+	assert.Equal(t, []string{"b1", "c2", "c1", "a1", "a2"}, hashes)
+}
+
+func TestGetFeePercentiles(t *testing.T) {
+	var fees []decimal.Decimal
+	for i := int64(1); i <= 10; i++ {
+		fees = append(fees, decimal.NewFromInt(i))
+	}
+	ret := GetFeePercentiles(fees, []int{0, 10, 50, 90, 100})
+	assert.Equal(t, "1", ret[0].String())
+	assert.Equal(t, "1", ret[10].String())
+	assert.Equal(t, "5", ret[50].String())
+	assert.Equal(t, "9", ret[90].String())
+	assert.Equal(t, "10", ret[100].String())
+	assert.Empty(t, GetFeePercentiles(nil, FeePercentiles))
+}
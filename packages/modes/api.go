@@ -4,6 +4,10 @@
 )
 
 func RegisterRoutes() http.Handler {
+	if conf.Config.IsLight() {
+		return api.NewLightRouter().GetAPI()
+	}
+
 	m := api.Mode{
 		EcosysIDValidator:  GetEcosystemIDValidator(),
 		EcosysNameGetter:   BuildEcosystemNameGetter(),
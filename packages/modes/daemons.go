@@ -14,6 +14,9 @@ func (f BlockchainDaemonsListsFactory) GetDaemonsList() []string {
 		"QueueParserBlocks",
 		"Disseminator",
 		"Confirmations",
+		"Snapshots",
+		"Pruning",
+		"PeerDiscovery",
 		"Scheduler",
 		"ExternalNetwork",
 	}
@@ -12,8 +12,11 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/daemons"
+	"github.com/IBAX-io/go-ibax/packages/light"
 	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
 	"github.com/IBAX-io/go-ibax/packages/network/tcpserver"
 	"github.com/IBAX-io/go-ibax/packages/service"
 	"github.com/IBAX-io/go-ibax/packages/smart"
@@ -149,6 +152,19 @@ func (l BCDaemonLoader) Load(ctx context.Context) error {
 		return err
 	}
 
+	// leveldb can be opened by the pool before
+	if !model.GLeveldbIsactive {
+		if err := model.Init_leveldb(conf.Config.Peers.Path); err != nil {
+			l.logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": conf.Config.Peers.Path}).Error("opening leveldb")
+			return err
+		}
+	}
+	if err := service.InitPeersService(); err != nil {
+		l.logger.WithError(err).Error("Can't init peers service")
+		return err
+	}
+	service.GetPeersService().Add("", conf.GetNodesAddr())
+
 	return nil
 }
 
@@ -240,7 +256,37 @@ func (l OBSDaemonLoader) Load(ctx context.Context) error {
 	return nil
 }
 
+// LightDaemonLoader allows load the light mode
+type LightDaemonLoader struct {
+	logger *log.Entry
+}
+
+// Load opens the storage of headers and starts the sync of headers
+func (l LightDaemonLoader) Load(ctx context.Context) error {
+	if err := model.Init_leveldb(conf.Config.Light.Path); err != nil {
+		l.logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": conf.Config.Light.Path}).Error("opening leveldb")
+		return err
+	}
+	if err := syspar.SetParams(map[string]string{
+		syspar.HonorNodes:   conf.Config.Light.HonorNodes,
+		syspar.MaxBlockSize: converter.Int64ToStr(network.MaxBlockSize),
+	}); err != nil {
+		l.logger.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("setting honor nodes")
+		return err
+	}
+	logMode(l.logger, conf.Config.OBSMode)
+
+	go light.Sync(ctx)
+	return nil
+}
+
 func GetDaemonLoader() types.DaemonLoader {
+	if conf.Config.IsLight() {
+		return LightDaemonLoader{
+			logger: log.WithFields(log.Fields{"loader": "light_daemon_loader"}),
+		}
+	}
+
 	if conf.Config.IsSupportingOBS() {
 		return OBSDaemonLoader{
 			logger:            log.WithFields(log.Fields{"loader": "obs_daemon_loader"}),
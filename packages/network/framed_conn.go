@@ -0,0 +1,487 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// streamWindow is the count of bytes which the side can send to the stream before
+	// the receiver has read them. The reading loop never waits for a slow stream
+	streamWindow = 4 * MaxFramePayload
+	// maxStreams is the max count of the streams which the remote side can open at the same time,
+	// the following streams are reset
+	maxStreams = 32
+)
+
+var (
+	ErrConnClosed    = errors.New("Connection is closed")
+	ErrStreamReset   = errors.New("Stream is reset")
+	ErrStreamWindow  = errors.New("Stream window is exceeded")
+	ErrStreamTimeout = &timeoutError{}
+)
+
+// timeoutError implements net.Error so the callers can check Timeout()
+type timeoutError struct{}
+
+func (*timeoutError) Error() string   { return "Stream deadline is exceeded" }
+func (*timeoutError) Timeout() bool   { return true }
+func (*timeoutError) Temporary() bool { return true }
+
+// StreamHandler processes the stream which has been opened by the remote side
+type StreamHandler func(reqType ReqTypesFlag, s *Stream)
+
+// FramedConn multiplexes several request streams over one connection.
+// Each stream is identified by the request id of the frame
+type FramedConn struct {
+	conn      net.Conn
+	handler   StreamHandler
+	remoteKey []byte
+
+	wmu     sync.Mutex
+	mu      sync.Mutex
+	streams map[uint32]*Stream
+	nextID  uint32
+	// lastRemoteID is the id of the last stream which has been opened by the remote side
+	lastRemoteID uint32
+	err          error
+	closed       chan struct{}
+}
+
+// NewFramedConn returns the multiplexer over conn. The streams opened by the remote side
+// are passed to handler, if handler is nil they are refused. remoteKey is the node key
+// which the remote side has proved by the handshake
+func NewFramedConn(conn net.Conn, remoteKey []byte, handler StreamHandler) *FramedConn {
+	return &FramedConn{
+		conn:      conn,
+		handler:   handler,
+		remoteKey: remoteKey,
+		streams:   make(map[uint32]*Stream),
+		closed:    make(chan struct{}),
+	}
+}
+
+// RemoteKey returns the verified node key of the remote side
+func (c *FramedConn) RemoteKey() []byte {
+	return c.remoteKey
+}
+
+// Serve reads frames until the connection is closed
+func (c *FramedConn) Serve() error {
+	for {
+		f := &Frame{}
+		if err := f.Read(c.conn); err != nil {
+			c.shutdown(err)
+			return err
+		}
+		c.dispatch(f)
+	}
+}
+
+func (c *FramedConn) dispatch(f *Frame) {
+	c.mu.Lock()
+	s, ok := c.streams[f.RequestID]
+	if !ok {
+		// the remote side opens the streams with increasing ids by the empty frame,
+		// the frames of the released and refused streams are skipped
+		if f.RequestID <= c.lastRemoteID || f.Flags != 0 {
+			c.mu.Unlock()
+			return
+		}
+		c.lastRemoteID = f.RequestID
+		if c.handler == nil || len(c.streams) >= maxStreams {
+			c.mu.Unlock()
+			c.writeFrame(&Frame{RequestID: f.RequestID, Type: f.Type, Flags: FrameFlagReset})
+			return
+		}
+		s = newStream(c, f.RequestID, f.Type)
+		c.streams[f.RequestID] = s
+		go func() {
+			c.handler(f.Type, s)
+			s.CloseWrite()
+		}()
+	}
+	c.mu.Unlock()
+
+	switch {
+	case f.Flags&FrameFlagReset != 0:
+		s.remoteReset()
+	case f.Flags&FrameFlagWindow != 0:
+		if len(f.Payload) == 4 {
+			s.addCredit(int(binary.LittleEndian.Uint32(f.Payload)))
+		}
+	default:
+		if !s.push(f.Payload) {
+			// the remote side ignores the window, only this stream is dropped
+			s.Reset()
+			return
+		}
+		if f.Flags&FrameFlagEnd != 0 {
+			s.remoteEnd()
+		}
+	}
+}
+
+// OpenStream opens a new stream for the request type. The stream is announced by the empty frame
+// which is written under the write lock, so the remote side gets the ids in the increasing order
+func (c *FramedConn) OpenStream(reqType ReqTypesFlag) (*Stream, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	s := newStream(c, c.nextID, reqType)
+	c.streams[s.id] = s
+	c.mu.Unlock()
+
+	if err := (&Frame{RequestID: s.id, Type: reqType}).Write(c.conn); err != nil {
+		c.release(s)
+		return nil, err
+	}
+	return s, nil
+}
+
+// Done returns the channel which is closed when the connection is closed
+func (c *FramedConn) Done() <-chan struct{} {
+	return c.closed
+}
+
+// Err returns the error which has closed the connection
+func (c *FramedConn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection and all streams
+func (c *FramedConn) Close() error {
+	c.shutdown(ErrConnClosed)
+	return c.conn.Close()
+}
+
+func (c *FramedConn) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.closed)
+	c.streams = make(map[uint32]*Stream)
+}
+
+func (c *FramedConn) writeFrame(f *Frame) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return f.Write(c.conn)
+}
+
+func (c *FramedConn) release(s *Stream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.streams[s.id] == s {
+		delete(c.streams, s.id)
+	}
+}
+
+// Stream is the request inside FramedConn. It implements net.Conn so request handlers
+// can work with it as with the legacy connection. Each side can send at most streamWindow
+// bytes which the other side hasn't read yet
+type Stream struct {
+	id      uint32
+	reqType ReqTypesFlag
+	conn    *FramedConn
+
+	mu            sync.Mutex
+	queue         [][]byte
+	queued        int
+	consumed      int
+	credit        int
+	readDeadline  time.Time
+	writeDeadline time.Time
+	localEnded    bool
+	remoteEnded   bool
+	reset         bool
+	readReady     chan struct{}
+	writeReady    chan struct{}
+}
+
+func newStream(c *FramedConn, id uint32, reqType ReqTypesFlag) *Stream {
+	return &Stream{
+		id:         id,
+		reqType:    reqType,
+		conn:       c,
+		credit:     streamWindow,
+		readReady:  make(chan struct{}, 1),
+		writeReady: make(chan struct{}, 1),
+	}
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// deadlineTimer returns the channel which fires at the deadline, zero deadline never fires
+func deadlineTimer(deadline time.Time) (<-chan time.Time, func() bool) {
+	if deadline.IsZero() {
+		return nil, func() bool { return false }
+	}
+	t := time.NewTimer(time.Until(deadline))
+	return t.C, t.Stop
+}
+
+// ID returns the request id of the stream
+func (s *Stream) ID() uint32 {
+	return s.id
+}
+
+// RemoteKey returns the verified node key of the remote side
+func (s *Stream) RemoteKey() []byte {
+	return s.conn.remoteKey
+}
+
+// push is called only from the reading loop of FramedConn, it never blocks
+func (s *Stream) push(data []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remoteEnded || len(data) == 0 {
+		return true
+	}
+	if s.queued+len(data) > streamWindow {
+		return false
+	}
+	s.queue = append(s.queue, data)
+	s.queued += len(data)
+	notify(s.readReady)
+	return true
+}
+
+func (s *Stream) Read(p []byte) (int, error) {
+	for {
+		s.mu.Lock()
+		if s.reset {
+			s.mu.Unlock()
+			return 0, ErrStreamReset
+		}
+		if len(s.queue) > 0 {
+			n := copy(p, s.queue[0])
+			if s.queue[0] = s.queue[0][n:]; len(s.queue[0]) == 0 {
+				s.queue = s.queue[1:]
+			}
+			s.queued -= n
+			s.consumed += n
+			var window int
+			if s.consumed >= streamWindow/2 && !s.remoteEnded {
+				window, s.consumed = s.consumed, 0
+			}
+			s.mu.Unlock()
+			if window > 0 {
+				s.sendWindow(window)
+			}
+			return n, nil
+		}
+		if s.remoteEnded {
+			s.mu.Unlock()
+			return 0, io.EOF
+		}
+		timer, stop := deadlineTimer(s.readDeadline)
+		s.mu.Unlock()
+
+		select {
+		case <-s.readReady:
+			stop()
+		case <-s.conn.closed:
+			stop()
+			return 0, io.ErrUnexpectedEOF
+		case <-timer:
+			return 0, ErrStreamTimeout
+		}
+	}
+}
+
+func (s *Stream) sendWindow(size int) {
+	payload := make([]byte, 4)
+	binary.LittleEndian.PutUint32(payload, uint32(size))
+	s.conn.writeFrame(&Frame{RequestID: s.id, Type: s.reqType, Flags: FrameFlagWindow, Payload: payload})
+}
+
+func (s *Stream) addCredit(size int) {
+	s.mu.Lock()
+	s.credit += size
+	s.mu.Unlock()
+	notify(s.writeReady)
+}
+
+// reserve waits until the remote side allows to send data
+func (s *Stream) reserve(size int) (int, error) {
+	for {
+		s.mu.Lock()
+		if s.reset {
+			s.mu.Unlock()
+			return 0, ErrStreamReset
+		}
+		if s.localEnded {
+			s.mu.Unlock()
+			return 0, ErrConnClosed
+		}
+		if s.credit > 0 {
+			if size > s.credit {
+				size = s.credit
+			}
+			s.credit -= size
+			s.mu.Unlock()
+			return size, nil
+		}
+		timer, stop := deadlineTimer(s.writeDeadline)
+		s.mu.Unlock()
+
+		select {
+		case <-s.writeReady:
+			stop()
+		case <-s.conn.closed:
+			stop()
+			return 0, ErrConnClosed
+		case <-timer:
+			return 0, ErrStreamTimeout
+		}
+	}
+}
+
+func (s *Stream) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		size := len(p)
+		if size > MaxFramePayload {
+			size = MaxFramePayload
+		}
+		size, err := s.reserve(size)
+		if err != nil {
+			return n, err
+		}
+		if err = s.conn.writeFrame(&Frame{RequestID: s.id, Type: s.reqType, Payload: p[:size]}); err != nil {
+			return n, err
+		}
+		n += size
+		p = p[size:]
+	}
+	return n, nil
+}
+
+// CloseWrite finishes writing to the stream, the response can be read after it
+func (s *Stream) CloseWrite() error {
+	s.mu.Lock()
+	if s.localEnded || s.reset {
+		s.mu.Unlock()
+		return nil
+	}
+	s.localEnded = true
+	release := s.remoteEnded
+	s.mu.Unlock()
+
+	if release {
+		s.conn.release(s)
+	}
+	notify(s.writeReady)
+	return s.conn.writeFrame(&Frame{RequestID: s.id, Type: s.reqType, Flags: FrameFlagEnd})
+}
+
+// Close closes the stream as the connection. If the remote side hasn't finished
+// writing the stream is reset so the remote side stops sending
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	ended := s.remoteEnded
+	s.mu.Unlock()
+	if !ended {
+		return s.Reset()
+	}
+	return s.CloseWrite()
+}
+
+// Reset aborts the stream on both sides, other streams of the connection are not affected
+func (s *Stream) Reset() error {
+	s.mu.Lock()
+	if s.reset || s.localEnded && s.remoteEnded {
+		s.mu.Unlock()
+		return nil
+	}
+	s.reset = true
+	s.mu.Unlock()
+
+	s.conn.release(s)
+	notify(s.readReady)
+	notify(s.writeReady)
+	return s.conn.writeFrame(&Frame{RequestID: s.id, Type: s.reqType, Flags: FrameFlagReset})
+}
+
+// remoteReset is called only from the reading loop of FramedConn
+func (s *Stream) remoteReset() {
+	s.mu.Lock()
+	s.reset = true
+	s.mu.Unlock()
+
+	s.conn.release(s)
+	notify(s.readReady)
+	notify(s.writeReady)
+}
+
+// remoteEnd is called only from the reading loop of FramedConn
+func (s *Stream) remoteEnd() {
+	s.mu.Lock()
+	if s.remoteEnded {
+		s.mu.Unlock()
+		return
+	}
+	s.remoteEnded = true
+	release := s.localEnded
+	s.mu.Unlock()
+
+	if release {
+		s.conn.release(s)
+	}
+	notify(s.readReady)
+}
+
+func (s *Stream) LocalAddr() net.Addr {
+	return s.conn.conn.LocalAddr()
+}
+
+func (s *Stream) RemoteAddr() net.Addr {
+	return s.conn.conn.RemoteAddr()
+}
+
+// SetDeadline sets the deadlines of reading and writing of the stream
+func (s *Stream) SetDeadline(t time.Time) error {
+	s.SetReadDeadline(t)
+	return s.SetWriteDeadline(t)
+}
+
+func (s *Stream) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	s.readDeadline = t
+	s.mu.Unlock()
+	notify(s.readReady)
+	return nil
+}
+
+func (s *Stream) SetWriteDeadline(t time.Time) error {
+	s.mu.Lock()
+	s.writeDeadline = t
+	s.mu.Unlock()
+	notify(s.writeReady)
+	return nil
+}
@@ -0,0 +1,248 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrame(t *testing.T) {
+	w := new(bytes.Buffer)
+	f := &Frame{RequestID: 7, Type: RequestTypeMaxBlock, Flags: FrameFlagEnd, Payload: []byte("data")}
+	require.NoError(t, f.Write(w))
+
+	res := &Frame{}
+	require.NoError(t, res.Read(w))
+	require.Equal(t, f, res)
+}
+
+func TestFramedConn(t *testing.T) {
+	client, server := net.Pipe()
+	big := bytes.Repeat([]byte{1}, 2*MaxFramePayload+10)
+
+	srv := NewFramedConn(server, nil, func(reqType ReqTypesFlag, s *Stream) {
+		req := make([]byte, 5)
+		if _, err := io.ReadFull(s, req); err != nil {
+			return
+		}
+		s.Write(append([]byte{byte(reqType)}, req...))
+		s.Write(big)
+	})
+	go srv.Serve()
+	cl := NewFramedConn(client, nil, nil)
+	go cl.Serve()
+	defer cl.Close()
+
+	// the goroutines send the errors back, the test fails only in the test goroutine
+	errs := make(chan error, 10)
+	for i := 1; i <= 10; i++ {
+		go func(reqType ReqTypesFlag) {
+			errs <- func() error {
+				s, err := cl.OpenStream(reqType)
+				if err != nil {
+					return err
+				}
+				defer s.Close()
+				if _, err = s.Write([]byte("hello")); err != nil {
+					return err
+				}
+				head := make([]byte, 6)
+				if _, err = io.ReadFull(s, head); err != nil {
+					return err
+				}
+				if !bytes.Equal(append([]byte{byte(reqType)}, "hello"...), head) {
+					return fmt.Errorf("wrong head %v of stream %d", head, reqType)
+				}
+				rest, err := io.ReadAll(s)
+				if err != nil {
+					return err
+				}
+				if len(rest) != len(big) {
+					return fmt.Errorf("wrong size %d of stream %d", len(rest), reqType)
+				}
+				return nil
+			}()
+		}(ReqTypesFlag(i))
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(t, <-errs)
+	}
+}
+
+func TestFramedConnRemoteStreams(t *testing.T) {
+	client, server := net.Pipe()
+	opened := make(chan uint32, 2*maxStreams)
+	srv := NewFramedConn(server, nil, func(reqType ReqTypesFlag, s *Stream) {
+		opened <- s.ID()
+		io.ReadAll(s)
+	})
+	go srv.Serve()
+	defer srv.Close()
+
+	resets := make(chan uint32, 2*maxStreams)
+	go func() {
+		for {
+			f := &Frame{}
+			if err := f.Read(client); err != nil {
+				return
+			}
+			if f.Flags&FrameFlagReset != 0 {
+				resets <- f.RequestID
+			}
+		}
+	}()
+	send := func(f *Frame) {
+		require.NoError(t, f.Write(client))
+	}
+	waitOpened := func(id uint32) {
+		select {
+		case got := <-opened:
+			require.Equal(t, id, got)
+		case <-time.After(time.Second):
+			t.Fatalf("stream %d isn't opened", id)
+		}
+	}
+
+	send(&Frame{RequestID: 2, Type: 1})
+	waitOpened(2)
+	// the ids must increase, the frames of the released streams don't open new ones
+	send(&Frame{RequestID: 1, Type: 1, Payload: []byte("data")})
+	send(&Frame{RequestID: 2, Type: 1, Flags: FrameFlagEnd})
+	send(&Frame{RequestID: 2, Type: 1, Payload: []byte("late")})
+	send(&Frame{RequestID: 3, Type: 1, Flags: FrameFlagEnd})
+
+	for id := uint32(4); id < 4+maxStreams; id++ {
+		send(&Frame{RequestID: id, Type: 1})
+		waitOpened(id)
+	}
+	send(&Frame{RequestID: 4 + maxStreams, Type: 1})
+	select {
+	case id := <-resets:
+		require.Equal(t, uint32(4+maxStreams), id)
+	case <-time.After(time.Second):
+		t.Fatal("stream over the limit isn't reset")
+	}
+	require.Empty(t, opened)
+}
+
+func newFramedPair(handler StreamHandler) (*FramedConn, func()) {
+	client, server := net.Pipe()
+	srv := NewFramedConn(server, nil, handler)
+	go srv.Serve()
+	cl := NewFramedConn(client, nil, nil)
+	go cl.Serve()
+	return cl, func() {
+		cl.Close()
+		srv.Close()
+	}
+}
+
+func TestFramedConnSlowStream(t *testing.T) {
+	big := bytes.Repeat([]byte{1}, 3*streamWindow)
+	cl, closeConn := newFramedPair(func(reqType ReqTypesFlag, s *Stream) {
+		if reqType == 1 {
+			s.Write(big)
+			return
+		}
+		s.Write([]byte("fast"))
+	})
+	defer closeConn()
+
+	// the first stream isn't read, it must not block other streams
+	slow, err := cl.OpenStream(1)
+	require.NoError(t, err)
+	_, err = slow.Write([]byte{0})
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		s, err := cl.OpenStream(2)
+		require.NoError(t, err)
+		_, err = s.Write([]byte{0})
+		require.NoError(t, err)
+		data, err := io.ReadAll(s)
+		require.NoError(t, err)
+		require.Equal(t, "fast", string(data))
+		s.Close()
+	}
+
+	data, err := io.ReadAll(slow)
+	require.NoError(t, err)
+	require.Equal(t, len(big), len(data))
+}
+
+func TestStreamDeadlineAndReset(t *testing.T) {
+	written := make(chan error, 1)
+	cl, closeConn := newFramedPair(func(reqType ReqTypesFlag, s *Stream) {
+		if reqType == 1 {
+			// never answers
+			io.ReadAll(s)
+			return
+		}
+		_, err := s.Write(bytes.Repeat([]byte{1}, 2*streamWindow))
+		written <- err
+	})
+	defer closeConn()
+
+	s, err := cl.OpenStream(1)
+	require.NoError(t, err)
+	s.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	_, err = s.Read(make([]byte, 1))
+	nerr, ok := err.(net.Error)
+	require.True(t, ok)
+	require.True(t, nerr.Timeout())
+	require.NoError(t, s.Reset())
+
+	// the reader closes the stream before the end, the writer is released by the reset
+	s, err = cl.OpenStream(2)
+	require.NoError(t, err)
+	_, err = s.Write([]byte{0})
+	require.NoError(t, err)
+	_, err = io.ReadFull(s, make([]byte, 10))
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+	select {
+	case err = <-written:
+		require.Equal(t, ErrStreamReset, err)
+	case <-time.After(time.Second):
+		t.Fatal("writer is blocked")
+	}
+	require.NoError(t, cl.Err())
+}
+
+func TestHandshakeProof(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	priv, pub, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	local := &Handshake{NetworkID: 1, Version: ProtocolVersion, Nonce: bytes.Repeat([]byte{1}, handshakeNonceSize)}
+	remote := &Handshake{NetworkID: 1, Version: ProtocolVersion, NodeKey: pub, Nonce: bytes.Repeat([]byte{2}, handshakeNonceSize)}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, remote.Write(buf))
+	read := &Handshake{}
+	require.NoError(t, read.Read(buf))
+	require.Equal(t, remote, read)
+
+	require.NoError(t, remote.WriteProof(buf, local, priv))
+	require.NoError(t, local.ReadProof(buf, remote))
+
+	// the proof of another nonce is rejected
+	require.NoError(t, remote.WriteProof(buf, remote, priv))
+	require.Equal(t, ErrHandshakeSign, local.ReadProof(buf, remote))
+
+	_, otherPub, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	require.NoError(t, remote.WriteProof(buf, local, priv))
+	require.Equal(t, ErrHandshakeSign, local.ReadProof(buf, &Handshake{NodeKey: otherPub}))
+}
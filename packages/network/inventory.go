@@ -0,0 +1,58 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import "sync"
+
+// HashCache is the bounded set of hashes, the oldest hash is evicted when the cache is full
+type HashCache struct {
+	mutex  sync.Mutex
+	hashes map[string]struct{}
+	ring   []string
+	next   int
+}
+
+// NewHashCache returns the cache which keeps up to size hashes
+func NewHashCache(size int) *HashCache {
+	return &HashCache{
+		hashes: make(map[string]struct{}, size),
+		ring:   make([]string, size),
+	}
+}
+
+// Add adds the hash and reports whether it hasn't been in the cache
+func (c *HashCache) Add(hash []byte) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := string(hash)
+	if _, ok := c.hashes[key]; ok {
+		return false
+	}
+	if old := c.ring[c.next]; len(old) > 0 {
+		delete(c.hashes, old)
+	}
+	c.ring[c.next] = key
+	c.next = (c.next + 1) % len(c.ring)
+	c.hashes[key] = struct{}{}
+	return true
+}
+
+// Has reports whether the hash is in the cache
+func (c *HashCache) Has(hash []byte) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, ok := c.hashes[string(hash)]
+	return ok
+}
+
+// Remove removes the hash. The hash which is added again can be evicted earlier than others
+func (c *HashCache) Remove(hash []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.hashes, string(hash))
+}
@@ -0,0 +1,28 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashCache(t *testing.T) {
+	c := NewHashCache(2)
+	require.True(t, c.Add([]byte("a")))
+	require.False(t, c.Add([]byte("a")))
+	require.True(t, c.Add([]byte("b")))
+
+	// the oldest hash is evicted
+	require.True(t, c.Add([]byte("c")))
+	require.False(t, c.Has([]byte("a")))
+	require.True(t, c.Has([]byte("b")))
+	require.True(t, c.Has([]byte("c")))
+
+	c.Remove([]byte("c"))
+	require.False(t, c.Has([]byte("c")))
+	require.True(t, c.Add([]byte("c")))
+}
@@ -35,12 +35,30 @@ const (
 	RequestTypeSendSubNodeSrcData
 	RequestTypeSendSubNodeSrcDataAgent
 	RequestTypeSendSubNodeAgentData
+	RequestTypeVote
+	RequestTypeSnapshot
+	RequestTypeHeaders
+	RequestTypePeers
+	RequestTypeTxInventory
 
 	// BlocksPerRequest contains count of blocks per request
 	//BlocksPerRequest int32 = 1000
 	BlocksPerRequest int = 100
 
 	MaxBlockSize = 10485760
+
+	// HeadersPerRequest contains max count of headers per request
+	HeadersPerRequest int64 = 1000
+
+	maxHeaderSize = 1024
+
+	// MaxPeersPerRequest contains max count of addresses which are exchanged by one request
+	MaxPeersPerRequest int64 = 100
+
+	maxPeerAddressSize = 255
+
+	// MaxInventoryHashes contains max count of transaction hashes which are announced by one request
+	MaxInventoryHashes int64 = 10000
 )
 
 var ErrNotAccepted = errors.New("Not accepted")
@@ -167,6 +185,369 @@ func (resp *ConfirmResponse) Write(w io.Writer) error {
 	return nil
 }
 
+// VoteRequest contains the block id which the signed vote is requested for
+type VoteRequest struct {
+	BlockID int64
+}
+
+func (req *VoteRequest) Read(r io.Reader) error {
+	return binary.Read(r, binary.LittleEndian, &req.BlockID)
+}
+
+func (req *VoteRequest) Write(w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, req.BlockID)
+}
+
+// VoteResponse is the block hash signed by the key of honor node.
+// The fields are empty if the node doesn't have the block or it isn't honor node
+type VoteResponse struct {
+	Hash    []byte
+	NodeKey []byte
+	Sign    []byte
+}
+
+func (resp *VoteResponse) Read(r io.Reader) error {
+	var err error
+	if resp.Hash, err = ReadSliceWithMaxSize(r, consts.HashSize); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading VoteResponse hash")
+		return err
+	}
+	if resp.NodeKey, err = ReadSliceWithMaxSize(r, maxNodeKeySize); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading VoteResponse node key")
+		return err
+	}
+	if resp.Sign, err = ReadSliceWithMaxSize(r, maxNodeKeySize); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading VoteResponse sign")
+		return err
+	}
+	return nil
+}
+
+func (resp *VoteResponse) Write(w io.Writer) error {
+	for _, slice := range [][]byte{resp.Hash, resp.NodeKey, resp.Sign} {
+		if err := writeSlice(w, slice); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending VoteResponse")
+			return err
+		}
+	}
+	return nil
+}
+
+// SnapshotHashChunk is the index of the chunk which requests only the hash of the snapshot
+// signed by the key of honor node
+const SnapshotHashChunk int64 = -1
+
+// SnapshotRequest contains the block id of the snapshot and the index of the requested chunk.
+// The last snapshot is requested if BlockID is 0
+type SnapshotRequest struct {
+	BlockID int64
+	Chunk   int64
+}
+
+func (req *SnapshotRequest) Read(r io.Reader) error {
+	if err := binary.Read(r, binary.LittleEndian, &req.BlockID); err != nil {
+		return err
+	}
+	return binary.Read(r, binary.LittleEndian, &req.Chunk)
+}
+
+func (req *SnapshotRequest) Write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, req.BlockID); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, req.Chunk)
+}
+
+// SnapshotResponse contains the chunk of the snapshot and the published hash of the whole snapshot.
+// BlockID is 0 if the node doesn't have the snapshot. NodeKey and Sign are filled by honor nodes
+// for SnapshotHashChunk
+type SnapshotResponse struct {
+	BlockID int64
+	Size    int64
+	Hash    []byte
+	NodeKey []byte
+	Sign    []byte
+	Data    []byte
+}
+
+func (resp *SnapshotResponse) Read(r io.Reader) error {
+	var err error
+	if err = binary.Read(r, binary.LittleEndian, &resp.BlockID); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading SnapshotResponse block id")
+		return err
+	}
+	if err = binary.Read(r, binary.LittleEndian, &resp.Size); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading SnapshotResponse size")
+		return err
+	}
+	if resp.Hash, err = ReadSliceWithMaxSize(r, consts.HashSize); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading SnapshotResponse hash")
+		return err
+	}
+	if resp.NodeKey, err = ReadSliceWithMaxSize(r, maxNodeKeySize); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading SnapshotResponse node key")
+		return err
+	}
+	if resp.Sign, err = ReadSliceWithMaxSize(r, maxNodeKeySize); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading SnapshotResponse sign")
+		return err
+	}
+	if resp.Data, err = ReadSliceWithMaxSize(r, consts.SnapshotChunkSize); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading SnapshotResponse data")
+		return err
+	}
+	return nil
+}
+
+func (resp *SnapshotResponse) Write(w io.Writer) error {
+	for _, v := range []int64{resp.BlockID, resp.Size} {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending SnapshotResponse")
+			return err
+		}
+	}
+	for _, slice := range [][]byte{resp.Hash, resp.NodeKey, resp.Sign, resp.Data} {
+		if err := writeSlice(w, slice); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending SnapshotResponse")
+			return err
+		}
+	}
+	return nil
+}
+
+// HeadersRequest contains the id of the first block and the count of requested headers
+type HeadersRequest struct {
+	BlockID int64
+	Count   int64
+}
+
+func (req *HeadersRequest) Read(r io.Reader) error {
+	if err := binary.Read(r, binary.LittleEndian, &req.BlockID); err != nil {
+		return err
+	}
+	return binary.Read(r, binary.LittleEndian, &req.Count)
+}
+
+func (req *HeadersRequest) Write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, req.BlockID); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, req.Count)
+}
+
+// BlockHeader is the serialized header of the block with the merkle root of its transactions
+// and the rollbacks hash which is calculated by the node after playing the block
+type BlockHeader struct {
+	Data          []byte
+	MrklRoot      []byte
+	RollbacksHash []byte
+}
+
+// HeadersResponse contains the headers of blocks in the order of block ids
+type HeadersResponse struct {
+	Headers []*BlockHeader
+}
+
+func (resp *HeadersResponse) Read(r io.Reader) error {
+	var count int64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading HeadersResponse count")
+		return err
+	}
+	if count < 0 || count > HeadersPerRequest {
+		return ErrMaxSize
+	}
+	resp.Headers = make([]*BlockHeader, 0, count)
+	for i := int64(0); i < count; i++ {
+		var err error
+		h := &BlockHeader{}
+		if h.Data, err = ReadSliceWithMaxSize(r, maxHeaderSize); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading HeadersResponse header")
+			return err
+		}
+		if h.MrklRoot, err = ReadSliceWithMaxSize(r, 2*consts.HashSize); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading HeadersResponse merkle root")
+			return err
+		}
+		if h.RollbacksHash, err = ReadSliceWithMaxSize(r, consts.HashSize); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading HeadersResponse rollbacks hash")
+			return err
+		}
+		resp.Headers = append(resp.Headers, h)
+	}
+	return nil
+}
+
+func (resp *HeadersResponse) Write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, int64(len(resp.Headers))); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending HeadersResponse")
+		return err
+	}
+	for _, h := range resp.Headers {
+		for _, slice := range [][]byte{h.Data, h.MrklRoot, h.RollbacksHash} {
+			if err := writeSlice(w, slice); err != nil {
+				log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending HeadersResponse")
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PeersRequest contains the addresses of peers which are known by the requesting node,
+// the first address is the address of the node if it accepts connections
+type PeersRequest struct {
+	Addresses []string
+}
+
+func (req *PeersRequest) Read(r io.Reader) (err error) {
+	req.Addresses, err = readAddresses(r)
+	return
+}
+
+func (req *PeersRequest) Write(w io.Writer) error {
+	return writeAddresses(w, req.Addresses)
+}
+
+// PeersResponse contains the addresses of peers which are known by the responding node
+type PeersResponse struct {
+	Addresses []string
+}
+
+func (resp *PeersResponse) Read(r io.Reader) (err error) {
+	resp.Addresses, err = readAddresses(r)
+	return
+}
+
+func (resp *PeersResponse) Write(w io.Writer) error {
+	return writeAddresses(w, resp.Addresses)
+}
+
+func readAddresses(r io.Reader) ([]string, error) {
+	var count int64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading count of peers")
+		return nil, err
+	}
+	if count < 0 || count > MaxPeersPerRequest {
+		return nil, ErrMaxSize
+	}
+	addresses := make([]string, 0, count)
+	for i := int64(0); i < count; i++ {
+		addr, err := ReadSliceWithMaxSize(r, maxPeerAddressSize)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading peer address")
+			return nil, err
+		}
+		addresses = append(addresses, string(addr))
+	}
+	return addresses, nil
+}
+
+func writeAddresses(w io.Writer, addresses []string) error {
+	if int64(len(addresses)) > MaxPeersPerRequest {
+		addresses = addresses[:MaxPeersPerRequest]
+	}
+	if err := binary.Write(w, binary.LittleEndian, int64(len(addresses))); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending count of peers")
+		return err
+	}
+	for _, addr := range addresses {
+		if err := writeSlice(w, []byte(addr)); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending peer address")
+			return err
+		}
+	}
+	return nil
+}
+
+// TxInventory contains the hashes of transactions which are announced by the node
+// or requested by the peer which doesn't have them
+type TxInventory struct {
+	Hashes [][]byte
+}
+
+func (inv *TxInventory) Read(r io.Reader) error {
+	var count int64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading count of inventory hashes")
+		return err
+	}
+	if count < 0 || count > MaxInventoryHashes {
+		return ErrMaxSize
+	}
+	data := make([]byte, count*consts.HashSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading inventory hashes")
+		return err
+	}
+	inv.Hashes = make([][]byte, 0, count)
+	for len(data) > 0 {
+		inv.Hashes = append(inv.Hashes, data[:consts.HashSize])
+		data = data[consts.HashSize:]
+	}
+	return nil
+}
+
+func (inv *TxInventory) Write(w io.Writer) error {
+	if int64(len(inv.Hashes)) > MaxInventoryHashes {
+		return ErrMaxSize
+	}
+	if err := binary.Write(w, binary.LittleEndian, int64(len(inv.Hashes))); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending count of inventory hashes")
+		return err
+	}
+	data := make([]byte, 0, len(inv.Hashes)*consts.HashSize)
+	for _, hash := range inv.Hashes {
+		if len(hash) != consts.HashSize {
+			return fmt.Errorf("wrong size of transaction hash %d", len(hash))
+		}
+		data = append(data, hash...)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// TxBodies contains the transactions which have been requested by the peer
+type TxBodies struct {
+	Data [][]byte
+}
+
+func (b *TxBodies) Read(r io.Reader) error {
+	var count int64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading count of transactions")
+		return err
+	}
+	if count < 0 || count > MaxInventoryHashes {
+		return ErrMaxSize
+	}
+	b.Data = make([][]byte, 0, count)
+	for i := int64(0); i < count; i++ {
+		data, err := ReadSliceWithMaxSize(r, uint64(syspar.GetMaxTxSize()))
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading transaction")
+			return err
+		}
+		b.Data = append(b.Data, data)
+	}
+	return nil
+}
+
+func (b *TxBodies) Write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, int64(len(b.Data))); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending count of transactions")
+		return err
+	}
+	for _, data := range b.Data {
+		if err := writeSlice(w, data); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending transaction")
+			return err
+		}
+	}
+	return nil
+}
+
 // DisRequest contains request data
 type DisRequest struct {
 	Data []byte
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -64,6 +66,105 @@ func TestGetBodyResponse(t *testing.T) {
 
 }
 
+func TestVoteResponse(t *testing.T) {
+	resp := VoteResponse{
+		Hash:    []byte(strings.Repeat("H", 32)),
+		NodeKey: []byte(strings.Repeat("K", 64)),
+		Sign:    []byte(strings.Repeat("S", 70)),
+	}
+	b := bytes.NewBuffer([]byte{})
+	require.NoError(t, resp.Write(b))
+
+	result := VoteResponse{}
+	require.NoError(t, result.Read(b))
+	require.Equal(t, resp, result)
+
+	empty := VoteResponse{}
+	b.Reset()
+	require.NoError(t, empty.Write(b))
+	require.NoError(t, result.Read(b))
+	require.Empty(t, result.Hash)
+	require.Empty(t, result.Sign)
+}
+
+func TestSnapshotResponse(t *testing.T) {
+	req := SnapshotRequest{BlockID: 100, Chunk: 3}
+	b := bytes.NewBuffer([]byte{})
+	require.NoError(t, req.Write(b))
+	resultReq := SnapshotRequest{}
+	require.NoError(t, resultReq.Read(b))
+	require.Equal(t, req, resultReq)
+
+	resp := SnapshotResponse{
+		BlockID: 100,
+		Size:    3 << 20,
+		Hash:    []byte(strings.Repeat("H", 32)),
+		NodeKey: []byte(strings.Repeat("K", 64)),
+		Sign:    []byte(strings.Repeat("S", 64)),
+		Data:    []byte(strings.Repeat("D", 1024)),
+	}
+	require.NoError(t, resp.Write(b))
+	result := SnapshotResponse{}
+	require.NoError(t, result.Read(b))
+	require.Equal(t, resp, result)
+}
+
+func TestHeadersResponse(t *testing.T) {
+	req := HeadersRequest{BlockID: 100, Count: 50}
+	b := bytes.NewBuffer([]byte{})
+	require.NoError(t, req.Write(b))
+	resultReq := HeadersRequest{}
+	require.NoError(t, resultReq.Read(b))
+	require.Equal(t, req, resultReq)
+
+	resp := HeadersResponse{Headers: []*BlockHeader{
+		{Data: []byte(strings.Repeat("A", 120)), MrklRoot: []byte(strings.Repeat("M", 64)), RollbacksHash: []byte(strings.Repeat("R", 32))},
+		{Data: []byte(strings.Repeat("B", 130)), MrklRoot: []byte(strings.Repeat("N", 64)), RollbacksHash: []byte(strings.Repeat("S", 32))},
+	}}
+	require.NoError(t, resp.Write(b))
+	result := HeadersResponse{}
+	require.NoError(t, result.Read(b))
+	require.Equal(t, resp, result)
+
+	resp.Headers[0].Data = []byte(strings.Repeat("A", 2048))
+	require.NoError(t, resp.Write(b))
+	require.Equal(t, ErrMaxSize, result.Read(b))
+}
+
+func TestPeersResponse(t *testing.T) {
+	req := PeersRequest{Addresses: []string{"10.0.0.1:7078", "node.example.com:7078"}}
+	b := bytes.NewBuffer([]byte{})
+	require.NoError(t, req.Write(b))
+	resultReq := PeersRequest{}
+	require.NoError(t, resultReq.Read(b))
+	require.Equal(t, req, resultReq)
+
+	resp := PeersResponse{Addresses: []string{}}
+	require.NoError(t, resp.Write(b))
+	result := PeersResponse{}
+	require.NoError(t, result.Read(b))
+	require.Equal(t, resp, result)
+
+	resp.Addresses = []string{strings.Repeat("a", 300)}
+	require.NoError(t, resp.Write(b))
+	require.Equal(t, ErrMaxSize, result.Read(b))
+}
+
+func TestTxInventory(t *testing.T) {
+	inv := TxInventory{Hashes: [][]byte{
+		[]byte(strings.Repeat("A", consts.HashSize)),
+		[]byte(strings.Repeat("B", consts.HashSize)),
+	}}
+	b := bytes.NewBuffer([]byte{})
+	require.NoError(t, inv.Write(b))
+	result := TxInventory{}
+	require.NoError(t, result.Read(b))
+	require.Equal(t, inv, result)
+
+	inv.Hashes = [][]byte{[]byte("short")}
+	require.Error(t, inv.Write(b))
+}
+
 func TestBodyResponse(t *testing.T) {
 	rt := GetBodyResponse{Data: []byte(strings.Repeat("A", 32))}
 	buf := []byte{}
@@ -0,0 +1,194 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestTypeProtocolV2 switches the connection to the framed protocol.
+// Legacy peers don't know this type and close the connection
+const RequestTypeProtocolV2 ReqTypesFlag = 0xFFFF
+
+// RequestTypeSecure precedes the TLS handshake of the encrypted connection between honor nodes.
+// New request types must be added before these reserved values
+const RequestTypeSecure ReqTypesFlag = 0xFFFE
+
+const (
+	// ProtocolVersion is the version of the framed protocol
+	ProtocolVersion uint16 = 2
+	// MinProtocolVersion is the minimal version of the framed protocol which is supported
+	MinProtocolVersion uint16 = 2
+
+	// MaxFramePayload is the max size of the frame payload, longer data is split into several frames
+	MaxFramePayload = 1 << 20
+
+	frameHeaderSize    = 11
+	maxNodeKeySize     = 128
+	handshakeNonceSize = 32
+	// handshakeSalt separates the signatures of handshakes from other signatures of node keys
+	handshakeSalt = "IBAX-HANDSHAKE"
+)
+
+// Frame flags
+const (
+	// FrameFlagEnd means that the sender has finished writing to the stream
+	FrameFlagEnd uint8 = 1 << iota
+	// FrameFlagReset aborts the stream on both sides
+	FrameFlagReset
+	// FrameFlagWindow allows the receiver to send more bytes, the payload is uint32 count
+	FrameFlagWindow
+)
+
+var (
+	ErrIncompatibleNetwork  = errors.New("Incompatible network id")
+	ErrIncompatibleProtocol = errors.New("Incompatible protocol version")
+	ErrFrameSize            = errors.New("Frame size greater than max size")
+	ErrHandshakeSign        = errors.New("Peer hasn't proved its node key")
+)
+
+// Frame is the unit of the framed protocol
+type Frame struct {
+	RequestID uint32
+	Type      ReqTypesFlag
+	Flags     uint8
+	Payload   []byte
+}
+
+func (f *Frame) Read(r io.Reader) error {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	size := binary.LittleEndian.Uint32(header[0:4])
+	if size > MaxFramePayload {
+		log.WithFields(log.Fields{"type": consts.ParameterExceeded, "size": size}).Error("on reading frame")
+		return ErrFrameSize
+	}
+	f.RequestID = binary.LittleEndian.Uint32(header[4:8])
+	f.Type = ReqTypesFlag(binary.LittleEndian.Uint16(header[8:10]))
+	f.Flags = header[10]
+	f.Payload = make([]byte, size)
+	_, err := io.ReadFull(r, f.Payload)
+	return err
+}
+
+func (f *Frame) Write(w io.Writer) error {
+	if len(f.Payload) > MaxFramePayload {
+		return ErrFrameSize
+	}
+	buf := make([]byte, frameHeaderSize, frameHeaderSize+len(f.Payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(f.Payload)))
+	binary.LittleEndian.PutUint32(buf[4:8], f.RequestID)
+	binary.LittleEndian.PutUint16(buf[8:10], uint16(f.Type))
+	buf[10] = f.Flags
+	_, err := w.Write(append(buf, f.Payload...))
+	return err
+}
+
+// Handshake is sent by both sides after RequestTypeProtocolV2, the accepting side sends it first.
+// Then each side signs the nonce of the other side by its node key to prove NodeKey
+type Handshake struct {
+	NetworkID int64
+	Version   uint16
+	NodeKey   []byte
+	Nonce     []byte
+}
+
+// NewHandshake returns the handshake of the current node
+func NewHandshake() *Handshake {
+	nonce := make([]byte, handshakeNonceSize)
+	rand.Read(nonce)
+	return &Handshake{
+		NetworkID: conf.Config.NetworkID,
+		Version:   ProtocolVersion,
+		NodeKey:   syspar.GetNodePubKey(),
+		Nonce:     nonce,
+	}
+}
+
+func (h *Handshake) Read(r io.Reader) error {
+	if err := binary.Read(r, binary.LittleEndian, &h.NetworkID); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &h.Version); err != nil {
+		return err
+	}
+	key, err := ReadSliceWithMaxSize(r, maxNodeKeySize)
+	if err != nil {
+		return err
+	}
+	h.NodeKey = key
+	if h.Nonce, err = ReadSliceWithMaxSize(r, handshakeNonceSize); err != nil {
+		return err
+	}
+	if len(h.Nonce) != handshakeNonceSize {
+		return ErrHandshakeSign
+	}
+	return nil
+}
+
+func (h *Handshake) Write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, h.NetworkID); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, h.Version); err != nil {
+		return err
+	}
+	if err := writeSlice(w, h.NodeKey); err != nil {
+		return err
+	}
+	return writeSlice(w, h.Nonce)
+}
+
+// Check returns error if the remote side is not compatible with the local one
+func (h *Handshake) Check(remote *Handshake) error {
+	if h.NetworkID != remote.NetworkID {
+		return ErrIncompatibleNetwork
+	}
+	if remote.Version < MinProtocolVersion {
+		return ErrIncompatibleProtocol
+	}
+	return nil
+}
+
+func handshakeData(nonce []byte) []byte {
+	return append([]byte(handshakeSalt), nonce...)
+}
+
+// WriteProof signs the nonce of the remote side by the private node key
+func (h *Handshake) WriteProof(w io.Writer, remote *Handshake, privKey []byte) error {
+	sign, err := crypto.Sign(privKey, handshakeData(remote.Nonce))
+	if err != nil {
+		return err
+	}
+	return writeSlice(w, sign)
+}
+
+// ReadProof checks that the remote side owns its node key
+func (h *Handshake) ReadProof(r io.Reader, remote *Handshake) error {
+	sign, err := ReadSliceWithMaxSize(r, maxNodeKeySize)
+	if err != nil {
+		return err
+	}
+	if len(remote.NodeKey) == 0 {
+		return ErrHandshakeSign
+	}
+	ok, err := crypto.CheckSign(remote.NodeKey, handshakeData(h.Nonce), sign)
+	if err != nil || !ok {
+		return ErrHandshakeSign
+	}
+	return nil
+}
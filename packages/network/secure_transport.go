@@ -0,0 +1,232 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const nodeCertLifetime = 10 * 365 * 24 * time.Hour
+
+var (
+	ErrNodeCertificate = errors.New("Node certificate is not valid")
+	ErrUnknownNodeKey  = errors.New("Node key is not in the list of honor nodes")
+	ErrWrongNodeKey    = errors.New("Node key doesn't match the expected one")
+	ErrNodeTLSCurve    = errors.New("Node tls transport supports only ECDSA keys")
+)
+
+var nodeCert = struct {
+	sync.Mutex
+	key  []byte
+	cert tls.Certificate
+}{}
+
+// nodeCertificate returns the self-signed certificate of the node private key.
+// The certificate is regenerated if the node key has been changed
+func nodeCertificate(privKey []byte) (tls.Certificate, error) {
+	nodeCert.Lock()
+	defer nodeCert.Unlock()
+
+	if crypto.Curve.String() != "ECDSA" {
+		return tls.Certificate{}, ErrNodeTLSCurve
+	}
+	if len(nodeCert.cert.Certificate) > 0 && bytes.Equal(nodeCert.key, privKey) {
+		return nodeCert.cert, nil
+	}
+
+	curve := elliptic.P256()
+	priv := new(ecdsa.PrivateKey)
+	priv.PublicKey.Curve = curve
+	priv.D = new(big.Int).SetBytes(privKey)
+	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(privKey)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ibax-node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(nodeCertLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("creating node certificate")
+		return tls.Certificate{}, err
+	}
+	nodeCert.key = privKey
+	nodeCert.cert = tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+	}
+	return nodeCert.cert, nil
+}
+
+// certNodeKey returns the node public key in the format of honor_nodes parameter
+func certNodeKey(rawCerts [][]byte) ([]byte, error) {
+	if len(rawCerts) == 0 {
+		return nil, ErrNodeCertificate
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return nil, err
+	}
+	if err = cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, ErrNodeCertificate
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, ErrNodeCertificate
+	}
+	return append(converter.FillLeft(pub.X.Bytes()), converter.FillLeft(pub.Y.Bytes())...), nil
+}
+
+// honorNodeKey checks that the key belongs to one of honor nodes
+func honorNodeKey(key []byte) error {
+	if _, err := syspar.GetNodePositionByPublicKey(key); err != nil {
+		return ErrUnknownNodeKey
+	}
+	return nil
+}
+
+// expectedNodeKey checks that the peer has exactly the expected key
+func expectedNodeKey(expected []byte) func([]byte) error {
+	return func(key []byte) error {
+		if !bytes.Equal(key, expected) {
+			return ErrWrongNodeKey
+		}
+		return nil
+	}
+}
+
+// nodeTLSConfig returns the config with the certificate of privKey. The key of the peer certificate
+// is checked by verify
+func nodeTLSConfig(privKey []byte, verify func([]byte) error) (*tls.Config, error) {
+	cert, err := nodeCertificate(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+		ClientAuth:   tls.RequireAnyClientCert,
+		// the chain is verified by VerifyPeerCertificate with the keys of honor nodes
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			key, err := certNodeKey(rawCerts)
+			if err != nil {
+				return err
+			}
+			return verify(key)
+		},
+	}, nil
+}
+
+// SecureClient establishes the encrypted connection with the honor node which has the public key
+func SecureClient(conn net.Conn, nodeKey []byte) (net.Conn, error) {
+	return secureClient(conn, syspar.GetNodePrivKey(), nodeKey)
+}
+
+func secureClient(conn net.Conn, privKey, nodeKey []byte) (net.Conn, error) {
+	if len(nodeKey) == 0 {
+		return nil, ErrWrongNodeKey
+	}
+	cfg, err := nodeTLSConfig(privKey, expectedNodeKey(nodeKey))
+	if err != nil {
+		return nil, err
+	}
+	rt := &RequestType{Type: RequestTypeSecure}
+	if err = rt.Write(conn); err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, cfg)
+	if err = tlsConn.Handshake(); err != nil {
+		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": conn.RemoteAddr()}).Error("tls handshake with node")
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// AcceptConn detects the kind of the incoming connection by the first request type. TLS connections
+// are accepted only from honor nodes, other connections are returned as is
+func AcceptConn(conn net.Conn) (net.Conn, error) {
+	return acceptConn(conn, syspar.GetNodePrivKey(), honorNodeKey)
+}
+
+func acceptConn(conn net.Conn, privKey []byte, verify func([]byte) error) (net.Conn, error) {
+	conn.SetDeadline(time.Now().Add(consts.TCPConnTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	pc := &peekedConn{Conn: conn, r: bufio.NewReader(conn)}
+	head, err := pc.r.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if ReqTypesFlag(binary.LittleEndian.Uint16(head)) != RequestTypeSecure {
+		return pc, nil
+	}
+	pc.r.Discard(len(head))
+	cfg, err := nodeTLSConfig(privKey, verify)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Server(pc, cfg)
+	if err = tlsConn.Handshake(); err != nil {
+		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": conn.RemoteAddr()}).Warn("tls handshake with node")
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// IsPrivateRequest returns true for the requests which carry private data
+func IsPrivateRequest(reqType ReqTypesFlag) bool {
+	switch reqType {
+	case RequestTypeSendPrivateData, RequestTypeSendPrivateFile,
+		RequestTypeSendVDESrcData, RequestTypeSendVDESrcDataAgent, RequestTypeSendVDEAgentData,
+		RequestTypeSendSubNodeSrcData, RequestTypeSendSubNodeSrcDataAgent, RequestTypeSendSubNodeAgentData:
+		return true
+	}
+	return false
+}
+
+// IsSecureConn returns true if the connection is encrypted and the peer is authenticated
+func IsSecureConn(conn net.Conn) bool {
+	switch c := conn.(type) {
+	case *tls.Conn:
+		return true
+	case *Stream:
+		return IsSecureConn(c.conn.conn)
+	}
+	return false
+}
+
+// peekedConn is net.Conn which has read ahead data
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,91 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptPlainConn(t *testing.T) {
+	// the type with the first byte of TLS record must not be taken for TLS connection
+	for _, reqType := range []ReqTypesFlag{RequestTypeMaxBlock, 0x16, 0x0116} {
+		client, server := net.Pipe()
+		go func() {
+			(&RequestType{Type: reqType}).Write(client)
+		}()
+		conn, err := AcceptConn(server)
+		require.NoError(t, err)
+		rt := &RequestType{}
+		require.NoError(t, rt.Read(conn))
+		require.Equal(t, reqType, rt.Type)
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestSecureHandshake(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	serverPriv, serverPub, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	clientPriv, clientPub, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	_, otherPub, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	// handshake connects the client which expects serverKey to the server which accepts clientKey
+	handshake := func(serverKey, clientKey []byte) (clientErr, serverErr error) {
+		accepted := make(chan error, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				accepted <- err
+				return
+			}
+			defer conn.Close()
+			secure, err := acceptConn(conn, serverPriv, expectedNodeKey(clientKey))
+			if err == nil {
+				rt := &RequestType{}
+				if err = rt.Read(secure); err == nil && !IsSecureConn(secure) {
+					err = ErrNodeCertificate
+				}
+			}
+			accepted <- err
+		}()
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			return err, <-accepted
+		}
+		defer conn.Close()
+		if secure, err := secureClient(conn, clientPriv, serverKey); err != nil {
+			clientErr = err
+			conn.Close()
+		} else {
+			clientErr = (&RequestType{Type: RequestTypeMaxBlock}).Write(secure)
+		}
+		return clientErr, <-accepted
+	}
+
+	clientErr, serverErr := handshake(serverPub, clientPub)
+	require.NoError(t, clientErr)
+	require.NoError(t, serverErr)
+
+	// the client rejects the server with another node key
+	clientErr, serverErr = handshake(otherPub, clientPub)
+	require.Equal(t, ErrWrongNodeKey, clientErr)
+	require.Error(t, serverErr)
+
+	// the server rejects the client with unknown node key
+	_, serverErr = handshake(serverPub, otherPub)
+	require.Equal(t, ErrWrongNodeKey, serverErr)
+}
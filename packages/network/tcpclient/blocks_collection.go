@@ -27,18 +27,11 @@ const sizeBytesLength = 4
 
 // GetBlocksBodies send GetBodiesRequest returns channel of binary blocks data
 func GetBlocksBodies(ctx context.Context, host string, blockID int64, reverseOrder bool) (<-chan []byte, error) {
-	conn, err := newConnection(host)
+	conn, err := dialRequest(host, network.RequestTypeBlockCollection)
 	if err != nil {
 		return nil, err
 	}
 
-	// send the type of data
-	rt := &network.RequestType{Type: network.RequestTypeBlockCollection}
-	if err = rt.Write(conn); err != nil {
-		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing data type block body to connection")
-		return nil, err
-	}
-
 	req := &network.GetBodiesRequest{
 		BlockID:      uint32(blockID),
 		ReverseOrder: reverseOrder,
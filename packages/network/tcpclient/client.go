@@ -11,7 +11,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -42,6 +45,25 @@ func newConnection(addr string) (net.Conn, error) {
 		return nil, err
 	}
 
+	if conf.Config.NodeTLS {
+		node, err := syspar.GetNodeByHost(addr)
+		if err != nil && host != addr {
+			node, err = syspar.GetNodeByHost(host)
+		}
+		if err != nil {
+			// only honor nodes have the keys for tls, the honor nodes are never connected without it
+			log.WithFields(log.Fields{"type": consts.ConnectionError, "address": host}).Warn("connecting without tls to the node which isn't honor node")
+		} else {
+			conn.SetDeadline(time.Now().Add(consts.TCPConnTimeout))
+			secure, err := network.SecureClient(conn, node.PublicKey)
+			if err != nil {
+				conn.Close()
+				return nil, err
+			}
+			conn = secure
+		}
+	}
+
 	conn.SetReadDeadline(time.Now().Add(consts.READ_TIMEOUT * time.Second))
 	conn.SetWriteDeadline(time.Now().Add(consts.WRITE_TIMEOUT * time.Second))
 	return conn, nil
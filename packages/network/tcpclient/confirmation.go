@@ -13,19 +13,13 @@ import (
 )
 
 func CheckConfirmation(host string, blockID int64, logger *log.Entry) (hash string) {
-	conn, err := newConnection(host)
+	conn, err := dialRequest(host, network.RequestTypeConfirmation)
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host, "block_id": blockID}).Debug("dialing to host")
 		return "0"
 	}
 	defer conn.Close()
 
-	rt := &network.RequestType{Type: network.RequestTypeConfirmation}
-	if err = rt.Write(conn); err != nil {
-		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "block_id": blockID}).Error("sending request type")
-		return "0"
-	}
-
 	req := &network.ConfirmRequest{
 		BlockID: uint32(blockID),
 	}
@@ -0,0 +1,33 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetHeaders requests the headers of blocks starting from blockID from the host
+func GetHeaders(host string, blockID, count int64, logger *log.Entry) ([]*network.BlockHeader, error) {
+	conn, err := dialRequest(host, network.RequestTypeHeaders)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host}).Debug("dialing to host")
+		return nil, err
+	}
+	defer conn.Close()
+	req := &network.HeadersRequest{BlockID: blockID, Count: count}
+	if err = req.Write(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("sending headers request")
+		return nil, err
+	}
+	resp := &network.HeadersResponse{}
+	if err = resp.Read(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("receiving headers response")
+		return nil, err
+	}
+	return resp.Headers, nil
+}
@@ -25,7 +25,21 @@ func HostWithMaxBlock(ctx context.Context, hosts []string) (bestHost string, max
 }
 
 func GetMaxBlockID(host string) (blockID int64, err error) {
-	return getMaxBlock(host)
+	return maxBlockFromHost(host)
+}
+
+// maxBlockFromHost uses the framed protocol and falls back to the legacy one only for old peers.
+// The incompatible peers are not asked by the legacy protocol
+func maxBlockFromHost(host string) (int64, error) {
+	resp := &network.MaxBlockResponse{}
+	err := SessionRequest(host, network.RequestTypeMaxBlock, nil, resp)
+	if err == ErrLegacyPeer {
+		return getMaxBlock(host)
+	}
+	if err != nil {
+		return -1, err
+	}
+	return resp.BlockID, nil
 }
 
 func getMaxBlock(host string) (blockID int64, err error) {
@@ -75,7 +89,7 @@ func hostWithMaxBlock(ctx context.Context, hosts []string) (bestHost string, max
 		wg.Add(1)
 
 		go func(host string) {
-			blockID, err := getMaxBlock(host)
+			blockID, err := maxBlockFromHost(host)
 			defer wg.Done()
 
 			resultChan <- blockAndHost{
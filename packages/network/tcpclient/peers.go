@@ -0,0 +1,33 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ExchangePeers sends the known addresses to the host and returns the addresses which are known by the host
+func ExchangePeers(host string, addresses []string, logger *log.Entry) ([]string, error) {
+	conn, err := dialRequest(host, network.RequestTypePeers)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host}).Debug("dialing to host")
+		return nil, err
+	}
+	defer conn.Close()
+	req := &network.PeersRequest{Addresses: addresses}
+	if err = req.Write(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Debug("sending peers request")
+		return nil, err
+	}
+	resp := &network.PeersResponse{}
+	if err = resp.Read(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Debug("receiving peers response")
+		return nil, err
+	}
+	return resp.Addresses, nil
+}
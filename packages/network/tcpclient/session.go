@@ -0,0 +1,215 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// legacyPeerRecheck is the period after which the peer without the framed protocol is asked again
+const legacyPeerRecheck = 10 * time.Minute
+
+var (
+	ErrLegacyPeer     = errors.New("Peer doesn't support the framed protocol")
+	ErrSessionTimeout = errors.New("Session request timeout")
+)
+
+// session is the framed connection to one host. Its mutex is held while the connection is opened,
+// so the requests to other hosts don't wait for the dialing
+type session struct {
+	sync.Mutex
+	conn   *network.FramedConn
+	legacy time.Time
+}
+
+var sessions = struct {
+	sync.Mutex
+	hosts map[string]*session
+}{
+	hosts: make(map[string]*session),
+}
+
+func getHostSession(host string) *session {
+	sessions.Lock()
+	defer sessions.Unlock()
+	s, ok := sessions.hosts[host]
+	if !ok {
+		s = &session{}
+		sessions.hosts[host] = s
+	}
+	return s
+}
+
+// GetSession returns the framed connection to host. The connection is opened once
+// and is shared by all requests to the host
+func GetSession(host string) (*network.FramedConn, error) {
+	s := getHostSession(host)
+	s.Lock()
+	defer s.Unlock()
+
+	if s.conn != nil {
+		if s.conn.Err() == nil {
+			return s.conn, nil
+		}
+		s.conn = nil
+	}
+	if !s.legacy.IsZero() && time.Since(s.legacy) < legacyPeerRecheck {
+		return nil, ErrLegacyPeer
+	}
+
+	fc, err := openSession(host)
+	if err == ErrLegacyPeer {
+		s.legacy = time.Now()
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.legacy = time.Time{}
+	s.conn = fc
+	return fc, nil
+}
+
+func openSession(host string) (*network.FramedConn, error) {
+	conn, err := newConnection(host)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := &network.RequestType{Type: network.RequestTypeProtocolV2}
+	if err = rt.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	remote := &network.Handshake{}
+	if err = remote.Read(conn); err != nil {
+		conn.Close()
+		// legacy nodes close the connection on the unknown request type without answer.
+		// The honor nodes and the encrypted connections are never downgraded
+		if err == io.EOF && !network.IsSecureConn(conn) && !isHonorHost(host) {
+			return nil, ErrLegacyPeer
+		}
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": host}).Error("reading handshake")
+		return nil, err
+	}
+	local := network.NewHandshake()
+	if err = local.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err = local.Check(remote); err != nil {
+		conn.Close()
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": host, "network_id": remote.NetworkID, "version": remote.Version}).Error("incompatible peer")
+		return nil, err
+	}
+	if err = local.WriteProof(conn, remote, syspar.GetNodePrivKey()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err = local.ReadProof(conn, remote); err != nil {
+		conn.Close()
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": host}).Error("peer node key")
+		return nil, err
+	}
+
+	// the session is long-lived, timeouts are checked per stream
+	conn.SetDeadline(time.Time{})
+	fc := network.NewFramedConn(conn, remote.NodeKey, nil)
+	go func() {
+		if err := fc.Serve(); err != nil {
+			log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": host}).Debug("session closed")
+		}
+		conn.Close()
+	}()
+	return fc, nil
+}
+
+func isHonorHost(host string) bool {
+	_, err := syspar.GetNodeByHost(host)
+	return err == nil
+}
+
+// PeerNodeKey returns the node key which the host has proved in the handshake of the session.
+// The peers without the framed protocol can't prove the node key and ErrLegacyPeer is returned
+func PeerNodeKey(host string) ([]byte, error) {
+	fc, err := GetSession(host)
+	if err != nil {
+		return nil, err
+	}
+	return fc.RemoteKey(), nil
+}
+
+// dialRequest returns the connection for the request of reqType. It is the stream of the session
+// or the legacy connection with the written request type for peers without the framed protocol
+func dialRequest(host string, reqType network.ReqTypesFlag) (net.Conn, error) {
+	fc, err := GetSession(host)
+	if err == nil {
+		s, err := fc.OpenStream(reqType)
+		if err != nil {
+			return nil, err
+		}
+		s.SetReadDeadline(time.Now().Add(consts.READ_TIMEOUT * time.Second))
+		s.SetWriteDeadline(time.Now().Add(consts.WRITE_TIMEOUT * time.Second))
+		return s, nil
+	}
+	if err != ErrLegacyPeer {
+		return nil, err
+	}
+	conn, err := newConnection(host)
+	if err != nil {
+		return nil, err
+	}
+	rt := &network.RequestType{Type: reqType}
+	if err = rt.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// SessionRequest sends the request of reqType over the framed connection and reads the response.
+// req can be nil for requests without body
+func SessionRequest(host string, reqType network.ReqTypesFlag, req, resp network.SelfReaderWriter) error {
+	fc, err := GetSession(host)
+	if err != nil {
+		return err
+	}
+	s, err := fc.OpenStream(reqType)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(consts.READ_TIMEOUT * time.Second))
+
+	if req != nil {
+		buf := new(bytes.Buffer)
+		if err = req.Write(buf); err != nil {
+			return err
+		}
+		if _, err = s.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err = resp.Read(s); err != nil {
+		if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+			// only the stream is reset, other requests of the session go on
+			log.WithFields(log.Fields{"type": consts.NetworkError, "host": host, "request_type": reqType}).Error("session request timeout")
+			return ErrSessionTimeout
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"net"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listenPeer accepts connections, reads the request type and passes the connection to serve
+func listenPeer(t *testing.T, serve func(net.Conn)) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			rt := &network.RequestType{}
+			if rt.Read(conn) == nil {
+				serve(conn)
+			}
+			conn.Close()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestOpenSessionLegacyPeer(t *testing.T) {
+	// the legacy node closes the connection on the unknown request type
+	host := listenPeer(t, func(net.Conn) {})
+	_, err := openSession(host)
+	require.Equal(t, ErrLegacyPeer, err)
+
+	// the broken handshake is not the legacy peer
+	host = listenPeer(t, func(conn net.Conn) {
+		conn.Write([]byte{1, 0})
+	})
+	_, err = openSession(host)
+	require.Error(t, err)
+	require.NotEqual(t, ErrLegacyPeer, err)
+}
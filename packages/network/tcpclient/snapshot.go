@@ -0,0 +1,85 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNoSnapshot   = errors.New("Host doesn't have snapshot")
+	ErrSnapshotHash = errors.New("Snapshot hash doesn't match")
+)
+
+// GetSnapshotChunk requests the chunk of the snapshot from the host
+func GetSnapshotChunk(host string, blockID, chunk int64, logger *log.Entry) (*network.SnapshotResponse, error) {
+	conn, err := dialRequest(host, network.RequestTypeSnapshot)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host}).Debug("dialing to host")
+		return nil, err
+	}
+	defer conn.Close()
+	req := &network.SnapshotRequest{BlockID: blockID, Chunk: chunk}
+	if err = req.Write(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("sending snapshot request")
+		return nil, err
+	}
+	resp := &network.SnapshotResponse{}
+	if err = resp.Read(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("receiving snapshot response")
+		return nil, err
+	}
+	if resp.BlockID == 0 {
+		return nil, ErrNoSnapshot
+	}
+	return resp, nil
+}
+
+// DownloadSnapshot writes the last snapshot of the host chunk by chunk and checks its hash.
+// It returns the block id of the snapshot
+func DownloadSnapshot(ctx context.Context, host string, w io.Writer, logger *log.Entry) (int64, error) {
+	var (
+		blockID, size, loaded int64
+		hash                  []byte
+	)
+	h := sha256.New()
+	for chunk := int64(0); chunk == 0 || loaded < size; chunk++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		resp, err := GetSnapshotChunk(host, blockID, chunk, logger)
+		if err != nil {
+			return 0, err
+		}
+		if chunk == 0 {
+			blockID, size, hash = resp.BlockID, resp.Size, resp.Hash
+		} else if resp.BlockID != blockID || !bytes.Equal(resp.Hash, hash) {
+			return 0, ErrSnapshotHash
+		}
+		if len(resp.Data) == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if _, err = w.Write(resp.Data); err != nil {
+			logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing snapshot")
+			return 0, err
+		}
+		h.Write(resp.Data)
+		loaded += int64(len(resp.Data))
+	}
+	if loaded != size || !bytes.Equal(h.Sum(nil), hash) {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "host": host, "block_id": blockID}).Error("wrong snapshot hash")
+		return 0, ErrSnapshotHash
+	}
+	return blockID, nil
+}
@@ -0,0 +1,149 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// knownTxsPerHost is the count of the last hashes which are remembered as announced to the host
+const knownTxsPerHost = 50000
+
+// knownTxs contains the hashes of transactions which have been announced to hosts
+var knownTxs = struct {
+	sync.Mutex
+	hosts map[string]*network.HashCache
+}{
+	hosts: make(map[string]*network.HashCache),
+}
+
+func hostKnownTxs(host string) *network.HashCache {
+	knownTxs.Lock()
+	defer knownTxs.Unlock()
+
+	c, ok := knownTxs.hosts[host]
+	if !ok {
+		c = network.NewHashCache(knownTxsPerHost)
+		knownTxs.hosts[host] = c
+	}
+	return c
+}
+
+// AnnounceTransactions announces the hashes of transactions to the hosts and sends only
+// the transactions which are requested by them. The hashes which have been announced
+// to the host before are skipped
+func AnnounceTransactions(ctx context.Context, hosts []string, txes []model.Transaction) error {
+	if len(hosts) == 0 || len(txes) == 0 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var errCount int32
+	for _, h := range hosts {
+		if err := ctx.Err(); err != nil {
+			log.Debug("exit by context error")
+			return err
+		}
+
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+
+			if err := announceToHost(host, txes); err != nil {
+				atomic.AddInt32(&errCount, 1)
+			}
+		}(h)
+	}
+
+	wg.Wait()
+
+	if int(errCount) == len(hosts) {
+		return ErrNodesUnavailable
+	}
+
+	return nil
+}
+
+func announceToHost(host string, txes []model.Transaction) error {
+	known := hostKnownTxs(host)
+	var unknown []model.Transaction
+	for _, tx := range txes {
+		if !known.Has(tx.Hash) {
+			unknown = append(unknown, tx)
+		}
+	}
+	for len(unknown) > 0 {
+		batch := unknown
+		if int64(len(batch)) > network.MaxInventoryHashes {
+			batch = batch[:network.MaxInventoryHashes]
+		}
+		unknown = unknown[len(batch):]
+		if err := sendInventory(host, batch); err != nil {
+			return err
+		}
+		for _, tx := range batch {
+			known.Add(tx.Hash)
+		}
+	}
+	return nil
+}
+
+func sendInventory(host string, txes []model.Transaction) error {
+	con, err := dialRequest(host, network.RequestTypeTxInventory)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": host}).Error("on creating tcp connection")
+		return err
+	}
+	defer con.Close()
+
+	inv := &network.TxInventory{Hashes: make([][]byte, 0, len(txes))}
+	bodies := make(map[string][]byte, len(txes))
+	for _, tx := range txes {
+		inv.Hashes = append(inv.Hashes, tx.Hash)
+		bodies[string(tx.Hash)] = tx.Data
+	}
+
+	if err = inv.Write(con); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("on sending inventory")
+		return err
+	}
+	needed := &network.TxInventory{}
+	if err = needed.Read(con); err != nil {
+		if err == io.EOF {
+			// the host doesn't support the inventory and closes the connection, so the transactions are pushed
+			packet, err := MarshalTxPacket(txes)
+			if err != nil {
+				return err
+			}
+			return sendRawTransacitionsToHost(host, packet)
+		}
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("on reading requested transactions")
+		return err
+	}
+	if len(needed.Hashes) == 0 {
+		return nil
+	}
+
+	resp := &network.TxBodies{Data: make([][]byte, 0, len(needed.Hashes))}
+	for _, hash := range needed.Hashes {
+		if data, ok := bodies[string(hash)]; ok {
+			resp.Data = append(resp.Data, data)
+		}
+	}
+	if err = resp.Write(con); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host}).Error("on sending requested transactions")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetVote requests the signed vote for the block from the honor node.
+// It returns nil if the node doesn't have the block
+func GetVote(host string, blockID int64, logger *log.Entry) (*utils.Vote, error) {
+	conn, err := dialRequest(host, network.RequestTypeVote)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "host": host, "block_id": blockID}).Debug("dialing to host")
+		return nil, err
+	}
+	defer conn.Close()
+	req := &network.VoteRequest{BlockID: blockID}
+	if err = req.Write(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "block_id": blockID}).Error("sending vote request")
+		return nil, err
+	}
+	resp := &network.VoteResponse{}
+	if err = resp.Read(conn); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "host": host, "block_id": blockID}).Error("receiving vote response")
+		return nil, err
+	}
+	if len(resp.Hash) == 0 {
+		return nil, nil
+	}
+	return &utils.Vote{BlockID: blockID, Hash: resp.Hash, NodeKey: resp.NodeKey, Sign: resp.Sign}, nil
+}
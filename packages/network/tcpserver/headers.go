@@ -0,0 +1,68 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpserver
+
+import (
+	"bytes"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TypeHeaders writes the headers of blocks starting from the requested block.
+// The request is sent by the nodes of the light mode
+func TypeHeaders(r *network.HeadersRequest) (*network.HeadersResponse, error) {
+	resp := &network.HeadersResponse{}
+	count := r.Count
+	if count <= 0 || count > network.HeadersPerRequest {
+		count = network.HeadersPerRequest
+	}
+	blocks, err := (&model.Block{}).GetBlocksFrom(r.BlockID-1, "ASC", int(count))
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": r.BlockID}).Error("getting blocks")
+		return resp, nil
+	}
+	prunedID, err := model.GetPrunedBlockID()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pruned block")
+		return resp, nil
+	}
+	for _, b := range blocks {
+		buf := bytes.NewBuffer(b.Data)
+		if _, _, err = utils.ParseBlockHeader(buf); err != nil {
+			log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err, "block_id": b.ID}).Error("parsing block header")
+			break
+		}
+		header := b.Data[:len(b.Data)-buf.Len()]
+		var mrklRoot []byte
+		if b.ID <= prunedID {
+			// only the header of the pruned block is kept, its merkle root is saved separately
+			pb := &model.PrunedBlock{}
+			if _, err = pb.Get(b.ID); err != nil {
+				log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": b.ID}).Error("getting pruned block")
+				break
+			}
+			mrklRoot = pb.MrklRoot
+		} else {
+			blck, err := block.UnmarshallBlock(bytes.NewBuffer(b.Data), false)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err, "block_id": b.ID}).Error("unmarshalling block")
+				break
+			}
+			mrklRoot = blck.MrklRoot
+		}
+		resp.Headers = append(resp.Headers, &network.BlockHeader{
+			Data:          header,
+			MrklRoot:      mrklRoot,
+			RollbacksHash: b.RollbacksHash,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,26 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpserver
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/service"
+)
+
+// TypePeers adds the addresses of the requesting node to the table of peers as unverified ones
+// and writes the honor nodes and the best verified peers
+func TypePeers(r *network.PeersRequest, remote string) (*network.PeersResponse, error) {
+	resp := &network.PeersResponse{Addresses: syspar.GetRemoteHosts()}
+	ps := service.GetPeersService()
+	if ps == nil {
+		return resp, nil
+	}
+	ps.Add(remote, r.Addresses)
+	if count := int(network.MaxPeersPerRequest) - len(resp.Addresses); count > 0 {
+		resp.Addresses = append(resp.Addresses, ps.Hosts(count)...)
+	}
+	return resp, nil
+}
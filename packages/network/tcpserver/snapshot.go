@@ -0,0 +1,67 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpserver
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/snapshot"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TypeSnapshot writes the chunk of the snapshot with the published hash of the snapshot.
+// Honor nodes sign the hash for network.SnapshotHashChunk without the data.
+// The request is sent by 'BlocksCollection' daemon of new nodes
+func TypeSnapshot(r *network.SnapshotRequest) (*network.SnapshotResponse, error) {
+	resp := &network.SnapshotResponse{}
+	s := &model.Snapshot{}
+	var (
+		found bool
+		err   error
+	)
+	if r.BlockID == 0 {
+		found, err = s.GetLast()
+	} else {
+		found, err = s.Get(r.BlockID)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": r.BlockID}).Error("getting snapshot")
+		return resp, nil
+	}
+	if !found {
+		return resp, nil
+	}
+	if r.Chunk == network.SnapshotHashChunk {
+		nodeKey := syspar.GetNodePubKey()
+		if _, err = syspar.GetNodePositionByPublicKey(nodeKey); err != nil {
+			// only honor nodes confirm snapshots
+			return resp, nil
+		}
+		sign, err := snapshot.NewSign(s.BlockID, s.Hash, nodeKey, syspar.GetNodePrivKey())
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "block_id": s.BlockID}).Error("signing snapshot hash")
+			return resp, nil
+		}
+		resp.BlockID = s.BlockID
+		resp.Size = s.Size
+		resp.Hash = s.Hash
+		resp.NodeKey = sign.NodeKey
+		resp.Sign = sign.Sign
+		return resp, nil
+	}
+	data, err := snapshot.ReadChunk(s, r.Chunk)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "block_id": s.BlockID, "chunk": r.Chunk}).Error("reading snapshot chunk")
+		return resp, nil
+	}
+	resp.BlockID = s.BlockID
+	resp.Size = s.Size
+	resp.Hash = s.Hash
+	resp.Data = data
+	return resp, nil
+}
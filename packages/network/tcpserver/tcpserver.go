@@ -5,11 +5,16 @@
 package tcpserver
 
 import (
+	"io"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/metrics"
 	"github.com/IBAX-io/go-ibax/packages/network"
 	"github.com/IBAX-io/go-ibax/packages/service"
 
@@ -25,10 +30,63 @@ func HandleTCPRequest(rw net.Conn) {
 		return
 	}
 
-	log.WithFields(log.Fields{"request_type": dType.Type}).Debug("tcpserver got request type")
-	var response interface{}
+	if dType.Type == network.RequestTypeProtocolV2 {
+		if err = handleFramedConn(rw); err != nil && err != io.EOF {
+			log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Debug("framed connection closed")
+		}
+		return
+	}
+
+	handleRequest(dType.Type, rw)
+}
+
+// handleFramedConn exchanges the handshakes and serves the requests of the framed protocol.
+// The handshake is sent first, so the client tells the legacy nodes by the closed connection
+func handleFramedConn(conn net.Conn) error {
+	local := network.NewHandshake()
+	if err := local.Write(conn); err != nil {
+		return err
+	}
+	remote := &network.Handshake{}
+	if err := remote.Read(conn); err != nil {
+		return err
+	}
+	if err := local.Check(remote); err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "network_id": remote.NetworkID, "version": remote.Version}).Warn("incompatible peer")
+		return err
+	}
+	if err := local.ReadProof(conn, remote); err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": conn.RemoteAddr()}).Warn("peer node key")
+		return err
+	}
+	if err := local.WriteProof(conn, remote, syspar.GetNodePrivKey()); err != nil {
+		return err
+	}
+
+	fc := network.NewFramedConn(conn, remote.NodeKey, func(reqType network.ReqTypesFlag, s *network.Stream) {
+		if reqType == network.RequestTypeProtocolV2 {
+			return
+		}
+		handleRequest(reqType, s)
+	})
+	return fc.Serve()
+}
 
-	switch dType.Type {
+func handleRequest(reqType network.ReqTypesFlag, rw net.Conn) {
+	log.WithFields(log.Fields{"request_type": reqType}).Debug("tcpserver got request type")
+	if conf.Config.NodeTLS && network.IsPrivateRequest(reqType) && !network.IsSecureConn(rw) {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "request_type": reqType, "host": rw.RemoteAddr()}).Warn("private request over insecure connection")
+		return
+	}
+	var (
+		response interface{}
+		err      error
+	)
+	defer func() {
+		metrics.TCPRequests.WithLabelValues(strconv.Itoa(int(reqType)), metrics.Result(err)).Inc()
+	}()
+
+	switch reqType {
 	case network.RequestTypeHonorNode:
 		if service.IsNodePaused() {
 			return
@@ -41,6 +99,12 @@ func HandleTCPRequest(rw net.Conn) {
 		}
 		err = Type2(rw)
 
+	case network.RequestTypeTxInventory:
+		if service.IsNodePaused() {
+			return
+		}
+		err = TypeTxInventory(rw)
+
 	case network.RequestTypeStopNetwork:
 		req := &network.StopNetworkRequest{}
 		if err = req.Read(rw); err == nil {
@@ -57,6 +121,30 @@ func HandleTCPRequest(rw net.Conn) {
 			response, err = Type4(req)
 		}
 
+	case network.RequestTypeVote:
+		req := &network.VoteRequest{}
+		if err = req.Read(rw); err == nil {
+			response, err = TypeVote(req)
+		}
+
+	case network.RequestTypeSnapshot:
+		req := &network.SnapshotRequest{}
+		if err = req.Read(rw); err == nil {
+			response, err = TypeSnapshot(req)
+		}
+
+	case network.RequestTypeHeaders:
+		req := &network.HeadersRequest{}
+		if err = req.Read(rw); err == nil {
+			response, err = TypeHeaders(req)
+		}
+
+	case network.RequestTypePeers:
+		req := &network.PeersRequest{}
+		if err = req.Read(rw); err == nil {
+			response, err = TypePeers(req, rw.RemoteAddr().String())
+		}
+
 	case network.RequestTypeBlockCollection:
 		req := &network.GetBodiesRequest{}
 		if err = req.Read(rw); err == nil {
@@ -103,7 +191,7 @@ func HandleTCPRequest(rw net.Conn) {
 		return
 	}
 
-	log.WithFields(log.Fields{"response": response, "request_type": dType.Type}).Debug("tcpserver responded")
+	log.WithFields(log.Fields{"response": response, "request_type": reqType}).Debug("tcpserver responded")
 	if err = response.(network.SelfReaderWriter).Write(rw); err != nil {
 		// err = SendRequest(response, rw)
 		log.Errorf("tcpserver handle error: %s", err)
@@ -132,8 +220,12 @@ func TcpListener(laddr string) error {
 				time.Sleep(time.Second)
 			} else {
 				go func(conn net.Conn) {
-					HandleTCPRequest(conn)
-					conn.Close()
+					defer conn.Close()
+					c, err := network.AcceptConn(conn)
+					if err != nil {
+						return
+					}
+					HandleTCPRequest(c)
 				}(conn)
 			}
 		}
@@ -0,0 +1,111 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpserver
+
+import (
+	"io"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/transaction"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// seenTxsSize is the count of the last hashes of transactions which are remembered as received
+const seenTxsSize = 100000
+
+// seenTxs contains the hashes of transactions which have been requested or received by the node,
+// such transactions aren't requested again when they are announced by other nodes
+var seenTxs = network.NewHashCache(seenTxsSize)
+
+// TypeTxInventory requests the announced transactions which the node doesn't have and saves them
+func TypeTxInventory(rw io.ReadWriter) error {
+	inv := &network.TxInventory{}
+	if err := inv.Read(rw); err != nil {
+		return err
+	}
+
+	needed := &network.TxInventory{}
+	for _, hash := range inv.Hashes {
+		if !seenTxs.Add(hash) {
+			continue
+		}
+		known, err := isKnownTransaction(hash)
+		if err != nil {
+			seenTxs.Remove(hash)
+			return err
+		}
+		if !known {
+			needed.Hashes = append(needed.Hashes, hash)
+		}
+	}
+	requested := make(map[string]bool, len(needed.Hashes))
+	for _, hash := range needed.Hashes {
+		requested[string(hash)] = true
+	}
+	// the transactions which haven't been received can be requested from other nodes
+	defer func() {
+		for hash := range requested {
+			seenTxs.Remove([]byte(hash))
+		}
+	}()
+
+	if err := needed.Write(rw); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on sending requested transactions")
+		return err
+	}
+	if len(needed.Hashes) == 0 {
+		return nil
+	}
+
+	bodies := &network.TxBodies{}
+	if err := bodies.Read(rw); err != nil {
+		return err
+	}
+	var rtxs []*model.RawTx
+	for _, data := range bodies.Data {
+		rtx := &transaction.RawTransaction{}
+		if err := rtx.Processing(data); err != nil {
+			log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("processing announced transaction")
+			continue
+		}
+		if !requested[string(rtx.Hash())] {
+			continue
+		}
+		delete(requested, string(rtx.Hash()))
+		rtxs = append(rtxs, rtx.SetRawTx())
+	}
+	if len(rtxs) == 0 {
+		return nil
+	}
+	if err := model.SendTxBatches(rtxs); err != nil {
+		for _, rtx := range rtxs {
+			seenTxs.Remove(rtx.Hash)
+		}
+		return err
+	}
+	return nil
+}
+
+// isKnownTransaction reports whether the transaction is in the blockchain, the pool or the queue
+func isKnownTransaction(hash []byte) (bool, error) {
+	for _, count := range []func([]byte) (int64, error){
+		model.GetLogTransactionsCount,
+		model.GetTransactionsCount,
+		model.GetQueuedTransactionsCount,
+	} {
+		exists, err := count(hash)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "txHash": hash}).Error("getting count of transactions")
+			return false, err
+		}
+		if exists > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
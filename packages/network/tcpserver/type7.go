@@ -21,6 +21,22 @@ package tcpserver
 		return err
 	}
 
+	// the bodies of pruned blocks are deleted, the node cannot send them
+	prunedID, err := model.GetPrunedBlockID()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pruned block")
+		return err
+	}
+	if prunedID > 0 {
+		bodies := blocks[:0]
+		for _, b := range blocks {
+			if b.ID > prunedID {
+				bodies = append(bodies, b)
+			}
+		}
+		blocks = bodies
+	}
+
 	if err := network.WriteInt(int64(len(blocks)), w); err != nil {
 		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("on sending requested blocks count")
 		return err
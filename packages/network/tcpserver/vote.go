@@ -0,0 +1,48 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpserver
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TypeVote writes the hash of the specified block signed by the node key.
+// The request is sent by 'confirmations' daemon of honor nodes
+func TypeVote(r *network.VoteRequest) (*network.VoteResponse, error) {
+	resp := &network.VoteResponse{}
+	nodeKey := syspar.GetNodePubKey()
+	if _, err := syspar.GetNodePositionByPublicKey(nodeKey); err != nil {
+		// only honor nodes vote
+		return resp, nil
+	}
+	block := &model.Block{}
+	found, err := block.Get(r.BlockID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": r.BlockID}).Error("getting block")
+		return resp, nil
+	}
+	if !found {
+		return resp, nil
+	}
+	vote, err := utils.SignVote(model.NodeVotes{}, block.ID, block.Hash, nodeKey, syspar.GetNodePrivKey())
+	if err == utils.ErrVoteConflict {
+		log.WithFields(log.Fields{"type": consts.BlockError, "error": err, "block_id": r.BlockID}).Warning("refusing to vote")
+		return resp, nil
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "block_id": r.BlockID}).Error("signing vote")
+		return resp, nil
+	}
+	resp.Hash = vote.Hash
+	resp.NodeKey = vote.NodeKey
+	resp.Sign = vote.Sign
+	return resp, nil
+}
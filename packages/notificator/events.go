@@ -0,0 +1,135 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package notificator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/publisher"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// EventsChannelPrefix is the prefix of the channel where the events of contracts are published,
+	// it's followed by the ecosystem of the event
+	EventsChannelPrefix = "events."
+	// ReorgsChannel is the channel of centrifugo where the replacements of blocks are published
+	ReorgsChannel = "reorgs"
+	// BlocksChannel is the channel where the headers of new blocks are published
+	BlocksChannel = "blocks"
+	// TxChannelPrefix is the prefix of the channel of the transaction status, it's followed by the hex hash
+	TxChannelPrefix = "tx."
+	// RoleChannelPrefix is the prefix of the channel of the role, it's followed by "<ecosystem>.<role>"
+	RoleChannelPrefix = "role."
+	// TableChannelPrefix is the prefix of the channel of the table, it's followed by the full table name
+	TableChannelPrefix = "table."
+	// AccountChannelPrefix is the prefix of the channel of the account
+	AccountChannelPrefix = "client"
+)
+
+// TxChannel returns the channel of the transaction status
+func TxChannel(hash []byte) string {
+	return fmt.Sprintf("%s%x", TxChannelPrefix, hash)
+}
+
+// EventsChannel returns the channel of the events of the ecosystem
+func EventsChannel(ecosystemID int64) string {
+	return fmt.Sprintf("%s%d", EventsChannelPrefix, ecosystemID)
+}
+
+// RoleChannel returns the channel of the role of the ecosystem
+func RoleChannel(ecosystemID, roleID int64) string {
+	return fmt.Sprintf("%s%d.%d", RoleChannelPrefix, ecosystemID, roleID)
+}
+
+func publish(channel string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err, "channel": channel}).Error("marshalling data")
+		return
+	}
+	if err = publisher.Publish(channel, data); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "channel": channel}).Debug("publishing data")
+	}
+}
+
+// SendBlock publishes the header of the committed block
+func SendBlock(blockID, time, keyID int64, nodePosition int64, hash []byte, txCount int) {
+	publish(BlocksChannel, map[string]interface{}{
+		"block_id":      blockID,
+		"hash":          fmt.Sprintf("%x", hash),
+		"time":          time,
+		"key_id":        keyID,
+		"node_position": nodePosition,
+		"tx_count":      txCount,
+	})
+}
+
+// SendTxStatus publishes the status of the transaction. The transaction is included
+// in the block if blockID isn't zero, otherwise it has been rejected with errText
+func SendTxStatus(hash []byte, blockID int64, errText string) {
+	status := map[string]interface{}{
+		"hash": fmt.Sprintf("%x", hash),
+	}
+	if blockID != 0 {
+		status["block_id"] = blockID
+	} else {
+		status["error"] = errText
+	}
+	publish(TxChannel(hash), status)
+}
+
+// SendTableChanges publishes the modified rows of every table
+func SendTableChanges(blockID int64, changes map[string][]string) {
+	tables := make([]string, 0, len(changes))
+	for table := range changes {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		publish(TableChannelPrefix+table, map[string]interface{}{
+			"block_id": blockID,
+			"table":    table,
+			"ids":      changes[table],
+		})
+	}
+}
+
+// SendEvents publishes the events of the committed block to the channels of their ecosystems
+func SendEvents(events []*model.ContractEvent) {
+	for _, event := range events {
+		data, err := json.Marshal(event.Info())
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling contract event")
+			continue
+		}
+		if err = publisher.Publish(EventsChannel(event.EcosystemID), data); err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Debug("publishing contract event")
+		}
+	}
+}
+
+// SendReorg publishes the replacement of blocks with another branch
+func SendReorg(reorg *model.Reorg) {
+	data, err := json.Marshal(map[string]interface{}{
+		"block_id": reorg.BlockID,
+		"depth":    reorg.Depth,
+		"old_hash": reorg.OldHash,
+		"new_hash": reorg.NewHash,
+		"txs":      json.RawMessage(reorg.Txs),
+	})
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling reorg")
+		return
+	}
+	if err = publisher.Publish(ReorgsChannel, data); err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Debug("publishing reorg")
+	}
+}
@@ -37,6 +37,9 @@ func UpdateNotifications(ecosystemID int64, accounts []string) {
 func UpdateRolesNotifications(ecosystemID int64, roles []int64) {
 	members, _ := model.GetRoleMembers(nil, ecosystemID, roles)
 	UpdateNotifications(ecosystemID, members)
+	for _, role := range roles {
+		publish(RoleChannel(ecosystemID, role), map[string]interface{}{"ecosystem": ecosystemID, "role_id": role})
+	}
 }
 
 func getEcosystemNotificationStats(ecosystemID int64, users []string) (map[string]*[]notificationRecord, error) {
@@ -51,4 +51,12 @@ func (q *Queue) Send() {
 	}
 }
 
+// Clone returns the copy of the queue which is restored if try block of the contract fails
+func (q *Queue) Clone() types.Notifications {
+	return &Queue{
+		Accounts: append([]*Accounts{}, q.Accounts...),
+		Roles:    append([]*Roles{}, q.Roles...),
+	}
+}
+
 func NewQueue() types.Notifications {
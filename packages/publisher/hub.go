@@ -0,0 +1,239 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxClientChannels is the max count of channels of one WebSocket client
+	maxClientChannels = 100
+	// clientQueueSize is the count of messages which are queued for the client,
+	// the client is disconnected if it doesn't read them
+	clientQueueSize = 256
+
+	methodSubscribe   = "subscribe"
+	methodUnsubscribe = "unsubscribe"
+)
+
+var (
+	errUnknownMethod = errors.New("Unknown method")
+	errManyChannels  = errors.New("Too many channels")
+)
+
+// Hub delivers the published data to the WebSocket clients of the node
+type Hub struct {
+	mu       sync.RWMutex
+	channels map[string]map[*wsClient]struct{}
+}
+
+// NewHub returns the new hub without clients
+func NewHub() *Hub {
+	return &Hub{channels: make(map[string]map[*wsClient]struct{})}
+}
+
+// Publish sends the data to the subscribers of the channel
+func (h *Hub) Publish(ctx context.Context, channel string, data []byte) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	clients := h.channels[channel]
+	if len(clients) == 0 {
+		return nil
+	}
+	msg := &wsMessage{Channel: channel, Data: json.RawMessage(data)}
+	if !json.Valid(data) {
+		str, _ := json.Marshal(string(data))
+		msg.Data = str
+	}
+	out, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	for c := range clients {
+		c.send(out)
+	}
+	return nil
+}
+
+// Clients returns the count of subscribed clients
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	clients := make(map[*wsClient]struct{})
+	for _, list := range h.channels {
+		for c := range list {
+			clients[c] = struct{}{}
+		}
+	}
+	return len(clients)
+}
+
+func (h *Hub) subscribe(c *wsClient, channel string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if c.channels[channel] {
+		return nil
+	}
+	if len(c.channels) >= maxClientChannels {
+		return errManyChannels
+	}
+	clients, ok := h.channels[channel]
+	if !ok {
+		clients = make(map[*wsClient]struct{})
+		h.channels[channel] = clients
+	}
+	clients[c] = struct{}{}
+	c.channels[channel] = true
+	return nil
+}
+
+func (h *Hub) unsubscribe(c *wsClient, channel string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(c, channel)
+}
+
+func (h *Hub) remove(c *wsClient, channel string) {
+	delete(c.channels, channel)
+	if clients, ok := h.channels[channel]; ok {
+		delete(clients, c)
+		if len(clients) == 0 {
+			delete(h.channels, channel)
+		}
+	}
+}
+
+func (h *Hub) removeClient(c *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for channel := range c.channels {
+		h.remove(c, channel)
+	}
+}
+
+// wsRequest is the request of the client
+type wsRequest struct {
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Channel string `json:"channel"`
+}
+
+// wsResponse is the response to the request of the client
+type wsResponse struct {
+	ID     int64  `json:"id"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// wsMessage is the data which is published to the channel
+type wsMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type wsClient struct {
+	conn     *wsConn
+	queue    chan []byte
+	done     chan struct{}
+	once     sync.Once
+	channels map[string]bool // guarded by the mutex of hub
+}
+
+// send queues the message, the slow client is disconnected
+func (c *wsClient) send(msg []byte) {
+	select {
+	case c.queue <- msg:
+	case <-c.done:
+	default:
+		c.close()
+	}
+}
+
+func (c *wsClient) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *wsClient) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.queue:
+			err = c.conn.WriteMessage(msg)
+		case <-ticker.C:
+			err = c.conn.Ping()
+		}
+		if err != nil {
+			c.close()
+			return
+		}
+	}
+}
+
+// ServeWebSocket switches the request to WebSocket and serves the subscriptions of the client.
+// authorize checks that the client can subscribe to the channel
+func (h *Hub) ServeWebSocket(w http.ResponseWriter, r *http.Request, authorize func(channel string) error) {
+	conn, err := upgradeWS(w, r)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "origin": r.Header.Get("Origin")}).Debug("upgrading to websocket")
+		return
+	}
+	c := &wsClient{
+		conn:     conn,
+		queue:    make(chan []byte, clientQueueSize),
+		done:     make(chan struct{}),
+		channels: make(map[string]bool),
+	}
+	defer h.removeClient(c)
+	defer c.close()
+	go c.writeLoop()
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req wsRequest
+		resp := &wsResponse{}
+		if err = json.Unmarshal(data, &req); err == nil {
+			resp.ID = req.ID
+			switch req.Method {
+			case methodSubscribe:
+				if err = authorize(req.Channel); err == nil {
+					err = h.subscribe(c, req.Channel)
+				}
+			case methodUnsubscribe:
+				h.unsubscribe(c, req.Channel)
+			default:
+				err = errUnknownMethod
+			}
+		}
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Result = req.Method
+		}
+		out, _ := json.Marshal(resp)
+		c.send(out)
+	}
+}
@@ -36,12 +36,40 @@ func (cn *ClientsChannels) Get(id int64) string {
 	return cn.storage[id]
 }
 
+// Backend delivers the published data to the subscribers of the channel
+type Backend interface {
+	Publish(ctx context.Context, channel string, data []byte) error
+}
+
+// centrifugoBackend publishes data to centrifugo if it is configured
+type centrifugoBackend struct{}
+
+func (centrifugoBackend) Publish(ctx context.Context, channel string, data []byte) error {
+	if publisher == nil {
+		return nil
+	}
+	return publisher.Publish(ctx, channel, data)
+}
+
 var (
 	clientsChannels   = ClientsChannels{storage: make(map[int64]string)}
 	centrifugoTimeout = time.Second * 5
 	publisher         *gocent.Client
 	config            conf.CentrifugoConfig
+
+	// WebSocketHub is the built-in WebSocket server of the node
+	WebSocketHub = NewHub()
+
+	backendsMutex sync.RWMutex
+	backends      = []Backend{centrifugoBackend{}, WebSocketHub}
 )
+
+// RegisterBackend adds the backend which receives all published data
+func RegisterBackend(b Backend) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	backends = append(backends, b)
+}
 		Addr: cfg.URL,
 		Key:  cfg.Key,
 	})
@@ -66,11 +94,26 @@ func GetJWTCent(userID, expire int64) (string, string, error) {
 	return result, timestamp, nil
 }
 
-// Write is publishing data to server
+// Write is publishing data to the channel of the account
 func Write(account string, data string) error {
+	return Publish("client"+account, []byte(data))
+}
+
+// Publish is publishing data to the channel of all backends. The first error is returned
+// but the data is published to the rest of backends
+func Publish(channel string, data []byte) error {
 	ctx, cancel := context.WithTimeout(context.Background(), centrifugoTimeout)
 	defer cancel()
-	return publisher.Publish(ctx, "client"+account, []byte(data))
+
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+	var result error
+	for _, b := range backends {
+		if err := b.Publish(ctx, channel, data); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
 }
 
 // GetStats returns Stats
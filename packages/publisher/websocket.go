@@ -0,0 +1,94 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package publisher
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsMaxMessageSize is the max size of the message of client
+	wsMaxMessageSize = 64 << 10
+	// wsWriteTimeout is the time for writing one message
+	wsWriteTimeout = 10 * time.Second
+	// wsReadTimeout is the time of waiting the next message, the pings of the server are answered earlier
+	wsReadTimeout = 60 * time.Second
+	// wsPingPeriod is the period of sending pings to the client
+	wsPingPeriod = 30 * time.Second
+)
+
+var upgrader = websocket.Upgrader{CheckOrigin: checkOrigin}
+
+// checkOrigin allows the clients without Origin such as other services, the pages of the same host
+// and the pages of the origins from the WebSocketOrigins config
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range conf.Config.WebSocketOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// wsConn is the WebSocket connection of the client. The messages are written by one goroutine
+type wsConn struct {
+	conn *websocket.Conn
+}
+
+// upgradeWS switches the connection to WebSocket, the error response is written by the upgrader
+func upgradeWS(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	})
+	return &wsConn{conn: conn}, nil
+}
+
+// ReadMessage returns the next text or binary message of the client. The control messages
+// are answered by the connection
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	_, data, err := c.conn.ReadMessage()
+	return data, err
+}
+
+// WriteMessage writes the text message to the client
+func (c *wsConn) WriteMessage(data []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// Ping sends the ping to the client
+func (c *wsConn) Ping() error {
+	return c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+}
+
+// Close closes the connection
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,100 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package publisher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(hub *Hub) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.ServeWebSocket(w, r, func(channel string) error {
+			if channel == "private" {
+				return errors.New("Permission denied")
+			}
+			return nil
+		})
+	}))
+}
+
+func dialWS(url string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http"), header)
+}
+
+func TestWebSocketHub(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(hub)
+	defer srv.Close()
+
+	c, _, err := dialWS(srv.URL, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	request := func(msg, answer string) {
+		require.NoError(t, c.WriteMessage(websocket.TextMessage, []byte(msg)))
+		read(t, c, answer)
+	}
+	request(`{"id":1,"method":"subscribe","channel":"blocks"}`, `{"id":1,"result":"subscribe"}`)
+	request(`{"id":2,"method":"subscribe","channel":"private"}`, `{"id":2,"error":"Permission denied"}`)
+	request(`{"id":3,"method":"publish","channel":"blocks"}`, `{"id":3,"error":"Unknown method"}`)
+	assert.Equal(t, 1, hub.Clients())
+
+	require.NoError(t, hub.Publish(context.Background(), "private", []byte(`{"secret":1}`)))
+	require.NoError(t, hub.Publish(context.Background(), "blocks", []byte(`{"block_id":10}`)))
+	read(t, c, `{"channel":"blocks","data":{"block_id":10}}`)
+	require.NoError(t, hub.Publish(context.Background(), "blocks", []byte("text")))
+	read(t, c, `{"channel":"blocks","data":"text"}`)
+
+	request(`{"id":4,"method":"unsubscribe","channel":"blocks"}`, `{"id":4,"result":"unsubscribe"}`)
+	assert.Equal(t, 0, hub.Clients())
+}
+
+func read(t *testing.T, c *websocket.Conn, expected string) {
+	_, data, err := c.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(hub)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	hub := NewHub()
+	srv := newTestServer(hub)
+	defer srv.Close()
+	defer func(origins []string) { conf.Config.WebSocketOrigins = origins }(conf.Config.WebSocketOrigins)
+	conf.Config.WebSocketOrigins = []string{"https://wallet.example.com"}
+
+	// the pages of other sites are rejected
+	_, resp, err := dialWS(srv.URL, http.Header{"Origin": {"https://evil.example.com"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	for _, origin := range []string{"https://wallet.example.com", srv.URL} {
+		c, _, err := dialWS(srv.URL, http.Header{"Origin": {origin}})
+		require.NoError(t, err, origin)
+		c.Close()
+	}
+}
@@ -19,7 +19,8 @@ import (
 )
 
 var (
-	ErrLastBlock = errors.New("Block is not the last")
+	ErrLastBlock      = errors.New("Block is not the last")
+	ErrFinalizedBlock = errors.New("Block is finalized")
 )
 
 // BlockRollback is blocking rollback
@@ -37,6 +38,9 @@ func RollbackBlock(data []byte) error {
 	if b.ID != bl.Header.BlockID {
 		return ErrLastBlock
 	}
+	if err = CheckFinalized(bl.Header.BlockID); err != nil {
+		return err
+	}
 
 		return err
 	}
@@ -89,6 +93,12 @@ func rollbackBlock(dbTransaction *model.DbTransaction, block *block.Block) error
 			return err
 		}
 
+		_, err = model.DeleteContractEventsByHash(dbTransaction, t.TxHash)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting contract events by hash")
+			return err
+		}
+
 		ts := &model.TransactionStatus{}
 		err = ts.UpdateBlockID(dbTransaction, 0, t.TxHash)
 		if err != nil {
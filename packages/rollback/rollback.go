@@ -19,8 +19,26 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// CheckFinalized returns ErrFinalizedBlock if the blocks from blockID cannot be rolled back
+// because the block with blockID or a later one has the finality certificate
+func CheckFinalized(blockID int64) error {
+	finalID, err := model.GetFinalizedBlockID(nil)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting finalized block")
+		return err
+	}
+	if blockID <= finalID {
+		log.WithFields(log.Fields{"type": consts.BlockError, "block_id": blockID, "finalized_block_id": finalID}).Error("rollback of finalized block")
+		return ErrFinalizedBlock
+	}
+	return nil
+}
+
 // ToBlockID rollbacks blocks till blockID
 func ToBlockID(blockID int64, dbTransaction *model.DbTransaction, logger *log.Entry) error {
+	if err := CheckFinalized(blockID + 1); err != nil {
+		return err
+	}
 	_, err := model.MarkVerifiedAndNotUsedTransactionsUnverified()
 	if err != nil {
 		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("marking verified and not used transactions unverified")
@@ -15,6 +15,11 @@ package script
 	cmdMapInit               // map initialization
 	cmdArrayInit             // array initialization
 	cmdError                 // error command
+	cmdFor                   // for ... in
+	cmdTry                   // try ... catch
+	cmdForNext               // next iteration of for ... in
+	cmdForItem               // push the current item of for ... in
+	cmdCopyIndex             // copy the object and the index for compound assignment []
 )
 
 // the commands for operations in expressions are listed below
@@ -36,6 +41,12 @@ const (
 	cmdNotLess
 	cmdGreat
 	cmdNotGreat
+	cmdMod
+	cmdBitAnd
+	cmdBitOr
+	cmdBitXor
+	cmdShiftL
+	cmdShiftR
 
 	cmdSys          = 0xff
 	cmdUnary uint16 = 50
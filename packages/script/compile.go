@@ -97,6 +97,9 @@ const (
 	stateConstsAssign
 	stateConstsValue
 	stateFields
+	stateFor
+	stateCatch
+	stateView
 	stateEval
 
 	// The list of state flags
@@ -158,6 +161,14 @@ const (
 	cfContinue
 	cfBreak
 	cfCmdError
+	cfAssignOper
+	cfFor
+	cfForVar
+	cfForIn
+	cfTry
+	cfCatch
+	cfCatchVar
+	cfView
 
 //	cfEval
 )
@@ -167,8 +178,15 @@ var (
 	opers = map[uint32]operPrior{
 		isOr: {cmdOr, 10}, isAnd: {cmdAnd, 15}, isEqEq: {cmdEqual, 20}, isNotEq: {cmdNotEq, 20},
 		isLess: {cmdLess, 22}, isGrEq: {cmdNotLess, 22}, isGreat: {cmdGreat, 22}, isLessEq: {cmdNotGreat, 22},
-		isPlus: {cmdAdd, 25}, isMinus: {cmdSub, 25}, isAsterisk: {cmdMul, 30},
-		isSolidus: {cmdDiv, 30}, isSign: {cmdSign, cmdUnary}, isNot: {cmdNot, cmdUnary}, isLPar: {cmdSys, 0xff}, isRPar: {cmdSys, 0},
+		isPlus: {cmdAdd, 25}, isMinus: {cmdSub, 25}, isBitOr: {cmdBitOr, 25}, isBitXor: {cmdBitXor, 25},
+		isAsterisk: {cmdMul, 30}, isSolidus: {cmdDiv, 30}, isPercent: {cmdMod, 30}, isBitAnd: {cmdBitAnd, 30},
+		isShl: {cmdShiftL, 30}, isShr: {cmdShiftR, 30},
+		isSign: {cmdSign, cmdUnary}, isNot: {cmdNot, cmdUnary}, isLPar: {cmdSys, 0xff}, isRPar: {cmdSys, 0},
+	}
+	// The compound assignments and the operations which they perform
+	assignOpers = map[uint32]uint16{
+		isAddEq: cmdAdd, isSubEq: cmdSub, isMulEq: cmdMul, isDivEq: cmdDiv, isModEq: cmdMod,
+		isAndEq: cmdBitAnd, isOrEq: cmdBitOr, isXorEq: cmdBitXor, isShlEq: cmdShiftL, isShrEq: cmdShiftR,
 	}
 	// The array of functions corresponding to the constants cf...
 	funcs = []compileFunc{nil,
@@ -198,6 +216,14 @@ var (
 		fContinue,
 		fBreak,
 		fCmdError,
+		fAssignOper,
+		fFor,
+		fForVar,
+		fForIn,
+		fTry,
+		fCatch,
+		fCatchVar,
+		fView,
 	}
 
 	// 'states' describes a finite machine with states on the base of which a bytecode will be generated
@@ -216,7 +242,11 @@ var (
 			lexKeyword | (keyBreak << 8):    {stateBody, cfBreak},
 			lexKeyword | (keyIf << 8):       {stateEval | statePush | stateToBlock | stateMustEval, cfIf},
 			lexKeyword | (keyWhile << 8):    {stateEval | statePush | stateToBlock | stateLabel | stateMustEval, cfWhile},
+			lexKeyword | (keyFor << 8):      {stateFor, cfFor},
 			lexKeyword | (keyElse << 8):     {stateBlock | statePush, cfElse},
+			lexKeyword | (keyTry << 8):      {stateBlock | statePush, cfTry},
+			lexKeyword | (keyCatch << 8):    {stateCatch | statePush, cfCatch},
+			lexKeyword | (keyView << 8):     {stateView, 0},
 			lexKeyword | (keyVar << 8):      {stateVar, 0},
 			lexKeyword | (keyTX << 8):       {stateTX, cfTX},
 			lexKeyword | (keySettings << 8): {stateSettings, cfSettings},
@@ -304,6 +334,7 @@ var (
 			lexIdent:  {stateAssign, cfAssignVar},
 			lexExtend: {stateAssign, cfAssignVar},
 			isEq:      {stateEval | stateToBody, cfAssign},
+			lexOper:   {stateEval | stateToBody, cfAssignOper},
 			0:         {errAssign, cfError},
 		},
 		{ // stateTX
@@ -341,6 +372,23 @@ var (
 			isRCurly:   {stateToBody, cfFields},
 			0:          {errMustRCurly, cfError},
 		},
+		{ // stateFor
+			lexIdent:                  {stateFor, cfForVar},
+			isComma:                   {stateFor, 0},
+			lexKeyword | (keyIn << 8): {stateEval | statePush | stateToBlock | stateMustEval, cfForIn},
+			0:                         {errVars, cfError},
+		},
+		{ // stateCatch
+			lexNewLine: {stateCatch, 0},
+			lexIdent:   {stateBlock, cfCatchVar},
+			isLCurly:   {stateBody, 0},
+			0:          {errMustLCurly, cfError},
+		},
+		{ // stateView
+			lexNewLine:                  {stateView, 0},
+			lexKeyword | (keyFunc << 8): {stateFunc | statePush, cfView},
+			0:                           {errUnknownCmd, cfError},
+		},
 	}
 )
 
@@ -515,6 +563,100 @@ func fWhile(buf *[]*Block, state int, lexem *Lexem) error {
 	return nil
 }
 
+// fFor adds for ... in command. The command gets its body in fForIn when
+// the expression of the iterated value has been compiled
+func fFor(buf *[]*Block, state int, lexem *Lexem) error {
+	(*(*buf)[len(*buf)-1]).Code = append((*(*buf)[len(*buf)-1]).Code, &ByteCode{cmdFor,
+		lexem.Line, &ForInfo{}})
+	return nil
+}
+
+func fForVar(buf *[]*Block, state int, lexem *Lexem) error {
+	code := (*(*buf)[len(*buf)-1]).Code
+	forInfo := code[len(code)-1].Value.(*ForInfo)
+	if len(forInfo.Vars) == 2 {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error(errForVars)
+		return errForVars
+	}
+	forInfo.Vars = append(forInfo.Vars, lexem.Value.(string))
+	return nil
+}
+
+// fForIn declares the loop variables in the body and moves for command after the expression.
+// The loop is compiled like while: the iterator is kept in the hidden variable of the outer block,
+// cmdForNext is the condition and the body assigns the current item to the loop variables
+func fForIn(buf *[]*Block, state int, lexem *Lexem) error {
+	prev := (*buf)[len(*buf)-2]
+	block := (*buf)[len(*buf)-1]
+	ind := len(prev.Code) - 1
+	for ; ind >= 0 && prev.Code[ind].Cmd != cmdFor; ind-- {
+	}
+	forCmd := prev.Code[ind]
+	forInfo := forCmd.Value.(*ForInfo)
+	if len(forInfo.Vars) == 0 {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error(errForVars)
+		return errForVars
+	}
+	prev.Code = append(prev.Code[:ind], prev.Code[ind+1:]...)
+	forInfo.Iter = &VarInfo{&ObjInfo{Type: ObjVar, Value: len(prev.Vars)}, prev}
+	prev.Vars = append(prev.Vars, reflect.TypeOf((*interface{})(nil)).Elem())
+	prev.Code = append(prev.Code, forCmd, &ByteCode{cmdLabel, lexem.Line, 0},
+		&ByteCode{cmdForNext, lexem.Line, forInfo}, &ByteCode{cmdWhile, lexem.Line, block},
+		&ByteCode{cmdContinue, lexem.Line, 0})
+
+	if block.Objects == nil {
+		block.Objects = make(map[string]*ObjInfo)
+	}
+	vars := make([]*VarInfo, 0, len(forInfo.Vars))
+	for _, name := range forInfo.Vars {
+		objInfo := &ObjInfo{Type: ObjVar, Value: len(block.Vars)}
+		block.Objects[name] = objInfo
+		block.Vars = append(block.Vars, reflect.TypeOf((*interface{})(nil)).Elem())
+		vars = append(vars, &VarInfo{objInfo, block})
+	}
+	block.Code = append(block.Code, &ByteCode{cmdForItem, lexem.Line, forInfo},
+		&ByteCode{cmdAssignVar, lexem.Line, vars}, &ByteCode{cmdAssign, lexem.Line, 0})
+	return nil
+}
+
+func fTry(buf *[]*Block, state int, lexem *Lexem) error {
+	(*(*buf)[len(*buf)-2]).Code = append((*(*buf)[len(*buf)-2]).Code, &ByteCode{cmdTry,
+		lexem.Line, &TryInfo{Try: (*buf)[len(*buf)-1]}})
+	return nil
+}
+
+func fCatch(buf *[]*Block, state int, lexem *Lexem) error {
+	code := (*(*buf)[len(*buf)-2]).Code
+	if len(code) == 0 || code[len(code)-1].Cmd != cmdTry || code[len(code)-1].Value.(*TryInfo).Catch != nil {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error(errCatch)
+		return errCatch
+	}
+	code[len(code)-1].Value.(*TryInfo).Catch = (*buf)[len(*buf)-1]
+	return nil
+}
+
+// fCatchVar declares the variable of catch block. The description of the error
+// is assigned from the stack at the beginning of the block
+func fCatchVar(buf *[]*Block, state int, lexem *Lexem) error {
+	block := (*buf)[len(*buf)-1]
+	if block.Objects == nil {
+		block.Objects = make(map[string]*ObjInfo)
+	}
+	objInfo := &ObjInfo{Type: ObjVar, Value: len(block.Vars)}
+	block.Objects[lexem.Value.(string)] = objInfo
+	block.Vars = append(block.Vars, reflect.TypeOf((*interface{})(nil)).Elem())
+	block.Code = append(block.Code, &ByteCode{cmdAssignVar, lexem.Line, []*VarInfo{{objInfo, block}}},
+		&ByteCode{cmdAssign, lexem.Line, 0})
+	return nil
+}
+
+// fView marks the function as read-only. Such function cannot call contracts and functions
+// which can modify the blockchain database
+func fView(buf *[]*Block, state int, lexem *Lexem) error {
+	(*buf)[len(*buf)-1].Info = &FuncInfo{View: true}
+	return nil
+}
+
 func fContinue(buf *[]*Block, state int, lexem *Lexem) error {
 	(*(*buf)[len(*buf)-1]).Code = append((*(*buf)[len(*buf)-1]).Code, &ByteCode{cmdContinue,
 		lexem.Line, 0})
@@ -566,6 +708,36 @@ func fAssign(buf *[]*Block, state int, lexem *Lexem) error {
 	return nil
 }
 
+// fAssignOper compiles 'x op= expr' as 'x = x op expr'. It is called when the expression
+// has already been compiled so the value of the variable is inserted before it
+func fAssignOper(buf *[]*Block, state int, lexem *Lexem) error {
+	block := (*buf)[len(*buf)-1]
+	oper, ok := assignOpers[lexem.Value.(uint32)]
+	if !ok {
+		return fError(buf, errAssign, lexem)
+	}
+	ind := len(block.Code) - 1
+	for ; ind >= 0 && block.Code[ind].Cmd != cmdAssignVar; ind-- {
+	}
+	if ind < 0 || ind == len(block.Code)-1 {
+		return errEndExp
+	}
+	vars := block.Code[ind].Value.([]*VarInfo)
+	if len(vars) != 1 {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error(errAssignOper)
+		return errAssignOper
+	}
+	value := &ByteCode{cmdVar, lexem.Line, vars[0]}
+	if vars[0].Obj.Type == ObjExtend {
+		value = &ByteCode{cmdExtend, lexem.Line, vars[0].Obj.Value.(string)}
+	}
+	code := append(ByteCodes{value}, block.Code[ind+1:]...)
+	block.Code = append(block.Code[:ind+1], code...)
+	block.Code = append(block.Code, &ByteCode{oper, lexem.Line, uint16(0)},
+		&ByteCode{cmdAssign, lexem.Line, 0})
+	return nil
+}
+
 func fTx(buf *[]*Block, state int, lexem *Lexem) error {
 	contract := (*buf)[len(*buf)-1]
 	logger := lexem.GetLogger()
@@ -709,7 +881,9 @@ func fNameBlock(buf *[]*Block, state int, lexem *Lexem) error {
 			Owner: (*buf)[0].Owner}
 	default:
 		itype = ObjFunc
-		fblock.Info = &FuncInfo{}
+		if _, ok := fblock.Info.(*FuncInfo); !ok {
+			fblock.Info = &FuncInfo{}
+		}
 	}
 	fblock.Type = itype
 	prev.Objects[name] = &ObjInfo{Type: itype, Value: fblock}
@@ -822,11 +996,29 @@ func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (*Block, error) {
 			}
 		}
 	}
+	if err = checkView(root); err != nil {
+		return nil, err
+	}
 	return root, nil
 }
 
+// checkView returns error if some view function can modify the blockchain database
+func checkView(block *Block) error {
+	for _, child := range block.Children {
+		if child.Type == ObjFunc && child.Info.(*FuncInfo).View && child.Info.(*FuncInfo).CanWrite {
+			return errViewWrite
+		}
+		if err := checkView(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // FlushBlock loads the compiled Block into the virtual machine
 func (vm *VM) FlushBlock(root *Block) {
+	vm.Lock()
+	defer vm.Unlock()
 	shift := len(vm.Children)
 	for key, item := range root.Objects {
 		if cur, ok := vm.Objects[key]; ok {
@@ -1080,7 +1272,10 @@ func setWritable(block *[]*Block) {
 
 // This function is responsible for the compilation of expressions
 func (vm *VM) compileEval(lexems *Lexems, ind *int, block *[]*Block) error {
-	var indexInfo *IndexInfo
+	var (
+		indexInfo  *IndexInfo
+		assignOper *ByteCode
+	)
 
 	i := *ind
 	curBlock := (*block)[len(*block)-1]
@@ -1271,6 +1466,18 @@ main:
 						noMap = false
 						continue
 					}
+					// 'x[i] op= expr' is compiled as 'x[i] = x[i] op expr', the index is evaluated once
+					if i < len(*lexems)-1 && (*lexems)[i+1].Type == lexOper {
+						if oper, ok := assignOpers[(*lexems)[i+1].Value.(uint32)]; ok {
+							i++
+							setIndex = true
+							indexInfo = prev.Value.(*IndexInfo)
+							assignOper = &ByteCode{oper, lexem.Line, uint16(0)}
+							bytecode = append(bytecode, &ByteCode{cmdCopyIndex, lexem.Line, 0}, prev)
+							noMap = false
+							continue
+						}
+					}
 					bytecode = append(bytecode, prev)
 				}
 			}
@@ -1278,6 +1485,14 @@ main:
 				return errMultiIndex
 			}
 		case lexOper:
+			if _, ok := assignOpers[lexem.Value.(uint32)]; ok {
+				if i == *ind {
+					// the compound assignment is compiled by fAssignOper
+					continue
+				}
+				logger.WithFields(log.Fields{"type": consts.ParseError}).Error(errAssignOperIndex)
+				return errAssignOperIndex
+			}
 			if oper, ok := opers[lexem.Value.(uint32)]; ok {
 				var prevType uint32
 				if i > 0 {
@@ -1443,6 +1658,9 @@ main:
 		bytecode = append(bytecode, buffer[i])
 	}
 	if setIndex {
+		if assignOper != nil {
+			bytecode = append(bytecode, assignOper)
+		}
 		bytecode = append(bytecode, &ByteCode{cmdSetIndex, 0, indexInfo})
 	}
 	curBlock.Code = append(curBlock.Code, bytecode...)
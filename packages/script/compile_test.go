@@ -68,6 +68,10 @@ func outMap(v *types.Map) string {
 	return fmt.Sprint(v)
 }
 
+func dbInsert(table string, values *types.Map) int64 {
+	return 1
+}
+
 func TestVMCompile(t *testing.T) {
 	test := []TestVM{
 		{`contract sets {
@@ -663,13 +667,195 @@ func TestVMCompile(t *testing.T) {
 					}
 					return out
 				}`, `bool_test`, `OKokI`},
+		{`func opers string {
+					var i, k int
+					i = 17 % 5 + 6 & 3 | 8 ^ 1 << 4
+					k = 100
+					k %= 7
+					k += 10
+					k *= 3 + 1
+					k -= 1 << 2
+					k /= 2
+					k <<= 2
+					k >>= 1
+					k |= 1
+					k ^= 3
+					k &= 254
+					$out = 10
+					$out += i
+					return Sprintf("%d %d %d", i, k, $out)
+				}`, `opers`, `28 46 38`},
+		{`func money_mod string {
+					var m money
+					m = Money(1000)
+					m %= Money(300)
+					return Sprintf("%v", m)
+				}`, `money_mod`, `100`},
+		{`func assign_oper int {
+					var i, k int
+					i, k += 1
+					return i
+				}`, `assign_oper`, `compound assignment must have one variable`},
+		{`func index_oper string {
+					var a array
+					var m map
+					var i int
+					a[0] = 5
+					a[1] = 1
+					m["k"] = 10
+					a[i] += 3
+					a[i+1] <<= 4
+					m["k"] -= 1
+					m["k"] *= a[0]
+					return Sprintf("%v %v %v", a[0], a[1], m["k"])
+				}`, `index_oper`, `8 16 72`},
+		{`func oper_target int {
+					var a array
+					Len(a) += 1
+					return 0
+				}`, `oper_target`, `compound assignment must follow a variable or an index`},
+		{`func for_array string {
+					var arr array
+					var out string
+					var sum int
+					arr = [1, 2, 3, 4]
+					for item in arr {
+						sum += item
+					}
+					for i, item in arr {
+						if i == 1 {
+							continue
+						}
+						if i == 3 {
+							break
+						}
+						out = out + Sprintf("%d:%d ", i, item)
+					}
+					return Sprintf("%d %s", sum, out)
+				}`, `for_array`, `10 0:1 2:3 `},
+		{`func find(list array, name string) string {
+					for item in list {
+						if item["name"] == name {
+							return item["value"]
+						}
+					}
+					return "none"
+				}
+				func for_map string {
+					var m map
+					var out string
+					m = {b: 2, a: 1, c: "x"}
+					for key, value in m {
+						out = out + Sprintf("%s=%v;", key, value)
+					}
+					for key in m {
+						out = out + key
+					}
+					return out + find([{name: "a", value: "1"}, {name: "b", value: "2"}], "b")
+				}`, `for_map`, `a=1;b=2;c=x;abc2`},
+		{`func for_vars int {
+					var arr array
+					for i, item, k in arr {
+					}
+					return 0
+				}`, `for_vars`, `for ... in must have one or two variables`},
+		{`func for_nested string {
+					var out string
+					var i int
+					for x in [1, 2, 3] {
+						for y in [1, 2, 3] {
+							if y > x {
+								break
+							}
+							out = out + Sprintf("%d%d ", x, y)
+						}
+						i = i + 1
+					}
+					return Sprintf("%s%d", out, i)
+				}`, `for_nested`, `11 21 22 31 32 33 3`},
+		{`func for_names string {
+					var in, for int
+					var arr array
+					in = 2
+					for = in + 1
+					arr = [in, for]
+					for in in arr {
+						for = for + in
+					}
+					return Sprintf("%d %d", in, for)
+				}`, `for_names`, `2 8`},
+		{`func try_catch string {
+					var out string
+					var i int
+					try {
+						i = 5
+						error "boom"
+						out = "unreachable"
+					} catch err {
+						out = Sprintf("%s:%s:%d", err["type"], err["text"], i)
+					}
+					try {
+						out = out + " ok"
+					} catch {
+						out = out + " fail"
+					}
+					try {
+						try {
+							warning "inner"
+						} catch e {
+							error e["text"] + "!"
+						}
+					} catch e {
+						out = out + " " + e["type"] + ":" + e["text"]
+					}
+					try {
+						info "skip"
+					}
+					return out
+				}`, `try_catch`, `error:boom:5 ok error:inner!`},
+		{`func catch_try int {
+					catch err {
+					}
+					return 0
+				}`, `catch_try`, `catch must follow try`},
+		{`func try_names int {
+					var try, catch int
+					try = 1
+					catch = try + 1
+					if catch {
+						try {
+							catch = catch + try
+						} catch {
+						}
+					}
+					return catch
+				}`, `try_names`, `3`},
+		{`func view_func string {
+					view func sum(a b int) int {
+						return a + b
+					}
+					return str(sum(2, 3))
+				}`, `view_func`, `5`},
+		{`contract view_write {
+					view func out string {
+						return str(DBInsert("keys", GetMap()))
+					}
+				}`, `view_write`, `view function cannot call contracts or functions which can modify the blockchain database`},
+		{`func insert_key int {
+					return DBInsert("keys", GetMap())
+				}
+				contract view_write_func {
+					view func out string {
+						return str(insert_key())
+					}
+				}`, `view_write_func`, `view function cannot call contracts or functions which can modify the blockchain database`},
 	}
 	vm := NewVM()
 	vm.Extern = true
 	vm.Extend(&ExtendData{map[string]interface{}{"Println": fmt.Println, "Sprintf": fmt.Sprintf,
 		"GetMap": getMap, "GetArray": getArray, "lenArray": lenArray, "outMap": outMap,
-		"str": str, "Money": Money, "Replace": strings.Replace}, nil,
-		map[string]struct{}{"Sprintf": {}}})
+		"str": str, "Money": Money, "Replace": strings.Replace, "DBInsert": dbInsert}, nil,
+		map[string]struct{}{"Sprintf": {}, "DBInsert": {}}})
 
 	for ikey, item := range test {
 		if ikey > 100 {
@@ -14,6 +14,7 @@ const (
 	eDataType        = `expecting type of the data field [Ln:%d Col:%d]`
 	eDataName        = `expecting name of the data field [Ln:%d Col:%d]`
 	eDataTag         = `unexpected tag [Ln:%d Col:%d]`
+	eForType         = `type %s cannot be iterated`
 )
 
 var (
@@ -35,4 +36,10 @@ var (
 	errSelfAssignment  = errors.New(`self assignment`)
 	errEndExp          = errors.New(`unexpected end of the expression`)
 	errOper            = errors.New(`unexpected operator; expecting operand`)
+	errNegativeShift   = errors.New(`negative shift amount`)
+	errAssignOper      = errors.New(`compound assignment must have one variable`)
+	errAssignOperIndex = errors.New(`compound assignment must follow a variable or an index`)
+	errForVars         = errors.New(`for ... in must have one or two variables`)
+	errCatch           = errors.New(`catch must follow try`)
+	errViewWrite       = errors.New(`view function cannot call contracts or functions which can modify the blockchain database`)
 )
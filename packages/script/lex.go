@@ -60,6 +60,24 @@ const (
 	isEqEq     = 0x3d3d // ==
 	isGrEq     = 0x3e3d // >=
 	isOr       = 0x7c7c // ||
+	isPercent  = 0x0025 // %
+	isBitAnd   = 0x0026 // &
+	isBitOr    = 0x007c // |
+	isBitXor   = 0x005e // ^
+	isShl      = 0x3c3c // <<
+	isShr      = 0x3e3e // >>
+
+	// Constants for compound assignments
+	isAddEq = 0x2b3d   // +=
+	isSubEq = 0x2d3d   // -=
+	isMulEq = 0x2a3d   // *=
+	isDivEq = 0x2f3d   // /=
+	isModEq = 0x253d   // %=
+	isAndEq = 0x263d   // &=
+	isOrEq  = 0x7c3d   // |=
+	isXorEq = 0x5e3d   // ^=
+	isShlEq = 0x3c3c3d // <<=
+	isShrEq = 0x3e3e3d // >>=
 
 )
 
@@ -88,6 +106,11 @@ const (
 	keyCond
 	keyTail
 	keyError
+	keyFor
+	keyIn
+	keyTry
+	keyCatch
+	keyView
 )
 
 const (
@@ -121,7 +144,8 @@ var (
 		msgInfo: keyInfo, `while`: keyWhile, `data`: keyTX, `settings`: keySettings, `nil`: keyNil,
 		`action`: keyAction, `conditions`: keyCond,
 		`true`: keyTrue, `false`: keyFalse, `break`: keyBreak, `continue`: keyContinue,
-		`var`: keyVar, `...`: keyTail}
+		`var`: keyVar, `...`: keyTail, `for`: keyFor, `in`: keyIn,
+		`try`: keyTry, `catch`: keyCatch, `view`: keyView}
 
 	// list of available types
 	// The list of types which save the corresponding 'reflect' type
@@ -351,9 +375,60 @@ func lexParser(input []rune) (Lexems, error) {
 			off++
 		}
 	}
+	contextKeywords(lexems)
 	return lexems, nil
 }
 
+// contextKeywords turns the keywords of for ... in and try ... catch into identifiers outside
+// of their statements. Such sequences were not valid before, so the sources with these names
+// are compiled as earlier
+func contextKeywords(lexems Lexems) {
+	isType := func(i int, lexType uint32) bool {
+		return i >= 0 && i < len(lexems) && lexems[i].Type == lexType
+	}
+	isKey := func(i int, key uint32) bool {
+		return isType(i, lexKeyword|(key<<8))
+	}
+	skipLines := func(i int) int {
+		for isType(i, lexNewLine) {
+			i++
+		}
+		return i
+	}
+	toIdent := func(i int, name string) {
+		lexems[i].Type = lexIdent
+		lexems[i].Value = name
+	}
+	isStatement := func(i int) bool {
+		return i == 0 || isType(i-1, lexNewLine) || isType(i-1, isLCurly) || isType(i-1, isRCurly)
+	}
+	for i := range lexems {
+		switch {
+		case isKey(i, keyFor):
+			if !isType(i+1, lexIdent) && !isKey(i+1, keyIn) {
+				toIdent(i, `for`)
+			}
+		case isKey(i, keyIn):
+			if !(isKey(i-2, keyFor) && isType(i-1, lexIdent)) && !(isKey(i-4, keyFor) &&
+				isType(i-3, lexIdent) && isType(i-2, isComma) && isType(i-1, lexIdent)) {
+				toIdent(i, `in`)
+			}
+		case isKey(i, keyTry):
+			if !isStatement(i) || !isType(i+1, isLCurly) {
+				toIdent(i, `try`)
+			}
+		case isKey(i, keyCatch):
+			next := skipLines(i + 1)
+			if isType(next, lexIdent) {
+				next = skipLines(next + 1)
+			}
+			if !isStatement(i) || !isType(next, isLCurly) {
+				toIdent(i, `catch`)
+			}
+		}
+	}
+}
+
 func OriginalToString(original uint32) string {
 	for key, v := range typesMap {
 		if v.Original == original {
@@ -8,24 +8,31 @@ package script
 
 var (
 	alphabet = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-		0, 0, 0, 0, 0, 0, 0, 2, 20, 4, 14, 22, 0, 12, 0, 6, 7, 21, 25, 16, 26, 15, 27, 29,
-		30, 30, 30, 30, 30, 30, 30, 30, 30, 24, 5, 17, 19, 18, 0, 23, 31, 31, 31, 31, 31, 31, 31, 31,
-		31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 8, 28, 9, 0, 32, 3,
-		31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31, 31,
-		31, 31, 10, 13, 11, 0, 0, 33,
+		0, 0, 0, 0, 0, 0, 0, 2, 20, 4, 14, 22, 29, 12, 0, 6, 7, 21, 25, 16, 26, 15, 27, 31,
+		32, 32, 32, 32, 32, 32, 32, 32, 32, 24, 5, 17, 19, 18, 0, 23, 33, 33, 33, 33, 33, 33, 33, 33,
+		33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 8, 28, 9, 30, 34, 3,
+		33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33, 33,
+		33, 33, 10, 13, 11, 0, 0, 35,
 	}
-	lexTable = [][34]uint32{
-		{0xff0000, 0x501, 0x1, 0xe0003, 0xf0003, 0x501, 0x101, 0x101, 0x101, 0x101, 0x101, 0x101, 0x50003, 0xc0003, 0x101, 0xb0003, 0x101, 0x80003, 0x80003, 0xd0003, 0x80003, 0x201, 0x90003, 0x90003, 0x101, 0x201, 0x201, 0x70003, 0xff0000, 0x30003, 0x30003, 0x60003, 0x60003, 0x60003},
-		{0x10001, 0x0, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001},
-		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x40001, 0x204, 0x204, 0x204, 0x204, 0x204, 0x10005, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
-		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
-		{0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0x60001, 0x60001, 0x60001, 0x60001, 0x60001},
-		{0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001},
-		{0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x20001, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x30001, 0x30001, 0x104, 0x104, 0x104},
-		{0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0x205, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000},
-		{0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x205, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104},
-		{0xe0001, 0xe0001, 0xe0001, 0x605, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001},
-		{0xf0001, 0xf0001, 0xf0001, 0xf0001, 0x605, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xa0008, 0xf0001, 0xf0001, 0xf0001, 0xf0001, 0xf0001},
-		{0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x705, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001, 0x40001},
+	lexTable = [][36]uint32{
+		{0xff0000, 0x501, 0x1, 0x10003, 0x20003, 0x501, 0x101, 0x101, 0x101, 0x101, 0x101, 0x101, 0x60003, 0x70003, 0x101, 0x40003, 0x101, 0x80003, 0x90003, 0xa0003, 0xc0003, 0xc0003, 0xf0003, 0xf0003, 0x101, 0xc0003, 0xc0003, 0xb0003, 0xff0000, 0xc0003, 0xc0003, 0xd0003, 0xd0003, 0xe0003, 0xe0003, 0xe0003},
+		{0x10001, 0x10001, 0x10001, 0x605, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001, 0x10001},
+		{0x20001, 0x20001, 0x20001, 0x20001, 0x605, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x30008, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001},
+		{0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001, 0x20001},
+		{0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x50001, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0xd0001, 0xd0001, 0x104, 0x104, 0x104},
+		{0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0x405, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000},
+		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
+		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
+		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0xc0001, 0x204, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
+		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0xc0001, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
+		{0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x205, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104, 0x104},
+		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x205, 0x204, 0x100001, 0x204, 0x204, 0x204, 0x204, 0x204, 0x120005, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
+		{0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x205, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204, 0x204},
+		{0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0xd0001, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0x304, 0xd0001, 0xd0001, 0xff0000, 0xff0000, 0xff0000},
+		{0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0x404, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001},
+		{0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xff0000, 0xe0001, 0xe0001, 0xe0001, 0xe0001, 0xe0001},
+		{0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x110001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001},
+		{0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x705, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001, 0x100001},
+		{0x120001, 0x0, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001, 0x120001},
 	}
 )
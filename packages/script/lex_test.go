@@ -31,6 +31,8 @@ func TestLexParser(t *testing.T) {
 		{"`my string` \"another String\"" + `"test \"subtest\" test"`, "[6 my string][6 another String][6 test \"subtest\" test]"},
 		{"contract my { func init {}}", "[264 1][4 my][31489 123][520 2][4 init][31489 123][32001 125][32001 125]"},
 		{`callfunc( 1, name + 10)`, `[4 callfunc][10241 40][3 1][11265 44][4 name][2 43][3 10][10497 41]`},
+		{`a % b & c | d ^ e << 2 >> 1`, `[4 a][2 37][4 b][2 38][4 c][2 124][4 d][2 94][4 e][2 15420][3 2][2 15934][3 1]`},
+		{"x += 1; y <<= 2; z &= a && b", `[4 x][2 11069][3 1][5 <nil>][4 y][2 3947581][3 2][5 <nil>][4 z][2 9789][4 a][2 9766][4 b]`},
 		if out, err := lexParser(source); err != nil {
 			if err.Error() != item.Output {
 				fmt.Println(string(source))
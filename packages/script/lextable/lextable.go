@@ -21,7 +21,7 @@ type States map[string]Action
 
 const (
 	// AlphaSize is the length of alphabet
-	AlphaSize = 34
+	AlphaSize = 36
 )
 
 /* Здесь мы определяем алфавит, с которым будет работать наш язык и описываем конечный автомат, который
@@ -38,7 +38,7 @@ var (
 	alphabet = []byte{0x01, 0x0a, ' ', '`', '"', ';', '(', ')', '[', ']', '{', '}', '&',
 		//           default  n    s    q    Q
 		'|', '#', '.', ',', '<', '>', '=', '!', '*', '$', '@', ':',
-		'+', '-', '/', '\\', '0', '1', 'a', '_', 128}
+		'+', '-', '/', '\\', '%', '^', '0', '1', 'a', '_', 128}
 	//													r
 
 	// В states мы обозначили за d - все символы, которые не указаны в состоянии
@@ -66,8 +66,9 @@ var (
 			"|": ["or", "", "push next"],
 			"=": ["eq", "", "push next"],
 			"/": ["solidus", "", "push next"],
-			"<>!": ["oneq", "", "push next"],
-			"*+-": ["main", "oper", "next"],
+			"<": ["less", "", "push next"],
+			">": ["great", "", "push next"],
+			"!*+-%^": ["oneq", "", "push next"],
 			"01": ["number", "", "push next"],
 			"a_r": ["ident", "", "push next"],
 			"@$": ["mustident", "", "push next"],
@@ -96,12 +97,22 @@ var (
 		"d": ["error", "", ""]
 	},
 	"and": {
-			"&": ["main", "oper", "pop next"],
-			"d": ["error", "", ""]
+			"&=": ["main", "oper", "pop next"],
+			"d": ["main", "oper", "pop"]
 		},
 	"or": {
-			"|": ["main", "oper", "pop next"],
-			"d": ["error", "", ""]
+			"|=": ["main", "oper", "pop next"],
+			"d": ["main", "oper", "pop"]
+		},
+	"less": {
+			"<": ["oneq", "", "next"],
+			"=": ["main", "oper", "pop next"],
+			"d": ["main", "oper", "pop"]
+		},
+	"great": {
+			">": ["oneq", "", "next"],
+			"=": ["main", "oper", "pop next"],
+			"d": ["main", "oper", "pop"]
 		},
 	"eq": {
 			"=": ["main", "oper", "pop next"],
@@ -110,6 +121,7 @@ var (
 	"solidus": {
 			"/": ["comline", "", "pop next"],
 			"*": ["comment", "", "next"],
+			"=": ["main", "oper", "pop next"],
 			"d": ["main", "oper", "pop"]
 		},
 	"oneq": {
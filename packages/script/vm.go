@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -105,6 +106,10 @@ type RunTime struct {
 	mem       int64
 	memVars   map[interface{}]int64
 	errInfo   ErrInfo
+	// catchErr is the original error before it was decorated with the contract name and line
+	catchErr error
+	// readOnly is true if the view function is executed
+	readOnly bool
 }
 
 func isSysVar(name string) bool {
@@ -189,6 +194,10 @@ func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 		_, err = rt.RunCode(obj.Value.(*Block))
 	} else {
 		finfo := obj.Value.(ExtFuncInfo)
+		if rt.readOnly && finfo.CanWrite {
+			rt.vm.logger.WithFields(log.Fields{"type": consts.VMError, "func_name": finfo.Name}).Error(errViewWrite.Error())
+			return errViewWrite
+		}
 		foo := reflect.ValueOf(finfo.Func)
 		var result []reflect.Value
 		pars := make([]reflect.Value, in)
@@ -255,6 +264,7 @@ func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 					}
 
 					rt.cost -= cost
+					rt.traceCost(finfo.Name, cost)
 					continue
 				}
 			}
@@ -271,6 +281,13 @@ func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 	return
 }
 
+// traceCost passes the cost of the extended function to the tracer if it has been defined
+func (rt *RunTime) traceCost(name string, cost int64) {
+	if tracer, ok := (*rt.extend)["sc"].(CostTracer); ok {
+		tracer.TraceCost(name, cost)
+	}
+}
+
 func (rt *RunTime) extendFunc(name string) error {
 	var (
 		ok bool
@@ -411,6 +428,30 @@ func ValueToFloat(v interface{}) (ret float64) {
 	return
 }
 
+// intOperands converts the operands of the integer operators. The string operand is allowed
+// only with int operand as it is done for the arithmetic operators
+func intOperands(left, right interface{}) (l, r int64, err error) {
+	switch left.(type) {
+	case int64:
+		switch right.(type) {
+		case int64, string:
+		default:
+			return 0, 0, errUnsupportedType
+		}
+	case string:
+		if _, ok := right.(int64); !ok {
+			return 0, 0, errUnsupportedType
+		}
+	default:
+		return 0, 0, errUnsupportedType
+	}
+	if l, err = converter.ValueToInt(left); err != nil {
+		return
+	}
+	r, err = converter.ValueToInt(right)
+	return
+}
+
 // ValueToDecimal converts interface (string, float64, Decimal or int64) to Decimal
 func ValueToDecimal(v interface{}) (ret decimal.Decimal, err error) {
 	switch val := v.(type) {
@@ -655,6 +696,42 @@ main:
 					break
 				}
 			}
+		case cmdFor:
+			val := rt.stack[len(rt.stack)-1]
+			rt.stack = rt.stack[:len(rt.stack)-1]
+			var iter *forIterator
+			if iter, err = newForIterator(val); err != nil {
+				rt.vm.logger.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("type cannot be iterated")
+				break main
+			}
+			if err = rt.setForIterator(cmd.Value.(*ForInfo), iter); err != nil {
+				break main
+			}
+		case cmdForNext:
+			var iter *forIterator
+			if iter, err = rt.getForIterator(cmd.Value.(*ForInfo)); err != nil {
+				break main
+			}
+			rt.cost -= CostIteration
+			iter.pos++
+			rt.stack = append(rt.stack, iter.pos < len(iter.keys))
+		case cmdForItem:
+			var iter *forIterator
+			if iter, err = rt.getForIterator(cmd.Value.(*ForInfo)); err != nil {
+				break main
+			}
+			switch {
+			case len(cmd.Value.(*ForInfo).Vars) == 2:
+				rt.stack = append(rt.stack, iter.keys[iter.pos], iter.items[iter.pos])
+			case iter.isMap:
+				rt.stack = append(rt.stack, iter.keys[iter.pos])
+			default:
+				rt.stack = append(rt.stack, iter.items[iter.pos])
+			}
+		case cmdTry:
+			if status, err = rt.runTry(cmd.Value.(*TryInfo)); err != nil {
+				break main
+			}
 		case cmdLabel:
 			labels = append(labels, ci)
 		case cmdContinue:
@@ -738,8 +815,10 @@ main:
 						break main
 					} else if cost == -1 {
 						rt.cost -= CostCall
+						rt.traceCost(finfo.Name, CostCall)
 					} else {
 						rt.cost -= cost
+						rt.traceCost(finfo.Name, cost)
 					}
 				}
 			} else {
@@ -816,6 +895,8 @@ main:
 				rt.vm.logger.WithFields(log.Fields{"type": consts.VMError, "vm_type": itype}).Error("type does not support indexing")
 				err = fmt.Errorf(`Type %s doesn't support indexing`, itype)
 			}
+		case cmdCopyIndex:
+			rt.stack = append(rt.stack, rt.stack[size-2], rt.stack[size-1])
 		case cmdSetIndex:
 			itype := reflect.TypeOf(rt.stack[size-3]).String()
 			indexInfo := cmd.Value.(*IndexInfo)
@@ -1097,6 +1178,52 @@ main:
 					break main
 				}
 			}
+		case cmdMod:
+			if left, ok := top[1].(decimal.Decimal); ok {
+				if tmpDec, ok = top[0].(decimal.Decimal); !ok {
+					err = errUnsupportedType
+					break main
+				}
+				if tmpDec.IsZero() {
+					err = errDivZero
+					break main
+				}
+				rt.cost -= CostDecimalOper
+				bin = left.Mod(tmpDec)
+			} else {
+				var left int64
+				if left, tmpInt, err = intOperands(top[1], top[0]); err != nil {
+					break main
+				}
+				if tmpInt == 0 {
+					err = errDivZero
+					break main
+				}
+				bin = left % tmpInt
+			}
+		case cmdBitAnd, cmdBitOr, cmdBitXor, cmdShiftL, cmdShiftR:
+			var left int64
+			if left, tmpInt, err = intOperands(top[1], top[0]); err != nil {
+				break main
+			}
+			switch cmd.Cmd {
+			case cmdBitAnd:
+				bin = left & tmpInt
+			case cmdBitOr:
+				bin = left | tmpInt
+			case cmdBitXor:
+				bin = left ^ tmpInt
+			default:
+				if tmpInt < 0 {
+					err = errNegativeShift
+					break main
+				}
+				if cmd.Cmd == cmdShiftL {
+					bin = left << uint64(tmpInt)
+				} else {
+					bin = left >> uint64(tmpInt)
+				}
+			}
 		case cmdAnd:
 			bin = valueToBool(top[1]) && valueToBool(top[0])
 		case cmdOr:
@@ -1279,6 +1406,9 @@ main:
 		}
 	}
 	rt.stack = rt.stack[:start]
+	if err != nil && rt.catchErr == nil {
+		rt.catchErr = err
+	}
 	if err != nil && !strings.HasPrefix(err.Error(), `{`) {
 		stack := (*rt.extend)["stack"].([]interface{})
 		curContract := stack[len(stack)-1].(string)
@@ -1304,6 +1434,161 @@ main:
 	return
 }
 
+// forIterator contains the items of the array or the map iterated by for ... in
+type forIterator struct {
+	keys  []interface{}
+	items []interface{}
+	isMap bool
+	pos   int
+}
+
+// newForIterator returns the iterator of the array or the map.
+// The keys of the map are iterated in the sorted order
+func newForIterator(val interface{}) (*forIterator, error) {
+	iter := &forIterator{pos: -1}
+	switch v := val.(type) {
+	case nil:
+	case *types.Map:
+		iter.isMap = true
+		sorted := v.Keys()
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			item, _ := v.Get(key)
+			iter.keys = append(iter.keys, key)
+			iter.items = append(iter.items, item)
+		}
+	default:
+		rv := reflect.ValueOf(val)
+		switch {
+		case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				iter.keys = append(iter.keys, int64(i))
+				iter.items = append(iter.items, rv.Index(i).Interface())
+			}
+		case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+			iter.isMap = true
+			sorted := make([]string, 0, rv.Len())
+			for _, key := range rv.MapKeys() {
+				sorted = append(sorted, key.String())
+			}
+			sort.Strings(sorted)
+			for _, key := range sorted {
+				iter.keys = append(iter.keys, key)
+				iter.items = append(iter.items, rv.MapIndex(reflect.ValueOf(key)).Interface())
+			}
+		default:
+			return nil, fmt.Errorf(eForType, rv.Type().String())
+		}
+	}
+	return iter, nil
+}
+
+// forIteratorOffset returns the offset of the hidden variable with the iterator of for ... in
+func (rt *RunTime) forIteratorOffset(info *ForInfo) (int, error) {
+	for i := len(rt.blocks) - 1; i >= 0; i-- {
+		if info.Iter.Owner == rt.blocks[i].Block {
+			return rt.blocks[i].Offset + info.Iter.Obj.Value.(int), nil
+		}
+	}
+	rt.vm.logger.WithFields(log.Fields{"type": consts.VMError, "var": info.Iter.Obj.Value}).Error("wrong var")
+	return 0, fmt.Errorf(`wrong var %v`, info.Iter.Obj.Value)
+}
+
+func (rt *RunTime) setForIterator(info *ForInfo, iter *forIterator) error {
+	off, err := rt.forIteratorOffset(info)
+	if err != nil {
+		return err
+	}
+	rt.setVar(off, iter)
+	return nil
+}
+
+func (rt *RunTime) getForIterator(info *ForInfo) (*forIterator, error) {
+	off, err := rt.forIteratorOffset(info)
+	if err != nil {
+		return nil, err
+	}
+	return rt.vars[off].(*forIterator), nil
+}
+
+// isCatchable returns false for the errors which must stop the transaction in any case
+func (rt *RunTime) isCatchable(err error) bool {
+	return !rt.timeLimit && rt.cost > 0 && rt.mem <= memoryLimit &&
+		err != ErrVMTimeLimit && err != ErrMemoryLimit
+}
+
+// errorToMap returns the description of the error for catch block
+func errorToMap(err error) *types.Map {
+	var desc struct {
+		Type  string `json:"type"`
+		Code  string `json:"id"`
+		Error string `json:"error"`
+	}
+	text := err.Error()
+	if strings.HasPrefix(text, `{`) {
+		json.Unmarshal([]byte(text), &desc)
+	} else if out, errMarshal := json.Marshal(err); errMarshal == nil {
+		json.Unmarshal(out, &desc)
+	}
+	if len(desc.Type) == 0 {
+		desc.Type = `panic`
+	}
+	if len(desc.Error) == 0 {
+		desc.Error = text
+	}
+	return types.LoadMap(map[string]interface{}{
+		`type`: desc.Type,
+		`code`: desc.Code,
+		`text`: desc.Error,
+	})
+}
+
+// runTry executes try block. If it fails then the changes of the database are rolled back
+// to the savepoint of the block and catch block gets the description of the error
+func (rt *RunTime) runTry(info *TryInfo) (status int, err error) {
+	var (
+		point int
+		sp    Savepointer
+	)
+	if sp, _ = (*rt.extend)["sc"].(Savepointer); sp != nil {
+		if point, err = sp.Savepoint(); err != nil {
+			return
+		}
+	}
+	size := len(rt.stack)
+	rt.catchErr = nil
+	status, err = rt.RunCode(info.Try)
+	if err == nil {
+		if sp != nil {
+			err = sp.ReleaseSavepoint(point)
+		}
+		return
+	}
+	if !rt.isCatchable(err) {
+		return
+	}
+	if rt.catchErr != nil {
+		err = rt.catchErr
+	}
+	rt.catchErr = nil
+	if sp != nil {
+		if errRollback := sp.RollbackSavepoint(point); errRollback != nil {
+			return statusNormal, errRollback
+		}
+	}
+	rt.stack = rt.stack[:size]
+	if info.Catch == nil {
+		return statusNormal, nil
+	}
+	rt.stack = append(rt.stack, errorToMap(err))
+	status, err = rt.RunCode(info.Catch)
+	if err != nil || status == statusReturn {
+		return
+	}
+	rt.stack = rt.stack[:size]
+	return
+}
+
 // Run executes Block with the specified parameters and extended variables and functions
 func (rt *RunTime) Run(block *Block, params []interface{}, extend *map[string]interface{}) (ret []interface{}, err error) {
 	defer func() {
@@ -5,6 +5,7 @@
 package script
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,3 +24,57 @@ func TestCalcMem(t *testing.T) {
 		assert.Equal(t, v.mem, calcMem(v.v))
 	}
 }
+
+func TestVMClone(t *testing.T) {
+	vm := NewVM()
+	owner := &OwnerInfo{StateID: 1, Active: true, TableID: 1}
+	assert.NoError(t, vm.Compile([]rune(`func value() string {
+		return "original"
+	}`), owner))
+	children := len(vm.Children)
+
+	clone := vm.Clone()
+	assert.NoError(t, clone.Compile([]rune(`func value() string {
+		return "changed"
+	}
+	contract Added {
+		action {}
+	}`), owner))
+
+	_, ok := vm.Objects[`@1Added`]
+	assert.False(t, ok)
+	assert.Equal(t, children, len(vm.Children))
+	out, err := vm.Call(`value`, nil, &map[string]interface{}{`rt_state`: uint32(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, `original`, out[0])
+	out, err = clone.Call(`value`, nil, &map[string]interface{}{`rt_state`: uint32(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, `changed`, out[0])
+}
+
+func TestVMCloneFlush(t *testing.T) {
+	vm := NewVM()
+	owner := &OwnerInfo{StateID: 1, Active: true, TableID: 1}
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 50; i++ {
+			if err := vm.Compile([]rune(fmt.Sprintf(`func value%d() int {
+				return %d
+			}`, i, i)), owner); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	// the flushing goroutine waits for the read lock to be released,
+	// each function adds the object and the child at once
+	base := NewVM()
+	for i := 0; i < 50; i++ {
+		vm.RLock()
+		clone := vm.Clone()
+		vm.RUnlock()
+		assert.Equal(t, len(clone.Children)-len(base.Children), len(clone.Objects)-len(base.Objects))
+	}
+	assert.NoError(t, <-done)
+}
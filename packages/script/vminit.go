@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 
@@ -53,6 +54,10 @@ const (
 	CostContract = 100
 	// CostExtend is the cost of the extend function calling
 	CostExtend = 10
+	// CostIteration is the cost of the each iteration of for ... in
+	CostIteration = 1
+	// CostDecimalOper is the additional cost of the modulo operation with money values
+	CostDecimalOper = 5
 
 	// VMTypeSmart is smart vm type
 	VMTypeSmart VMType = 1
@@ -137,6 +142,7 @@ type FuncInfo struct {
 	Variadic bool
 	ID       uint32
 	CanWrite bool // If the function can update DB
+	View     bool // If the function is read-only
 }
 
 // VarInfo contains the variable information
@@ -145,6 +151,18 @@ type VarInfo struct {
 	Owner *Block
 }
 
+// ForInfo contains the loop variables and the hidden variable of the iterator of for ... in
+type ForInfo struct {
+	Vars []string
+	Iter *VarInfo
+}
+
+// TryInfo contains the blocks of try ... catch. Catch is nil if there is not catch block
+type TryInfo struct {
+	Try   *Block
+	Catch *Block
+}
+
 // IndexInfo contains the information for SetIndex
 type IndexInfo struct {
 	VarOffset int
@@ -190,6 +208,9 @@ type VM struct {
 	Extern        bool  // extern mode of compilation
 	ShiftContract int64 // id of the first contract
 	logger        *log.Entry
+	// RWMutex guards Objects and Children. They are changed under the write lock, the readers
+	// of other goroutines must hold the read lock
+	sync.RWMutex
 }
 
 // ExtendData is used for the definition of the extended functions and variables
@@ -205,6 +226,18 @@ type Stacker interface {
 	PopStack(fn string)
 }
 
+// CostTracer represents interface for getting the costs of the called extended functions
+type CostTracer interface {
+	TraceCost(name string, cost int64)
+}
+
+// Savepointer represents interface for rolling back the changes of the failed try block
+type Savepointer interface {
+	Savepoint() (int, error)
+	RollbackSavepoint(point int) error
+	ReleaseSavepoint(point int) error
+}
+
 // ExecContract runs the name contract where txs contains the list of parameters and
 // params are the values of parameters
 func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interface{}, error) {
@@ -240,6 +273,23 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 		prevExtend[key] = item
 		delete(*rt.extend, key)
 	}
+	prevthis := (*rt.extend)[`this_contract`]
+	prevparent := (*rt.extend)[`parent`]
+	// the variables of the caller are restored even if the contract has failed
+	// because the error can be caught in try block
+	defer func() {
+		(*rt.extend)[`parent`] = prevparent
+		(*rt.extend)[`this_contract`] = prevthis
+		for key := range *rt.extend {
+			if isSysVar(key) {
+				continue
+			}
+			delete(*rt.extend, key)
+		}
+		for key, item := range prevExtend {
+			(*rt.extend)[key] = item
+		}
+	}()
 
 	var isSignature bool
 	if cblock.Info.(*ContractInfo).Tx != nil {
@@ -259,11 +309,9 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 	for i, ipar := range pars {
 		(*rt.extend)[ipar] = params[i]
 	}
-	prevthis := (*rt.extend)[`this_contract`]
 	_, nameContract := converter.ParseName(name)
 	(*rt.extend)[`this_contract`] = nameContract
 
-	prevparent := (*rt.extend)[`parent`]
 	parent := ``
 	for i := len(rt.blocks) - 1; i >= 0; i-- {
 		if rt.blocks[i].Block.Type == ObjFunc && rt.blocks[i].Block.Parent != nil &&
@@ -300,6 +348,7 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 		if err := stack.AppendStack(name); err != nil {
 			return nil, err
 		}
+		defer stack.PopStack(name)
 	}
 	if (*rt.extend)[`sc`] != nil && isSignature {
 		obj := rt.vm.Objects[`check_signature`]
@@ -312,37 +361,23 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 	for _, method := range []string{`conditions`, `action`} {
 		if block, ok := (*cblock).Objects[method]; ok && block.Type == ObjFunc {
 			rtemp := rt.vm.RunInit(rt.cost)
+			rtemp.readOnly = rt.readOnly
 			(*rt.extend)[`parent`] = parent
 			_, err = rtemp.Run(block.Value.(*Block), nil, rt.extend)
 			rt.cost = rtemp.cost
 			if err != nil {
+				if rt.catchErr == nil {
+					rt.catchErr = rtemp.catchErr
+				}
 				logger.WithFields(log.Fields{"error": err, "method_name": method, "type": consts.ContractError}).Error("executing contract method")
 				break
 			}
 		}
 	}
-	if stack != nil {
-		stack.PopStack(name)
-	}
 	if err != nil {
 		return nil, err
 	}
-	(*rt.extend)[`parent`] = prevparent
-	(*rt.extend)[`this_contract`] = prevthis
-
-	result := (*rt.extend)[`result`]
-	for key := range *rt.extend {
-		if isSysVar(key) {
-			continue
-		}
-		delete(*rt.extend, key)
-	}
-
-	for key, item := range prevExtend {
-		(*rt.extend)[key] = item
-	}
-
-	return result, nil
+	return (*rt.extend)[`result`], nil
 }
 
 // NewVM creates a new virtual machine
@@ -367,6 +402,27 @@ func NewVM() *VM {
 	return &vm
 }
 
+// Clone returns a copy of the virtual machine which can be flushed with new contracts
+// without affecting the original one. The caller must hold the read lock of vm
+func (vm *VM) Clone() *VM {
+	clone := VM{
+		Block:         vm.Block,
+		ExtCost:       vm.ExtCost,
+		FuncCallsDB:   vm.FuncCallsDB,
+		Extern:        vm.Extern,
+		ShiftContract: vm.ShiftContract,
+		logger:        vm.logger,
+	}
+	clone.Objects = make(map[string]*ObjInfo, len(vm.Objects))
+	for key, item := range vm.Objects {
+		obj := *item
+		clone.Objects[key] = &obj
+	}
+	clone.Children = make(Blocks, len(vm.Children), cap(vm.Children))
+	copy(clone.Children, vm.Children)
+	return &clone
+}
+
 // Extend sets the extended variables and functions
 func (vm *VM) Extend(ext *ExtendData) {
 	for key, item := range ext.Objects {
@@ -480,6 +536,22 @@ func (vm *VM) Call(name string, params []interface{}, extend *map[string]interfa
 	return ret, err
 }
 
+// RunView executes the view function. The extended functions which can modify the database
+// are refused in it and in all contracts called from it
+func (vm *VM) RunView(block *Block, params []interface{}, extend *map[string]interface{}) (ret []interface{}, err error) {
+	var cost int64
+	if v, ok := (*extend)[`txcost`]; ok {
+		cost = v.(int64)
+	} else {
+		cost = syspar.GetMaxCost()
+	}
+	rt := vm.RunInit(cost)
+	rt.readOnly = true
+	ret, err = rt.Run(block, params, extend)
+	(*extend)[`txcost`] = rt.Cost()
+	return
+}
+
 // ExContract executes the name contract in the state with specified parameters
 func ExContract(rt *RunTime, state uint32, name string, params *types.Map) (interface{}, error) {
 
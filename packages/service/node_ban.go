@@ -160,6 +160,15 @@ func (nbs *NodesBanService) newBadBlock(producer syspar.HonorNode, blockId, bloc
 	return tx.CreateTransaction(txData, txHash, conf.Config.KeyID, sc.Time)
 }
 
+// IsHostBanned reports whether the host belongs to the banned honor node, other hosts aren't banned
+func (nbs *NodesBanService) IsHostBanned(host string) bool {
+	n, err := syspar.GetNodeByHost(host)
+	if err != nil {
+		return false
+	}
+	return nbs.IsBanned(n)
+}
+
 func (nbs *NodesBanService) FilterHosts(hosts []string) ([]string, []string, error) {
 	var goodHosts, banHosts []string
 	for _, h := range hosts {
@@ -0,0 +1,253 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package service
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxPeerFailures is the count of failures in a row after which the peer is removed
+	maxPeerFailures = 5
+	// latencyWeight is the weight of the last latency in the average latency of the peer
+	latencyWeight = 0.3
+)
+
+// PeersService is the table of peers which is filled by the exchange of addresses.
+// The received addresses are unverified until the peer proves its node key in the handshake,
+// only the verified peers are used. The unverified peers take up to half of the table and
+// the failing peers are replaced with new addresses when the table is full.
+// The peers are scored by the availability and the latency, the banned honor nodes are skipped
+type PeersService struct {
+	mutex sync.Mutex
+	peers map[string]*model.Peer
+	max   int
+}
+
+var ps *PeersService
+
+// GetPeersService is returning the peers service
+func GetPeersService() *PeersService {
+	return ps
+}
+
+// InitPeersService loads the table of peers from leveldb
+func InitPeersService() error {
+	list, err := model.GetPeers()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting peers")
+		return err
+	}
+	ps = &PeersService{
+		peers: make(map[string]*model.Peer, len(list)),
+		max:   conf.Config.Peers.Max,
+	}
+	for _, p := range list {
+		ps.peers[p.Address] = p
+	}
+	return nil
+}
+
+func isVerified(p *model.Peer) bool {
+	return len(p.NodeKey) > 0
+}
+
+// evictCandidate returns the peer with the most failures in a row or nil if all peers answer.
+// Only the unverified peers are returned if onlyUnverified is true
+func evictCandidate(peers map[string]*model.Peer, onlyUnverified bool) *model.Peer {
+	var worst *model.Peer
+	for _, p := range peers {
+		if onlyUnverified && isVerified(p) {
+			continue
+		}
+		if p.Failures > 0 && (worst == nil || p.Failures > worst.Failures) {
+			worst = p
+		}
+	}
+	return worst
+}
+
+// Add adds the unknown addresses which have been received from the source as unverified peers
+func (ps *PeersService) Add(source string, addresses []string) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	var unverified int
+	for _, p := range ps.peers {
+		if !isVerified(p) {
+			unverified++
+		}
+	}
+	for _, addr := range addresses {
+		host, err := tcpclient.NormalizeHostAddress(addr, consts.DEFAULT_TCP_PORT)
+		if err != nil || host == conf.Config.Peers.Address {
+			continue
+		}
+		if _, ok := ps.peers[host]; ok {
+			continue
+		}
+		if capped := unverified >= ps.max/2; capped || len(ps.peers) >= ps.max {
+			worst := evictCandidate(ps.peers, capped)
+			if worst == nil {
+				return
+			}
+			if !isVerified(worst) {
+				unverified--
+			}
+			ps.remove(worst.Address)
+		}
+		p := &model.Peer{Address: host, Source: source}
+		ps.peers[host] = p
+		unverified++
+		ps.save(p)
+	}
+}
+
+// Success registers the answer of the peer with the node key which it has proved in the handshake.
+// The peer which has changed the node key is registered as failed
+func (ps *PeersService) Success(address string, latency time.Duration, nodeKey []byte) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	p, ok := ps.peers[address]
+	if !ok {
+		return
+	}
+	if bytes.Equal(nodeKey, syspar.GetNodePubKey()) {
+		// the address of the node itself
+		ps.remove(address)
+		return
+	}
+	if isVerified(p) && !bytes.Equal(p.NodeKey, nodeKey) {
+		ps.failure(p)
+		return
+	}
+	p.NodeKey = nodeKey
+	ms := latency.Milliseconds()
+	if p.Success == 0 {
+		p.Latency = ms
+	} else {
+		p.Latency = int64(latencyWeight*float64(ms) + (1-latencyWeight)*float64(p.Latency))
+	}
+	p.Success++
+	p.Failures = 0
+	p.LastSeen = time.Now().Unix()
+	ps.save(p)
+}
+
+// Failure registers the unavailability of the peer, the peer is removed after maxPeerFailures in a row
+func (ps *PeersService) Failure(address string) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if p, ok := ps.peers[address]; ok {
+		ps.failure(p)
+	}
+}
+
+func (ps *PeersService) failure(p *model.Peer) {
+	if p.Failures++; p.Failures < maxPeerFailures {
+		ps.save(p)
+		return
+	}
+	ps.remove(p.Address)
+}
+
+func (ps *PeersService) remove(address string) {
+	delete(ps.peers, address)
+	if err := model.DeletePeer(address); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "address": address}).Error("deleting peer")
+	}
+}
+
+func (ps *PeersService) save(p *model.Peer) {
+	if err := p.Save(); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "address": p.Address}).Error("saving peer")
+	}
+}
+
+func (ps *PeersService) list() []*model.Peer {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	list := make([]*model.Peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if nbs != nil && nbs.IsHostBanned(p.Address) {
+			continue
+		}
+		cp := *p
+		list = append(list, &cp)
+	}
+	return list
+}
+
+// Hosts returns up to count available verified peers. The peers are randomly taken from the best ones
+// so the nodes don't use the same peers
+func (ps *PeersService) Hosts(count int) []string {
+	return selectPeers(ps.list(), count)
+}
+
+// Probe returns up to count random peers including unverified ones which haven't answered yet
+func (ps *PeersService) Probe(count int) []string {
+	list := ps.list()
+	rand.Shuffle(len(list), func(i, j int) { list[i], list[j] = list[j], list[i] })
+	hosts := make([]string, 0, count)
+	for i := 0; i < len(list) && i < count; i++ {
+		hosts = append(hosts, list[i].Address)
+	}
+	return hosts
+}
+
+// Len returns the count of peers in the table
+func (ps *PeersService) Len() int {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	return len(ps.peers)
+}
+
+// peerScore is higher for the peers with low latency and without recent failures
+func peerScore(p *model.Peer) float64 {
+	if p.Success == 0 {
+		return 0
+	}
+	return 1000 / float64(p.Latency+10) / float64(p.Failures+1)
+}
+
+// selectPeers returns up to count random peers of the 2*count best verified peers which have answered
+func selectPeers(list []*model.Peer, count int) []string {
+	available := make([]*model.Peer, 0, len(list))
+	for _, p := range list {
+		if p.Success > 0 && isVerified(p) {
+			available = append(available, p)
+		}
+	}
+	sort.SliceStable(available, func(i, j int) bool {
+		return peerScore(available[i]) > peerScore(available[j])
+	})
+	if len(available) > 2*count {
+		available = available[:2*count]
+	}
+	rand.Shuffle(len(available), func(i, j int) { available[i], available[j] = available[j], available[i] })
+	if len(available) > count {
+		available = available[:count]
+	}
+	hosts := make([]string, 0, len(available))
+	for _, p := range available {
+		hosts = append(hosts, p.Address)
+	}
+	return hosts
+}
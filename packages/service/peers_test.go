@@ -0,0 +1,112 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+func newTestPeersService(t *testing.T, max int) *PeersService {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	require.NoError(t, err)
+	model.DBlevel = db
+	t.Cleanup(func() { db.Close() })
+	return &PeersService{peers: make(map[string]*model.Peer), max: max}
+}
+
+func addresses(from, count int) []string {
+	list := make([]string, 0, count)
+	for i := from; i < from+count; i++ {
+		list = append(list, fmt.Sprintf("10.0.0.%d:7078", i))
+	}
+	return list
+}
+
+func TestPeersServiceVerify(t *testing.T) {
+	ps := newTestPeersService(t, 10)
+
+	// the unverified peers take up to half of the table and aren't used
+	ps.Add("source", addresses(1, 10))
+	assert.Equal(t, 5, ps.Len())
+	assert.Empty(t, ps.Hosts(5))
+
+	ps.Success("10.0.0.1:7078", 10*time.Millisecond, []byte("key1"))
+	assert.Equal(t, []string{"10.0.0.1:7078"}, ps.Hosts(5))
+
+	// the peer which has changed the node key is failed
+	ps.Success("10.0.0.1:7078", 10*time.Millisecond, []byte("key2"))
+	assert.Equal(t, []byte("key1"), ps.peers["10.0.0.1:7078"].NodeKey)
+	assert.Equal(t, int64(1), ps.peers["10.0.0.1:7078"].Failures)
+
+	list, err := model.GetPeers()
+	require.NoError(t, err)
+	assert.Len(t, list, 5)
+}
+
+func TestPeersServiceEvict(t *testing.T) {
+	ps := newTestPeersService(t, 4)
+	verify := func(addr string) {
+		ps.Success(addr, time.Millisecond, []byte(addr))
+	}
+
+	ps.Add("source", addresses(1, 2))
+	verify("10.0.0.1:7078")
+	verify("10.0.0.2:7078")
+	ps.Add("source", addresses(3, 2))
+	require.Equal(t, 4, ps.Len())
+
+	// the table is full and all peers answer
+	ps.Add("source", addresses(5, 1))
+	assert.Equal(t, 4, ps.Len())
+	assert.NotContains(t, ps.peers, "10.0.0.5:7078")
+
+	// the failing unverified peer is replaced with the new address
+	ps.Failure("10.0.0.3:7078")
+	ps.Add("source", addresses(5, 1))
+	assert.Equal(t, 4, ps.Len())
+	assert.Contains(t, ps.peers, "10.0.0.5:7078")
+	assert.NotContains(t, ps.peers, "10.0.0.3:7078")
+
+	// the failing verified peer is replaced too
+	verify("10.0.0.4:7078")
+	verify("10.0.0.5:7078")
+	ps.Failure("10.0.0.2:7078")
+	ps.Add("source", addresses(6, 1))
+	assert.Equal(t, 4, ps.Len())
+	assert.Contains(t, ps.peers, "10.0.0.6:7078")
+	assert.NotContains(t, ps.peers, "10.0.0.2:7078")
+
+	// the peer is removed after maxPeerFailures in a row
+	for i := 0; i < maxPeerFailures; i++ {
+		ps.Failure("10.0.0.1:7078")
+	}
+	assert.Equal(t, 3, ps.Len())
+}
+
+func TestSelectPeers(t *testing.T) {
+	list := []*model.Peer{
+		{Address: "fast", Success: 1, Latency: 10, NodeKey: []byte("1")},
+		{Address: "slow", Success: 1, Latency: 1000, NodeKey: []byte("2")},
+		{Address: "failing", Success: 1, Latency: 10, Failures: 3, NodeKey: []byte("3")},
+		{Address: "unverified", Success: 1, Latency: 1},
+		{Address: "new", NodeKey: []byte("4")},
+	}
+	for i := 0; i < 10; i++ {
+		hosts := selectPeers(list, 1)
+		require.Len(t, hosts, 1)
+		// the host is taken from the 2 best verified peers
+		assert.Contains(t, []string{"fast", "failing"}, hosts[0])
+	}
+	assert.ElementsMatch(t, []string{"fast", "slow", "failing"}, selectPeers(list, 5))
+}
@@ -33,6 +33,7 @@ const (
 	eEcoKeyDisable       = `%s disable in ecosystem %d`
 	eEcoFuelRate         = `fuel rate must be greater than 0 or empty in ecosystem %d`
 	eEcoCurrentBalance   = `current balance is not enough in ecosystem %d, at least [%s] difference`
+	eViewParam           = `parameter %d must be %s`
 )
 
 var (
@@ -75,6 +76,8 @@ var (
 	errNotValidUTF        = errors.New(`result is not valid utf-8 string`)
 	errFloat              = errors.New(`incorrect float value`)
 	errFloatResult        = errors.New(`incorrect float result`)
+	errEventName          = errors.New(`incorrect event name`)
+	errViewParams         = errors.New(`wrong count of parameters`)
 
 	errMaxPrice = fmt.Errorf(`price value is more than %d`, MaxPrice)
 )
@@ -0,0 +1,107 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package smart
+
+import (
+	"fmt"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EstimateCost is the total cost of the calls of the extended function
+type EstimateCost struct {
+	Calls int64 `json:"calls"`
+	Cost  int64 `json:"cost"`
+}
+
+// EstimateResult is the result of the dry run of the contract
+type EstimateResult struct {
+	Fuel   int64                    `json:"fuel"`
+	Result string                   `json:"result"`
+	Error  string                   `json:"error,omitempty"`
+	Costs  map[string]*EstimateCost `json:"costs"`
+}
+
+// TraceCost collects the costs of the extended functions while the contract is being estimated
+func (sc *SmartContract) TraceCost(name string, cost int64) {
+	if sc.costs == nil {
+		return
+	}
+	item, ok := sc.costs[name]
+	if !ok {
+		item = &EstimateCost{}
+		sc.costs[name] = item
+	}
+	item.Calls++
+	item.Cost += cost
+}
+
+// EstimateContract executes the contract like CallContract but it doesn't check the signature
+// and doesn't pay for the execution. The changes of the database must be rolled back by the caller.
+// The caller must hold the read lock of sc.VM, the new contracts are flushed into the copy of VM
+func (sc *SmartContract) EstimateContract() (*EstimateResult, error) {
+	logger := sc.GetLogger()
+	ret := &EstimateResult{Costs: make(map[string]*EstimateCost)}
+	sc.costs = ret.Costs
+	sc.sharedVM = true
+	defer func() {
+		sc.costs = nil
+		sc.sharedVM = false
+	}()
+
+	sc.Key = &model.Key{}
+	found, err := sc.Key.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, sc.TxSmart.KeyID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting wallet")
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf(eEcoKeyNotFound, converter.AddressToString(sc.TxSmart.KeyID), sc.TxSmart.EcosystemID)
+	}
+	if len(sc.Key.PublicKey) > 0 {
+		sc.PublicKeys = append(sc.PublicKeys, sc.Key.PublicKey)
+	}
+
+	sc.TxContract.Extend = sc.getExtend()
+	if err = sc.AppendStack(sc.TxContract.Name); err != nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "error": err}).Error("loop in contract")
+		return nil, err
+	}
+	ctrctExtend := *sc.TxContract.Extend
+	before := ctrctExtend[`txcost`].(int64)
+	ctrctExtend[`txcost`] = before - syspar.GetSizeFuel()*sc.TxSize/1024
+
+	_, nameContract := converter.ParseName(sc.TxContract.Name)
+	ctrctExtend[`original_contract`] = nameContract
+	ctrctExtend[`this_contract`] = nameContract
+
+	var cfuncs []*script.Block
+	for _, method := range []string{`conditions`, `action`} {
+		if cfunc := sc.TxContract.GetFunc(method); cfunc != nil {
+			cfuncs = append(cfuncs, cfunc)
+		}
+	}
+	for i := 0; i < len(cfuncs); i++ {
+		sc.TxContract.Called = 1 << i
+		if _, err = VMRun(sc.VM, cfuncs[i], nil, sc.TxContract.Extend); err != nil {
+			break
+		}
+	}
+	ret.Fuel = before - ctrctExtend[`txcost`].(int64)
+	if err != nil {
+		ret.Error = err.Error()
+		return ret, nil
+	}
+	if ctrctExtend[`result`] != nil {
+		ret.Result = fmt.Sprint(ctrctExtend[`result`])
+	}
+	return ret, nil
+}
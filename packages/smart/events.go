@@ -0,0 +1,77 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package smart
+
+import (
+	"fmt"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/types"
+
+	"github.com/shopspring/decimal"
+)
+
+const maxEventName = 255
+
+// eventTopics returns the indexed fields of the event. They are the fields with scalar values
+// and they are stored as strings so the events can be found by them
+func eventTopics(params *types.Map) map[string]string {
+	topics := make(map[string]string)
+	for _, key := range params.Keys() {
+		val, _ := params.Get(key)
+		switch v := val.(type) {
+		case string:
+			topics[key] = v
+		case int64, int, bool:
+			topics[key] = fmt.Sprint(v)
+		case decimal.Decimal:
+			topics[key] = v.String()
+		}
+	}
+	return topics
+}
+
+// EmitEvent stores the event of the current contract. The event is rolled back together
+// with the transaction and is sent to the subscribers when the block has been committed
+func EmitEvent(sc *SmartContract, name string, params *types.Map) error {
+	if sc.OBS {
+		return ErrNotImplementedOnOBS
+	}
+	if len(name) == 0 || len(name) > maxEventName {
+		return logErrorShort(errEventName, consts.ParameterExceeded)
+	}
+	if params == nil {
+		params = types.NewMap()
+	}
+	data, err := marshalJSON(params, "marshalling event data")
+	if err != nil {
+		return err
+	}
+	topics, err := marshalJSON(eventTopics(params), "marshalling event topics")
+	if err != nil {
+		return err
+	}
+	contract := sc.TxContract.Name
+	if len(sc.TxContract.StackCont) > 0 {
+		contract = sc.TxContract.StackCont[len(sc.TxContract.StackCont)-1].(string)
+	}
+	event := &model.ContractEvent{
+		TxHash:      sc.TxHash,
+		EcosystemID: sc.TxSmart.EcosystemID,
+		Contract:    contract,
+		Name:        name,
+		Topics:      string(topics),
+		Data:        string(data),
+	}
+	if sc.BlockData != nil {
+		event.BlockID = sc.BlockData.BlockID
+	}
+	if err = event.Create(sc.DbTransaction); err != nil {
+		return logErrorDB(err, "inserting contract event")
+	}
+	sc.Events = append(sc.Events, event)
+	return nil
+}
@@ -128,12 +128,16 @@ type SmartContract struct {
 	Rand          *rand.Rand
 	FlushRollback []FlushInfo
 	Notifications types.Notifications
+	Events        []*model.ContractEvent
 	GenBlock      bool
 	TimeLimit     int64
 	Key           *model.Key
 	RollBackTx    []*model.RollbackTx
 	multiPays     multiPays
 	taxes         bool
+	savepoints    []savepoint
+	costs         map[string]*EstimateCost
+	sharedVM      bool // VM is read locked by the caller, it is copied before flushing contracts
 }
 
 var (
@@ -295,6 +299,7 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"UnixDateTimeLocation":         UnixDateTimeLocation,
 		"UpdateNotifications":          UpdateNotifications,
 		"UpdateRolesNotifications":     UpdateRolesNotifications,
+		"EmitEvent":                    EmitEvent,
 		"TransactionInfo":              TransactionInfo,
 		"DelTable":                     DelTable,
 		"DelColumn":                    DelColumn,
@@ -382,6 +387,7 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 			"DeleteOBS":        {},
 			"DelColumn":        {},
 			"DelTable":         {},
+			"EmitEvent":        {},
 		},
 	})
 }
@@ -1276,6 +1282,9 @@ func FlushContract(sc *SmartContract, iroot interface{}, id int64) error {
 			return errOneContract
 		}
 	}
+	if sc.sharedVM {
+		sc.VM, sc.sharedVM = sc.VM.Clone(), false
+	}
 	for i, item := range root.Children {
 		if item.Type == script.ObjContract {
 			root.Children[i].Info.(*script.ContractInfo).Owner.TableID = id
@@ -0,0 +1,100 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package smart
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// savepoint is the state of the contract at the beginning of try block
+type savepoint struct {
+	rollbackTx    int
+	flushRollback int
+	events        int
+	multiPays     int
+	taxes         bool
+	txFuel        int64
+	notifications types.Notifications
+}
+
+// notificationsCloner is the queue of notifications which can be saved at the beginning of try block
+type notificationsCloner interface {
+	Clone() types.Notifications
+}
+
+// Savepoint creates the database savepoint for try block and returns its number
+func (sc *SmartContract) Savepoint() (int, error) {
+	point := len(sc.savepoints)
+	if sc.DbTransaction != nil {
+		if err := sc.DbTransaction.Savepoint(consts.SetSavePointMarkTry(point)); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "point": point}).Error("creating try savepoint")
+			return 0, err
+		}
+	}
+	var notifications types.Notifications
+	if queue, ok := sc.Notifications.(notificationsCloner); ok {
+		notifications = queue.Clone()
+	}
+	sc.savepoints = append(sc.savepoints, savepoint{
+		rollbackTx:    len(sc.RollBackTx),
+		flushRollback: len(sc.FlushRollback),
+		events:        len(sc.Events),
+		multiPays:     len(sc.multiPays),
+		taxes:         sc.taxes,
+		txFuel:        sc.TxFuel,
+		notifications: notifications,
+	})
+	return point, nil
+}
+
+// RollbackSavepoint discards the changes which have been made after the savepoint was created
+func (sc *SmartContract) RollbackSavepoint(point int) error {
+	sp := sc.savepoints[point]
+	if sc.DbTransaction != nil {
+		if err := sc.DbTransaction.RollbackSavepoint(consts.SetSavePointMarkTry(point)); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "point": point}).Error("rolling back try savepoint")
+			return err
+		}
+	}
+	sc.RollBackTx = sc.RollBackTx[:sp.rollbackTx]
+	if len(sc.FlushRollback) > sp.flushRollback {
+		sc.VM.Lock()
+		for i := len(sc.FlushRollback) - 1; i >= sp.flushRollback; i-- {
+			finfo := sc.FlushRollback[i]
+			if finfo.Prev == nil {
+				sc.VM.Children = sc.VM.Children[:finfo.ID]
+				delete(sc.VM.Objects, finfo.Name)
+			} else {
+				sc.VM.Children[finfo.ID] = finfo.Prev
+				sc.VM.Objects[finfo.Name] = finfo.Info
+			}
+		}
+		sc.VM.Unlock()
+	}
+	sc.FlushRollback = sc.FlushRollback[:sp.flushRollback]
+	sc.Events = sc.Events[:sp.events]
+	sc.multiPays = sc.multiPays[:sp.multiPays]
+	sc.taxes = sp.taxes
+	sc.TxFuel = sp.txFuel
+	if sp.notifications != nil {
+		sc.Notifications = sp.notifications
+	}
+	return sc.ReleaseSavepoint(point)
+}
+
+// ReleaseSavepoint removes the savepoint of try block
+func (sc *SmartContract) ReleaseSavepoint(point int) error {
+	sc.savepoints = sc.savepoints[:point]
+	if sc.DbTransaction != nil {
+		if err := sc.DbTransaction.ReleaseSavepoint(point, consts.SavePointMarkTry); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "point": point}).Error("releasing try savepoint")
+			return err
+		}
+	}
+	return nil
+}
@@ -141,6 +141,8 @@ func SavepointSmartVMObjects() {
 }
 
 func RollbackSmartVMObjects() {
+	smartVM.Lock()
+	defer smartVM.Unlock()
 	smartVM.Objects = make(map[string]*script.ObjInfo)
 	for k, v := range SmartObjects {
 		smartVM.Objects[k] = v
@@ -10,6 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/IBAX-io/go-ibax/packages/script"
+	"github.com/IBAX-io/go-ibax/packages/types"
+
+	"github.com/shopspring/decimal"
 )
 
 type TestSmart struct {
@@ -71,3 +74,32 @@ func TestCheckAppend(t *testing.T) {
 	_, err := Run(cfunc, nil, &map[string]interface{}{})
 	require.NoError(t, err)
 }
+
+func TestEventTopics(t *testing.T) {
+	params := types.LoadMap(map[string]interface{}{
+		"account": "0000-0000-0000-0000-0001",
+		"amount":  decimal.New(15, 2),
+		"id":      int64(7),
+		"list":    []interface{}{1, 2},
+	})
+	require.Equal(t, map[string]string{
+		"account": "0000-0000-0000-0000-0001",
+		"amount":  "1500",
+		"id":      "7",
+	}, eventTopics(params))
+}
+
+func TestTraceCost(t *testing.T) {
+	sc := &SmartContract{}
+	sc.TraceCost("DBFind", 100)
+	require.Nil(t, sc.costs)
+
+	sc.costs = make(map[string]*EstimateCost)
+	sc.TraceCost("DBFind", 100)
+	sc.TraceCost("DBFind", 50)
+	sc.TraceCost("Sprintf", 1)
+	require.Equal(t, map[string]*EstimateCost{
+		"DBFind":  {Calls: 2, Cost: 150},
+		"Sprintf": {Calls: 1, Cost: 1},
+	}, sc.costs)
+}
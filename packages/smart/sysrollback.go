@@ -82,8 +82,10 @@ func SysRollbackContract(name string, EcosystemID int64) error {
 			log.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("rollback contract")
 			return err
 		}
+		vm.Lock()
 		vm.Children = vm.Children[:id]
 		delete(vm.Objects, c.Name)
+		vm.Unlock()
 	}
 
 	return nil
@@ -0,0 +1,170 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package smart
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/script"
+	"github.com/IBAX-io/go-ibax/packages/types"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetView returns the block of the specified read-only function in the contract
+func (contract *Contract) GetView(name string) *script.Block {
+	if block := contract.GetFunc(name); block != nil && block.Info.(*script.FuncInfo).View {
+		return block
+	}
+	return nil
+}
+
+// jsonValue converts the numbers of the decoded json value to int64 or float64
+// and the objects to *types.Map
+func jsonValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonValue(item)
+		}
+		return types.LoadMap(v)
+	}
+	return val
+}
+
+func viewParam(val interface{}, ptype string) (ret interface{}, err error) {
+	switch ptype {
+	case `int64`:
+		switch v := val.(type) {
+		case json.Number:
+			ret, err = v.Int64()
+		case string:
+			ret, err = strconv.ParseInt(v, 10, 64)
+		}
+	case `float64`:
+		switch v := val.(type) {
+		case json.Number:
+			ret, err = v.Float64()
+		case string:
+			ret, err = strconv.ParseFloat(v, 64)
+		}
+	case `string`:
+		switch v := val.(type) {
+		case string:
+			ret = v
+		case json.Number:
+			ret = v.String()
+		}
+	case `bool`:
+		if v, ok := val.(bool); ok {
+			ret = v
+		}
+	case `decimal.Decimal`:
+		switch v := val.(type) {
+		case json.Number:
+			ret, err = decimal.NewFromString(v.String())
+		case string:
+			ret, err = decimal.NewFromString(v)
+		}
+	case `[]uint8`:
+		if v, ok := val.(string); ok {
+			ret, err = hex.DecodeString(v)
+		}
+	case `[]interface {}`:
+		if v, ok := val.([]interface{}); ok {
+			ret = jsonValue(v)
+		}
+	case `*types.Map`:
+		if v, ok := val.(map[string]interface{}); ok {
+			ret = jsonValue(v)
+		}
+	default:
+		ret = jsonValue(val)
+	}
+	return
+}
+
+// ViewParams converts the values which have been decoded from json with UseNumber
+// to the types of the parameters of the view function
+func ViewParams(block *script.Block, values []interface{}) ([]interface{}, error) {
+	finfo := block.Info.(*script.FuncInfo)
+	count := len(finfo.Params)
+	if finfo.Variadic {
+		count--
+		if len(values) < count {
+			return nil, errViewParams
+		}
+	} else if len(values) != count {
+		return nil, errViewParams
+	}
+	params := make([]interface{}, 0, len(finfo.Params))
+	for i := 0; i < count; i++ {
+		ptype := finfo.Params[i].String()
+		val, err := viewParam(values[i], ptype)
+		if err != nil || val == nil {
+			return nil, fmt.Errorf(eViewParam, i+1, ptype)
+		}
+		params = append(params, val)
+	}
+	if finfo.Variadic {
+		tail := make([]interface{}, 0, len(values)-count)
+		for _, val := range values[count:] {
+			tail = append(tail, jsonValue(val))
+		}
+		params = append(params, tail)
+	}
+	return params, nil
+}
+
+// CallView executes the read-only function of the contract immediately without the transaction
+// and returns its results
+func (sc *SmartContract) CallView(block *script.Block, params []interface{}) ([]interface{}, error) {
+	logger := sc.GetLogger()
+
+	sc.Key = &model.Key{}
+	found, err := sc.Key.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, sc.TxSmart.KeyID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting wallet")
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf(eEcoKeyNotFound, converter.AddressToString(sc.TxSmart.KeyID), sc.TxSmart.EcosystemID)
+	}
+
+	sc.TxContract.Extend = sc.getExtend()
+	if err = sc.AppendStack(sc.TxContract.Name); err != nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "error": err}).Error("loop in contract")
+		return nil, err
+	}
+	defer sc.PopStack(sc.TxContract.Name)
+
+	_, nameContract := converter.ParseName(sc.TxContract.Name)
+	(*sc.TxContract.Extend)[`original_contract`] = nameContract
+	(*sc.TxContract.Extend)[`this_contract`] = nameContract
+
+	ret, err := sc.VM.RunView(block, params, sc.TxContract.Extend)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.VMError, "error": err, "contract_name": sc.TxContract.Name}).Error("running view function")
+		return nil, err
+	}
+	return ret, nil
+}
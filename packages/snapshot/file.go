@@ -0,0 +1,137 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ChunkSize is the size of the part of the snapshot which is sent by one request
+const ChunkSize = consts.SnapshotChunkSize
+
+var ErrChunk = errors.New("Wrong snapshot chunk")
+
+// Path returns the path of the snapshot file of the block
+func Path(blockID int64) string {
+	return filepath.Join(conf.Config.DataDir, "snapshots", fmt.Sprintf("%d.snap", blockID))
+}
+
+// Chunks returns the count of chunks of the snapshot
+func Chunks(size int64) int64 {
+	return (size + ChunkSize - 1) / ChunkSize
+}
+
+// FileHash returns the hash of the snapshot file which is published by the node
+func FileHash(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Create exports the snapshot of the current state to the data directory and saves its hash
+func Create(dbTransaction *model.DbTransaction) (*model.Snapshot, error) {
+	logger := log.WithFields(log.Fields{"type": consts.IOError})
+	if err := os.MkdirAll(filepath.Dir(Path(0)), 0775); err != nil {
+		logger.WithError(err).Error("creating snapshots directory")
+		return nil, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(Path(0)), "export")
+	if err != nil {
+		logger.WithError(err).Error("creating snapshot file")
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	h := sha256.New()
+	header, err := Export(dbTransaction, io.MultiWriter(f, h))
+	if err == nil {
+		err = f.Sync()
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		logger.WithError(err).Error("writing snapshot file")
+		return nil, err
+	}
+	info, err := os.Stat(f.Name())
+	if err != nil {
+		logger.WithError(err).Error("getting size of snapshot file")
+		return nil, err
+	}
+	if err = os.Rename(f.Name(), Path(header.BlockID)); err != nil {
+		logger.WithError(err).Error("renaming snapshot file")
+		return nil, err
+	}
+	s := &model.Snapshot{
+		BlockID: header.BlockID,
+		Hash:    h.Sum(nil),
+		Size:    info.Size(),
+		Time:    time.Now().Unix(),
+	}
+	if err = s.Create(nil); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("saving snapshot")
+		return nil, err
+	}
+	return s, nil
+}
+
+// RemoveBefore deletes the snapshots which are older than the specified block
+func RemoveBefore(blockID int64) error {
+	list, err := model.GetSnapshotsBefore(blockID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting old snapshots")
+		return err
+	}
+	for _, s := range list {
+		if err = os.Remove(Path(s.BlockID)); err != nil && !os.IsNotExist(err) {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "block_id": s.BlockID}).Error("removing snapshot file")
+			return err
+		}
+		if err = model.DeleteSnapshot(s.BlockID); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": s.BlockID}).Error("deleting snapshot")
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadChunk returns the part of the snapshot file
+func ReadChunk(s *model.Snapshot, chunk int64) ([]byte, error) {
+	if chunk < 0 || chunk >= Chunks(s.Size) {
+		return nil, ErrChunk
+	}
+	f, err := os.Open(Path(s.BlockID))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data := make([]byte, ChunkSize)
+	n, err := f.ReadAt(data, chunk*ChunkSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data[:n], nil
+}
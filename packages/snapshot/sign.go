@@ -0,0 +1,79 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+)
+
+var (
+	ErrSign       = errors.New("Snapshot signature is incorrect")
+	ErrSignQuorum = errors.New("Snapshot hash is not confirmed by honor nodes")
+)
+
+// Sign is the hash of the snapshot file of the block which is signed by the key of honor node.
+// The snapshots of the same block are identical on all nodes, so the signatures of the quorum
+// of honor nodes confirm every row of the snapshot
+type Sign struct {
+	BlockID int64
+	Hash    []byte
+	NodeKey []byte
+	Sign    []byte
+}
+
+// ForSign returns the data which is signed by the node key
+func (s *Sign) ForSign() []byte {
+	return []byte(fmt.Sprintf("snapshot,%d,%x", s.BlockID, s.Hash))
+}
+
+// NewSign returns the hash of the snapshot signed by the private key of the node
+func NewSign(blockID int64, hash, nodeKey, privateKey []byte) (*Sign, error) {
+	s := &Sign{BlockID: blockID, Hash: hash, NodeKey: nodeKey}
+	sign, err := crypto.Sign(privateKey, s.ForSign())
+	if err != nil {
+		return nil, err
+	}
+	s.Sign = sign
+	return s, nil
+}
+
+// Verify checks that the hash is signed by its node key
+func (s *Sign) Verify() error {
+	ok, err := utils.CheckSign([][]byte{s.NodeKey}, s.ForSign(), s.Sign, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSign
+	}
+	return nil
+}
+
+// VerifySigns checks that the hash of the snapshot is signed by the quorum of different honor nodes.
+// isHonorNode reports whether the key belongs to honor node
+func VerifySigns(blockID int64, hash []byte, signs []*Sign, nodes int64, isHonorNode func([]byte) bool) error {
+	keys := make(map[string]bool)
+	for _, s := range signs {
+		key := hex.EncodeToString(s.NodeKey)
+		if s.BlockID != blockID || !bytes.Equal(s.Hash, hash) || keys[key] || !isHonorNode(s.NodeKey) {
+			continue
+		}
+		if s.Verify() != nil {
+			continue
+		}
+		keys[key] = true
+	}
+	if len(keys) < utils.FinalityQuorum(nodes) {
+		return ErrSignQuorum
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySigns(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	crypto.InitHash("SHA256")
+
+	hash := crypto.Hash([]byte("snapshot"))
+	var (
+		keys  [][]byte
+		signs []*Sign
+	)
+	for i := 0; i < 4; i++ {
+		priv, pub, err := crypto.GenKeyPair()
+		require.NoError(t, err)
+		keys = append(keys, pub)
+		s, err := NewSign(10, hash, pub, priv)
+		require.NoError(t, err)
+		signs = append(signs, s)
+	}
+	isHonorNode := func(key []byte) bool {
+		for _, k := range keys[:3] {
+			if bytes.Equal(k, key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.NoError(t, VerifySigns(10, hash, signs, 3, isHonorNode))
+	assert.NoError(t, VerifySigns(10, hash, signs[:3], 4, isHonorNode))
+	// the duplicate signatures and the signatures of other nodes are not counted
+	assert.Equal(t, ErrSignQuorum, VerifySigns(10, hash, append(signs[:2:2], signs[0], signs[3]), 4, isHonorNode))
+	assert.Equal(t, ErrSignQuorum, VerifySigns(11, hash, signs, 3, isHonorNode))
+	assert.Equal(t, ErrSignQuorum, VerifySigns(10, crypto.Hash([]byte("other")), signs, 3, isHonorNode))
+
+	// the signature is bound to the block of the snapshot
+	forged := *signs[0]
+	forged.BlockID = 11
+	assert.Error(t, forged.Verify())
+}
@@ -0,0 +1,381 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+// Package snapshot exports and imports the state of the blockchain at the block.
+// The snapshot is the gzipped stream of json values: the header, then every table
+// with its definition followed by its rows. The tables and the rows are sorted so
+// the snapshots of the same block are identical on all nodes
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Version is the version of the snapshot format
+const Version = 2
+
+// insertBatch is the count of rows which are inserted by one query
+const insertBatch = 100
+
+var (
+	ErrVersion   = errors.New("Unsupported snapshot version")
+	ErrBlockHash = errors.New("Snapshot block hash doesn't match")
+	ErrTable     = errors.New("Snapshot table is not allowed")
+	ErrStateRoot = errors.New("Snapshot state doesn't match the state root of the block")
+)
+
+// columnTypes are the definitions of the columns of the tables which are created by contracts
+// by the types of the columns in the snapshot. The tables which don't exist locally are created
+// only with these columns, the snapshot never contains sql
+var columnTypes = map[string]string{
+	"bigint":                      `bigint NOT NULL DEFAULT '0'`,
+	"jsonb":                       `jsonb`,
+	"character varying(102400)":   `varchar(102400)`,
+	"character(1)":                `character(1) NOT NULL DEFAULT '0'`,
+	"timestamp without time zone": `timestamp`,
+	"double precision":            `double precision`,
+	"numeric(30,0)":               `decimal (30, 0) NOT NULL DEFAULT '0'`,
+	"text":                        `text`,
+	"bytea":                       `bytea NOT NULL DEFAULT '\x'`,
+}
+
+var (
+	tableName  = regexp.MustCompile(`^[0-9]+_[a-z0-9_]+$`)
+	columnName = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Header is the first value of the snapshot. Rollbacks are the rollback records of the block,
+// they define the rows which are covered by the state root of the block
+type Header struct {
+	Version   int                 `json:"version"`
+	BlockID   int64               `json:"block_id"`
+	Hash      []byte              `json:"hash"`
+	Block     *model.Block        `json:"block"`
+	InfoBlock *model.InfoBlock    `json:"info_block"`
+	Rollbacks []*model.RollbackTx `json:"rollbacks"`
+	Tables    int                 `json:"tables"`
+}
+
+// Table is the definition of the table which is followed by its rows
+type Table struct {
+	Name    string              `json:"name"`
+	Columns []model.TableColumn `json:"columns"`
+	Rows    int64               `json:"rows"`
+}
+
+// Row contains the text values of the columns, NULL is nil
+type Row []*string
+
+func quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// setupSession makes the text representation of the values independent of the node settings
+func setupSession(dbTransaction *model.DbTransaction) error {
+	for _, opt := range []string{`TimeZone = 'UTC'`, `DateStyle = 'ISO, YMD'`, `bytea_output = 'hex'`,
+		`extra_float_digits = 1`} {
+		if err := model.GetDB(dbTransaction).Exec(`SET LOCAL ` + opt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export writes the snapshot of the current state. dbTransaction must be started by
+// model.StartSnapshotTransaction so all tables are read at the same block
+func Export(dbTransaction *model.DbTransaction, w io.Writer) (*Header, error) {
+	logger := log.WithFields(log.Fields{"type": consts.DBError})
+	if err := setupSession(dbTransaction); err != nil {
+		logger.WithError(err).Error("setting up session")
+		return nil, err
+	}
+	ib := &model.InfoBlock{}
+	if err := model.GetDB(dbTransaction).Last(ib).Error; err != nil {
+		logger.WithError(err).Error("getting info block")
+		return nil, err
+	}
+	block := &model.Block{}
+	if err := model.GetDB(dbTransaction).Where("id = ?", ib.BlockID).First(block).Error; err != nil {
+		logger.WithFields(log.Fields{"error": err, "block_id": ib.BlockID}).Error("getting block")
+		return nil, err
+	}
+	var rollbacks []*model.RollbackTx
+	if err := model.GetDB(dbTransaction).Where("block_id = ?", block.ID).Order("id").Find(&rollbacks).Error; err != nil {
+		logger.WithFields(log.Fields{"error": err, "block_id": block.ID}).Error("getting rollbacks of block")
+		return nil, err
+	}
+	tables, err := model.GetStateTables(dbTransaction)
+	if err != nil {
+		logger.WithError(err).Error("getting tables")
+		return nil, err
+	}
+
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	header := &Header{
+		Version:   Version,
+		BlockID:   block.ID,
+		Hash:      block.Hash,
+		Block:     block,
+		InfoBlock: ib,
+		Rollbacks: rollbacks,
+		Tables:    len(tables),
+	}
+	if err = enc.Encode(header); err != nil {
+		return nil, err
+	}
+	for _, name := range tables {
+		if err = exportTable(dbTransaction, enc, name); err != nil {
+			logger.WithFields(log.Fields{"error": err, "table": name}).Error("exporting table")
+			return nil, err
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func exportTable(dbTransaction *model.DbTransaction, enc *json.Encoder, name string) error {
+	table := &Table{Name: name}
+	var err error
+	if table.Columns, err = model.GetTableColumns(dbTransaction, name); err != nil {
+		return err
+	}
+	if err = model.GetDB(dbTransaction).Table(name).Count(&table.Rows).Error; err != nil {
+		return err
+	}
+	if err = enc.Encode(table); err != nil {
+		return err
+	}
+	if table.Rows == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(table.Columns))
+	order := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		fields[i] = quote(col.Name) + "::text"
+		order[i] = fmt.Sprintf(`%d COLLATE "C"`, i+1)
+	}
+	rows, err := model.GetDB(dbTransaction).Raw(fmt.Sprintf(`SELECT %s FROM %s ORDER BY %s`,
+		strings.Join(fields, ","), quote(name), strings.Join(order, ","))).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	row := make(Row, len(table.Columns))
+	dest := make([]interface{}, len(row))
+	for i := range row {
+		dest[i] = &row[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+		if err = enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ReadHeader returns the header of the snapshot
+func ReadHeader(r io.Reader) (*Header, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	header := &Header{}
+	if err = json.NewDecoder(zr).Decode(header); err != nil {
+		return nil, err
+	}
+	if header.Version != Version {
+		return nil, ErrVersion
+	}
+	return header, nil
+}
+
+// Import replaces the state with the snapshot. The blocks, the rollback data and
+// the confirmations of the node are deleted, the snapshot block becomes the last block.
+// Import doesn't verify the rows of the snapshot, the caller checks the hash of the snapshot file
+// with VerifySigns. If stateRoot is not nil, the imported rows which have been modified
+// by the snapshot block must match it, the other rows aren't covered by the state root
+func Import(dbTransaction *model.DbTransaction, r io.Reader, stateRoot []byte) (*Header, error) {
+	logger := log.WithFields(log.Fields{"type": consts.DBError})
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(zr)
+	header := &Header{}
+	if err = dec.Decode(header); err != nil {
+		return nil, err
+	}
+	if header.Version != Version {
+		return nil, ErrVersion
+	}
+	if header.Block == nil || header.InfoBlock == nil || header.Block.ID != header.BlockID ||
+		!bytes.Equal(header.Block.Hash, header.Hash) || !bytes.Equal(header.InfoBlock.Hash, header.Hash) {
+		return nil, ErrBlockHash
+	}
+	if stateRoot != nil && !bytes.Equal(header.Block.StateRoot, stateRoot) {
+		return nil, ErrStateRoot
+	}
+	if err = setupSession(dbTransaction); err != nil {
+		logger.WithError(err).Error("setting up session")
+		return nil, err
+	}
+
+	db := model.GetDB(dbTransaction)
+	for _, name := range []string{"block_chain", "info_block", "rollback_tx", "confirmations"} {
+		if err = db.Exec(`DELETE FROM ` + quote(name)).Error; err != nil {
+			logger.WithFields(log.Fields{"error": err, "table": name}).Error("deleting local data")
+			return nil, err
+		}
+	}
+	if err = db.Create(header.Block).Error; err != nil {
+		logger.WithError(err).Error("creating block")
+		return nil, err
+	}
+	if err = db.Create(header.InfoBlock).Error; err != nil {
+		logger.WithError(err).Error("creating info block")
+		return nil, err
+	}
+	imported := make(map[string]bool)
+	for i := 0; i < header.Tables; i++ {
+		table := &Table{}
+		if err = dec.Decode(table); err != nil {
+			return nil, err
+		}
+		if imported[table.Name] {
+			logger.WithFields(log.Fields{"type": consts.InvalidObject, "table": table.Name}).Error("duplicate table in snapshot")
+			return nil, ErrTable
+		}
+		imported[table.Name] = true
+		if err = importTable(dbTransaction, dec, table); err != nil {
+			logger.WithFields(log.Fields{"error": err, "table": table.Name}).Error("importing table")
+			return nil, err
+		}
+	}
+	if stateRoot != nil {
+		root, err := block.StateRoot(dbTransaction, header.Rollbacks)
+		if err != nil {
+			logger.WithError(err).Error("calculating state root")
+			return nil, err
+		}
+		if !bytes.Equal(root, stateRoot) {
+			log.WithFields(log.Fields{"type": consts.BlockError, "block_id": header.BlockID}).Error("snapshot state root doesn't match")
+			return nil, ErrStateRoot
+		}
+	}
+	return header, nil
+}
+
+// tableSQL returns the columns of the table which is created by the contract. It is created
+// like model.CreateTable does with the primary key on id
+func tableSQL(table *Table) (string, error) {
+	if !tableName.MatchString(table.Name) {
+		return "", ErrTable
+	}
+	var hasID bool
+	cols := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		colType, ok := columnTypes[col.Type]
+		if !ok || !columnName.MatchString(col.Name) {
+			return "", fmt.Errorf("column %s %s is not allowed", col.Name, col.Type)
+		}
+		if col.Name == "id" {
+			if col.Type != "bigint" {
+				return "", fmt.Errorf("column id %s is not allowed", col.Type)
+			}
+			hasID = true
+		}
+		cols[i] = quote(col.Name) + " " + colType
+	}
+	if !hasID {
+		return "", ErrTable
+	}
+	cols = append(cols, fmt.Sprintf(`CONSTRAINT %s PRIMARY KEY (id)`, quote(table.Name+"_pkey")))
+	return strings.Join(cols, ","), nil
+}
+
+// importTable replaces the rows of the table of the state. The local tables of the node
+// are never exported so the snapshot cannot contain them
+func importTable(dbTransaction *model.DbTransaction, dec *json.Decoder, table *Table) error {
+	if model.IsLocalTable(table.Name) {
+		return ErrTable
+	}
+	db := model.GetDB(dbTransaction)
+	names := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		names[i] = quote(col.Name)
+	}
+	if model.IsTable(table.Name) {
+		if err := db.Exec(`DELETE FROM ` + quote(table.Name)).Error; err != nil {
+			return err
+		}
+	} else {
+		cols, err := tableSQL(table)
+		if err != nil {
+			return err
+		}
+		if err = db.Exec(fmt.Sprintf(`CREATE TABLE %s (%s)`, quote(table.Name), cols)).Error; err != nil {
+			return err
+		}
+	}
+
+	var (
+		values []string
+		args   []interface{}
+	)
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(names)), ",") + ")"
+	insert := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		err := db.Exec(fmt.Sprintf(`INSERT INTO %s (%s) VALUES %s`, quote(table.Name),
+			strings.Join(names, ","), strings.Join(values, ",")), args...).Error
+		values, args = values[:0], args[:0]
+		return err
+	}
+	for i := int64(0); i < table.Rows; i++ {
+		var row Row
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+		if len(row) != len(names) {
+			return fmt.Errorf("wrong count of values %d, expected %d", len(row), len(names))
+		}
+		values = append(values, placeholder)
+		for _, v := range row {
+			if v == nil {
+				args = append(args, nil)
+			} else {
+				args = append(args, *v)
+			}
+		}
+		if len(values) == insertBatch {
+			if err := insert(); err != nil {
+				return err
+			}
+		}
+	}
+	return insert()
+}
@@ -0,0 +1,44 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableSQL(t *testing.T) {
+	table := &Table{Name: "2_goods", Columns: []model.TableColumn{
+		{Name: "id", Type: "bigint"},
+		{Name: "name", Type: "character varying(102400)"},
+		{Name: "price", Type: "numeric(30,0)"},
+	}}
+	cols, err := tableSQL(table)
+	assert.NoError(t, err)
+	assert.Equal(t, `"id" bigint NOT NULL DEFAULT '0',"name" varchar(102400),`+
+		`"price" decimal (30, 0) NOT NULL DEFAULT '0',CONSTRAINT "2_goods_pkey" PRIMARY KEY (id)`, cols)
+
+	for _, columns := range [][]model.TableColumn{
+		{{Name: "name", Type: "text"}},
+		{{Name: "id", Type: "text"}},
+		{{Name: "id", Type: "bigint"}, {Name: "name", Type: "text DEFAULT now()"}},
+		{{Name: "id", Type: "bigint"}, {Name: `name" text, "x`, Type: "text"}},
+	} {
+		_, err = tableSQL(&Table{Name: "2_goods", Columns: columns})
+		assert.Error(t, err)
+	}
+	_, err = tableSQL(&Table{Name: `goods"; DROP TABLE "1_keys`, Columns: table.Columns})
+	assert.Equal(t, ErrTable, err)
+}
+
+func TestImportLocalTable(t *testing.T) {
+	for _, name := range []string{"api_keys", "node_votes", "stop_daemons", "transactions", "queue_tx", "pruned_blocks"} {
+		assert.Equal(t, ErrTable, importTable(nil, nil, &Table{Name: name}), name)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/notificator"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -99,7 +100,7 @@ func MarkTransactionBad(dbTransaction *model.DbTransaction, hash []byte, errText
 	}
 	log.WithFields(log.Fields{"type": consts.BadTxError, "tx_hash": hash, "error": errText}).Debug("tx marked as bad")
 
-	return model.NewDbTransaction(model.DBConn).Connection().Transaction(func(tx *gorm.DB) error {
+	err := model.NewDbTransaction(model.DBConn).Connection().Transaction(func(tx *gorm.DB) error {
 		// looks like there is not hash in queue_tx in this moment
 		qtx := &model.QueueTx{}
 		_, err := qtx.GetByHash(model.NewDbTransaction(tx), hash)
@@ -123,6 +124,10 @@ func MarkTransactionBad(dbTransaction *model.DbTransaction, hash []byte, errText
 		}
 		return nil
 	})
+	if err == nil {
+		notificator.SendTxStatus(hash, 0, errText)
+	}
+	return err
 }
 
 // ProcessQueueTransaction writes transactions into the queue
@@ -6,6 +6,7 @@ import (
 
 	"github.com/shopspring/decimal"
 
+	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/model"
 	defer func() {
 		if err != nil {
@@ -54,6 +55,9 @@ import (
 		if errTx != nil {
 			return errTx
 		}
+		if _, errTx = model.EvictCheapTransactions(dbTransaction, conf.Config.MaxTxPoolSize); errTx != nil {
+			return errTx
+		}
 	}
 	if len(hashes) > 0 {
 		errQTx := model.DeleteQueueTxs(dbTransaction, hashes)
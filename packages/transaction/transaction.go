@@ -174,6 +174,7 @@ type Transaction struct {
 
 	SmartContract *smart.SmartContract
 	RollBackTx    []*model.RollbackTx
+	Events        []*model.ContractEvent
 }
 
 // GetLogger returns logger
@@ -411,6 +412,7 @@ func (t *Transaction) CallContract(point int) (resultContract string, flushRollb
 	}
 	resultContract, err = sc.CallContract(point)
 	t.RollBackTx = sc.RollBackTx
+	t.Events = sc.Events
 	t.TxFuel = sc.TxFuel
 	t.SysUpdate = sc.SysUpdate
 	if sc.FlushRollback != nil {
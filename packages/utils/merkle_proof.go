@@ -0,0 +1,123 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package utils
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+var (
+	ErrMerkleIndex      = errors.New("Merkle leaf index out of range")
+	ErrMerkleProof      = errors.New("Merkle proof does not match root")
+	ErrMerkleSignHeader = errors.New("Block header signature is incorrect")
+)
+
+// MerkleProofStep is a sibling hash on the path from the leaf to the root.
+// Left is true when the sibling is placed on the left side of the pair
+type MerkleProofStep struct {
+	Hash []byte `json:"hash"`
+	Left bool   `json:"left"`
+}
+
+// MerkleProof is an inclusion proof of one item of the merkle tree built by MerkleTreeRoot
+type MerkleProof struct {
+	Index int               `json:"index"`
+	Leaf  []byte            `json:"leaf"`
+	Path  []MerkleProofStep `json:"path"`
+	Root  []byte            `json:"root"`
+}
+
+func merkleLeaf(data []byte) []byte {
+	return converter.BinToHex(crypto.DoubleHash(data))
+}
+
+func merkleNode(left, right []byte) []byte {
+	return converter.BinToHex(crypto.DoubleHash(append(append([]byte{}, left...), right...)))
+}
+
+// MerkleTreeProof returns the proof for the item with index in dataArray.
+// The tree is built the same way as in MerkleTreeRoot, the last odd item of the level
+// is moved to the next level without hashing
+func MerkleTreeProof(dataArray [][]byte, index int) (*MerkleProof, error) {
+	if index < 0 || index >= len(dataArray) {
+		return nil, ErrMerkleIndex
+	}
+	level := make([][]byte, 0, len(dataArray))
+	for _, v := range dataArray {
+		level = append(level, merkleLeaf(v))
+	}
+
+	proof := &MerkleProof{
+		Index: index,
+		Leaf:  dataArray[index],
+	}
+	pos := index
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 >= len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, merkleNode(level[i], level[i+1]))
+		}
+		if pos%2 == 1 {
+			proof.Path = append(proof.Path, MerkleProofStep{Hash: level[pos-1], Left: true})
+		} else if pos+1 < len(level) {
+			proof.Path = append(proof.Path, MerkleProofStep{Hash: level[pos+1]})
+		}
+		pos /= 2
+		level = next
+	}
+	proof.Root = level[0]
+	return proof, nil
+}
+
+// ComputeRoot returns merkle root calculated from the leaf and the sibling path
+func (p *MerkleProof) ComputeRoot() []byte {
+	hash := merkleLeaf(p.Leaf)
+	for _, step := range p.Path {
+		if step.Left {
+			hash = merkleNode(step.Hash, hash)
+		} else {
+			hash = merkleNode(hash, step.Hash)
+		}
+	}
+	return hash
+}
+
+// Verify checks that the proof leads to the expected root
+func (p *MerkleProof) Verify(root []byte) error {
+	if !bytes.Equal(p.ComputeRoot(), root) {
+		return ErrMerkleProof
+	}
+	return nil
+}
+
+// VerifyTxProof checks offline that the transaction belongs to the block.
+// The merkle root is checked against the proof and the header signature is checked
+// against the public key of the honor node which has generated the block.
+// txData can be empty if only the leaf of the proof is known
+func VerifyTxProof(txData []byte, proof *MerkleProof, header, prev *BlockData, nodePublicKey []byte) error {
+	if len(txData) > 0 && !bytes.Equal(proof.Leaf, merkleLeaf(txData)) {
+		return ErrMerkleProof
+	}
+	root := proof.ComputeRoot()
+	if !bytes.Equal(root, proof.Root) {
+		return ErrMerkleProof
+	}
+	ok, err := CheckSign([][]byte{nodePublicKey}, []byte(header.ForSign(prev, root)), header.Sign, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMerkleSignHeader
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkleTreeProof(t *testing.T) {
+	crypto.InitHash("SHA256")
+	for n := 1; n <= 9; n++ {
+		var data [][]byte
+		for i := 0; i < n; i++ {
+			data = append(data, []byte(fmt.Sprintf("tx%d", i)))
+		}
+		root, err := MerkleTreeRoot(data)
+		assert.NoError(t, err)
+		for i := 0; i < n; i++ {
+			proof, err := MerkleTreeProof(data, i)
+			assert.NoError(t, err)
+			assert.Equal(t, root, proof.Root)
+			assert.NoError(t, proof.Verify(root))
+
+			proof.Leaf = []byte("wrong")
+			assert.Equal(t, ErrMerkleProof, proof.Verify(root))
+		}
+	}
+	_, err := MerkleTreeProof([][]byte{[]byte("tx")}, 1)
+	assert.Equal(t, ErrMerkleIndex, err)
+}
@@ -0,0 +1,56 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+var ErrRowProofSign = errors.New("Row proof signature is incorrect")
+
+// RowProof is the value of the table row at the block which is signed by the key of honor node.
+// Data is the json object of the row, it is empty if the row doesn't exist
+type RowProof struct {
+	BlockID   int64  `json:"block_id"`
+	Hash      []byte `json:"hash"`
+	Table     string `json:"table"`
+	ID        int64  `json:"id"`
+	Ecosystem int64  `json:"ecosystem"`
+	Data      []byte `json:"data"`
+	NodeKey   []byte `json:"node_key"`
+	Sign      []byte `json:"sign"`
+}
+
+// ForSign returns the data of the proof which is signed by the node key
+func (p *RowProof) ForSign() []byte {
+	return []byte(fmt.Sprintf("row,%d,%x,%s,%d,%d,%x", p.BlockID, p.Hash, p.Table, p.ID, p.Ecosystem,
+		crypto.Hash(p.Data)))
+}
+
+// SignRowProof signs the proof by the private key of the node
+func SignRowProof(p *RowProof, nodeKey, privateKey []byte) error {
+	sign, err := crypto.Sign(privateKey, p.ForSign())
+	if err != nil {
+		return err
+	}
+	p.NodeKey = nodeKey
+	p.Sign = sign
+	return nil
+}
+
+// Verify checks that the proof is signed by its node key
+func (p *RowProof) Verify() error {
+	ok, err := CheckSign([][]byte{p.NodeKey}, p.ForSign(), p.Sign, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRowProofSign
+	}
+	return nil
+}
@@ -53,6 +53,7 @@ type BlockData struct {
 	Sign              []byte
 	Hash              []byte
 	RollbacksHash     []byte
+	StateRoot         []byte
 	Version           int
 	PrivateBlockchain bool
 }
@@ -65,6 +66,9 @@ func blockVer(cur, prev *BlockData) (ret string) {
 	if cur.Version >= consts.BvRollbackHash {
 		ret = fmt.Sprintf(",%x", prev.RollbacksHash)
 	}
+	if cur.Version >= consts.BvStateRoot {
+		ret += fmt.Sprintf(",%x", prev.StateRoot)
+	}
 	return
 }
 
@@ -105,6 +109,13 @@ func ParseBlockHeader(buf *bytes.Buffer) (header, prev BlockData, err error) {
 			return
 		}
 	}
+	// for version of block with included the state root
+	if header.Version >= consts.BvStateRoot {
+		prev.StateRoot, err = converter.DecodeBytesBuf(buf)
+		if err != nil {
+			return
+		}
+	}
 
 	if header.BlockID == firstBlock {
 		buf.Next(1)
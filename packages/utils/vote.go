@@ -0,0 +1,109 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+var (
+	ErrVoteSign      = errors.New("Vote signature is incorrect")
+	ErrVoteHash      = errors.New("Vote is for the different block hash")
+	ErrVoteQuorum    = errors.New("Not enough votes of honor nodes")
+	ErrVoteDuplicate = errors.New("Duplicate vote of honor node")
+	ErrVoteNode      = errors.New("Vote is not signed by honor node")
+	ErrVoteConflict  = errors.New("Node has already voted for the different block hash")
+)
+
+// VoteStore keeps the hashes of the blocks which the node has voted for
+type VoteStore interface {
+	// SaveVote saves the hash if the node hasn't voted for the block yet and returns the saved hash
+	SaveVote(blockID int64, hash []byte) ([]byte, error)
+}
+
+// Vote is the confirmation of the block hash which is signed by the key of honor node
+type Vote struct {
+	BlockID int64  `json:"block_id"`
+	Hash    []byte `json:"hash"`
+	NodeKey []byte `json:"node_key"`
+	Sign    []byte `json:"sign"`
+}
+
+// ForSign returns the data of the vote which is signed by the node key
+func (v *Vote) ForSign() []byte {
+	return []byte(fmt.Sprintf("vote,%d,%x", v.BlockID, v.Hash))
+}
+
+// NewVote returns the vote for the block hash signed by the private key of the node
+func NewVote(blockID int64, hash, nodeKey, privateKey []byte) (*Vote, error) {
+	v := &Vote{BlockID: blockID, Hash: hash, NodeKey: nodeKey}
+	sign, err := crypto.Sign(privateKey, v.ForSign())
+	if err != nil {
+		return nil, err
+	}
+	v.Sign = sign
+	return v, nil
+}
+
+// SignVote persists the hash of the block before signing it so the node never signs
+// two different hashes of the same block
+func SignVote(store VoteStore, blockID int64, hash, nodeKey, privateKey []byte) (*Vote, error) {
+	saved, err := store.SaveVote(blockID, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(saved, hash) {
+		return nil, ErrVoteConflict
+	}
+	return NewVote(blockID, hash, nodeKey, privateKey)
+}
+
+// Verify checks that the vote is signed by its node key
+func (v *Vote) Verify() error {
+	ok, err := CheckSign([][]byte{v.NodeKey}, v.ForSign(), v.Sign, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrVoteSign
+	}
+	return nil
+}
+
+// FinalityQuorum returns the count of votes which finalizes the block, it is more than 2/3 of honor nodes
+func FinalityQuorum(nodes int64) int {
+	return int(nodes*2/3 + 1)
+}
+
+// VerifyVotes checks that the votes are signed by different honor nodes for the same block hash
+// and that there are enough of them. isHonorNode reports whether the key belongs to honor node
+func VerifyVotes(blockID int64, hash []byte, votes []*Vote, nodes int64, isHonorNode func([]byte) bool) error {
+	keys := make(map[string]bool)
+	for _, v := range votes {
+		if v.BlockID != blockID || !bytes.Equal(v.Hash, hash) {
+			return ErrVoteHash
+		}
+		if !isHonorNode(v.NodeKey) {
+			return ErrVoteNode
+		}
+		key := hex.EncodeToString(v.NodeKey)
+		if keys[key] {
+			return ErrVoteDuplicate
+		}
+		if err := v.Verify(); err != nil {
+			return err
+		}
+		keys[key] = true
+	}
+	if len(keys) < FinalityQuorum(nodes) {
+		return ErrVoteQuorum
+	}
+	return nil
+}
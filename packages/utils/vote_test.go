@@ -0,0 +1,82 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyVotes(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	crypto.InitHash("SHA256")
+
+	hash := crypto.DoubleHash([]byte("block"))
+	var (
+		keys  [][]byte
+		votes []*Vote
+	)
+	for i := 0; i < 4; i++ {
+		priv, pub, err := crypto.GenKeyPair()
+		require.NoError(t, err)
+		keys = append(keys, pub)
+		vote, err := NewVote(10, hash, pub, priv)
+		require.NoError(t, err)
+		votes = append(votes, vote)
+	}
+	isHonorNode := func(key []byte) bool {
+		for _, k := range keys {
+			if bytes.Equal(k, key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.Equal(t, 3, FinalityQuorum(4))
+	assert.NoError(t, VerifyVotes(10, hash, votes[:3], 4, isHonorNode))
+	assert.Equal(t, ErrVoteQuorum, VerifyVotes(10, hash, votes[:2], 4, isHonorNode))
+	assert.Equal(t, ErrVoteDuplicate, VerifyVotes(10, hash, append(votes[:2:2], votes[0]), 4, isHonorNode))
+	assert.Equal(t, ErrVoteHash, VerifyVotes(11, hash, votes, 4, isHonorNode))
+	assert.Equal(t, ErrVoteNode, VerifyVotes(10, hash, votes, 4, func([]byte) bool { return false }))
+
+	votes[1].Sign = votes[0].Sign
+	assert.Equal(t, ErrVoteSign, VerifyVotes(10, hash, votes, 4, isHonorNode))
+}
+
+type testVoteStore map[int64][]byte
+
+func (s testVoteStore) SaveVote(blockID int64, hash []byte) ([]byte, error) {
+	if saved, ok := s[blockID]; ok {
+		return saved, nil
+	}
+	s[blockID] = hash
+	return hash, nil
+}
+
+func TestSignVote(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	crypto.InitHash("SHA256")
+
+	priv, pub, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	hash := crypto.DoubleHash([]byte("block"))
+	store := testVoteStore{}
+
+	vote, err := SignVote(store, 10, hash, pub, priv)
+	require.NoError(t, err)
+	assert.NoError(t, vote.Verify())
+	_, err = SignVote(store, 10, hash, pub, priv)
+	assert.NoError(t, err)
+
+	_, err = SignVote(store, 10, crypto.DoubleHash([]byte("fork")), pub, priv)
+	assert.Equal(t, ErrVoteConflict, err)
+	assert.Equal(t, hash, store[10])
+}